@@ -4,16 +4,30 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/atrush/pract_01.git/internal/api"
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/metrics"
+	"github.com/atrush/pract_01.git/internal/service"
 	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/bolt"
+	"github.com/atrush/pract_01.git/internal/storage/cached"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
 	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/internal/storage/instrumented"
 	"github.com/atrush/pract_01.git/internal/storage/psql"
+	"github.com/atrush/pract_01.git/internal/storage/sqlite"
+	"github.com/atrush/pract_01.git/internal/tracing"
 	"github.com/atrush/pract_01.git/pkg"
 )
 
+//  tracingShutdownTimeout is max time of exporting remaining spans on shutdown.
+const tracingShutdownTimeout = 5 * time.Second
+
 var (
 	buildVersion = "N/A"
 	buildDate    = "N/A"
@@ -25,34 +39,90 @@ func main() {
 	fmt.Printf("Build date:%v\n", buildDate)
 	fmt.Printf("Build commit:%v\n", buildCommit)
 
+	//  cli subcommands
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "storage" {
+		if err := runStorage(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	cfg, err := pkg.NewConfig()
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	slog.SetDefault(logger)
+
+	//  storages and background tasks log with logger from context
+	ctx, cancel := context.WithCancel(logging.WithLogger(context.Background(), logger))
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Exporter:     cfg.TraceExporter,
+		OTLPEndpoint: cfg.TraceOTLPEndpoint,
+		OTLPInsecure: cfg.TraceOTLPInsecure,
+		Version:      buildVersion,
+	})
+	if err != nil {
+		fatal(logger, err)
+	}
+
 	finishedChan := make(chan struct{})
 
 	db, err := getDB(ctx, finishedChan, *cfg)
 	if err != nil {
-		log.Fatal(err.Error())
+		fatal(logger, err)
+	}
+
+	//  compacts storage file in background
+	if fileDB, ok := db.(*infile.Storage); ok {
+		go fileDB.RunCompactor(ctx, infile.DefCompactInterval)
+	}
+
+	//  storage latency is observed before cache, so cache hits are not counted as storage operations
+	m := metrics.New()
+	db, err = instrumented.NewStorage(db, m.ObserveStorage)
+	if err != nil {
+		fatal(logger, err)
+	}
+
+	db, err = withCache(ctx, db, *cfg)
+	if err != nil {
+		fatal(logger, err)
 	}
 	defer db.Close()
 
-	server, err := api.NewServer(cfg, db)
+	//  marks expired urls in background
+	reaper, err := service.NewExpiredReaper(db, service.DefReaperInterval)
 	if err != nil {
-		log.Fatal(err.Error())
+		fatal(logger, err)
+	}
+	go reaper.Run(ctx)
+
+	server, err := api.NewServer(cfg, db, m, logger)
+	if err != nil {
+		fatal(logger, err)
 	}
 
 	go func() {
 		if err := server.RunGRPC(); err != nil {
-			log.Fatal(err.Error())
+			fatal(logger, err)
 		}
 	}()
 
 	go func() {
 		if err := server.RunHTTP(); err != nil {
-			log.Fatal(err.Error())
+			fatal(logger, err)
 		}
 	}()
 
@@ -60,30 +130,69 @@ func main() {
 	sigc := make(chan os.Signal, 1)
 	signal.Notify(sigc, os.Interrupt)
 	<-sigc
-	log.Println("accepted sigint, shutting down")
+	logger.Info("accepted sigint, shutting down")
 
 	if err := server.ShutdownHTTP(ctx); err != nil {
-		log.Fatalf("error shutdown server: %s\n", err.Error())
+		fatal(logger, fmt.Errorf("error shutdown server: %w", err))
 	}
 
 	server.ShutdownGRPC()
 
+	//  exports spans of finished requests
+	traceCtx, traceCancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	if err := shutdownTracing(traceCtx); err != nil {
+		logger.Error("error shutdown tracing", "error", err.Error())
+	}
+	traceCancel()
+
 	// waiting if db must wait ending of async tasks
 	if db.WaitAsyncTasksEnded() {
-		log.Println("waiting all tasks finish")
+		logger.Info("waiting all tasks finish")
 		cancel()
 		<-finishedChan
-		log.Println("all tasks finished")
+		logger.Info("all tasks finished")
 	}
 }
 
+//  fatal logs error and exits with code 1.
+func fatal(logger *slog.Logger, err error) {
+	logger.Error(err.Error())
+	os.Exit(1)
+}
+
 //  getDB returns initialized storage
-//  psql storage if dsn not empty, else memory storage
+//  bolt storage if dsn starts with bolt://, sqlite storage if dsn starts with sqlite://,
+//  psql storage if dsn not empty, else memory storage.
+//  Only storage type is logged, dsn can contain password.
 func getDB(ctx context.Context, finishedChan chan struct{}, cfg pkg.Config) (storage.Storage, error) {
-	log.Println("dsn: " + cfg.DatabaseDSN)
+	logger := logging.FromContext(ctx)
+
+	//  bolt storage
+	if bolt.IsDSN(cfg.DatabaseDSN) {
+		logger.Info("инициализация хранилища", "type", "bolt")
+		db, err := bolt.NewStorage(ctx, finishedChan, cfg.DatabaseDSN)
+		if err != nil {
+			return nil, err
+		}
+
+		return db, nil
+	}
+
+	//  sqlite storage
+	if sqlite.IsDSN(cfg.DatabaseDSN) {
+		logger.Info("инициализация хранилища", "type", "sqlite")
+		db, err := sqlite.NewStorage(ctx, finishedChan, cfg.DatabaseDSN, deleteQueueOptions(cfg)...)
+		if err != nil {
+			return nil, err
+		}
+
+		return db, nil
+	}
+
 	//  postgress storage
 	if cfg.DatabaseDSN != "" {
-		db, err := psql.NewStorage(ctx, finishedChan, cfg.DatabaseDSN)
+		logger.Info("инициализация хранилища", "type", "psql")
+		db, err := psql.NewStorage(ctx, finishedChan, cfg.DatabaseDSN, deleteQueueOptions(cfg)...)
 		if err != nil {
 			return nil, err
 		}
@@ -92,10 +201,40 @@ func getDB(ctx context.Context, finishedChan chan struct{}, cfg pkg.Config) (sto
 	}
 
 	//  memory with file storage
-	db, err := infile.NewFileStorage(cfg.FileStoragePath)
+	logger.Info("инициализация хранилища", "type", "memory", "file", cfg.FileStoragePath)
+	db, err := infile.NewFileStorage(ctx, cfg.FileStoragePath)
 	if err != nil {
 		return nil, err
 	}
 
 	return db, nil
 }
+
+//  deleteQueueOptions returns options of async url delete queue from config.
+func deleteQueueOptions(cfg pkg.Config) []deletequeue.Option {
+	return []deletequeue.Option{
+		deletequeue.WithSize(cfg.DeleteQueueSize),
+		deletequeue.WithDeadLetterFile(cfg.DeleteDeadLetterPath),
+	}
+}
+
+//  withCache wraps storage with url cache
+//  Redis-compatible cache if cache address not empty, in-process LRU cache if cache size not zero, else returns storage as is
+func withCache(ctx context.Context, db storage.Storage, cfg pkg.Config) (storage.Storage, error) {
+	ttl := time.Duration(cfg.CacheTTL) * time.Second
+
+	if cfg.CacheAddress != "" {
+		cache, err := cached.NewRESPCache(ctx, cfg.CacheAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		return cached.NewStorage(db, cache, ttl)
+	}
+
+	if cfg.CacheSize > 0 {
+		return cached.NewStorage(db, cached.NewLRU(cfg.CacheSize), ttl)
+	}
+
+	return db, nil
+}
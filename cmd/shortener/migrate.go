@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/atrush/pract_01.git/internal/storage/bolt"
+	"github.com/atrush/pract_01.git/internal/storage/psql"
+	"github.com/atrush/pract_01.git/internal/storage/sqlite"
+	"github.com/atrush/pract_01.git/pkg"
+)
+
+//  migrateUsage describes migrate subcommand.
+const migrateUsage = "usage: shortener migrate up|down|status [flags]"
+
+//  runMigrate runs migrate subcommand: applies, rolls back or shows psql or sqlite schema migrations.
+//  args - subcommand args without "migrate", config flags are read after action.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	action := args[0]
+
+	//  leave only config flags for config parsing
+	os.Args = append([]string{os.Args[0]}, args[1:]...)
+	cfg, err := pkg.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.DatabaseDSN == "" {
+		return errors.New("миграции доступны только для PostgreSQL и SQLite, строка соединения с бд пуста")
+	}
+
+	if bolt.IsDSN(cfg.DatabaseDSN) {
+		return errors.New("миграции не требуются для bolt хранилища, бакеты создаются при открытии")
+	}
+
+	migrator := psqlMigrator
+	if sqlite.IsDSN(cfg.DatabaseDSN) {
+		migrator = sqliteMigrator
+	}
+
+	switch action {
+	case "up":
+		if err := migrator.up(cfg.DatabaseDSN); err != nil {
+			return err
+		}
+	case "down":
+		if err := migrator.down(cfg.DatabaseDSN); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, latest, dirty, err := migrator.status(cfg.DatabaseDSN)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version:%v\n", version)
+	fmt.Printf("latest version:%v\n", latest)
+	fmt.Printf("dirty:%v\n", dirty)
+
+	return nil
+}
+
+//  migrator is set of migration functions of storage.
+type migrator struct {
+	up     func(dsn string) error
+	down   func(dsn string) error
+	status func(dsn string) (version uint, latest uint, dirty bool, err error)
+}
+
+var (
+	psqlMigrator = migrator{
+		up:   psql.MigrateUp,
+		down: psql.MigrateDown,
+		status: func(dsn string) (uint, uint, bool, error) {
+			s, err := psql.GetMigrationStatus(dsn)
+			return s.Version, s.Latest, s.Dirty, err
+		},
+	}
+	sqliteMigrator = migrator{
+		up:   sqlite.MigrateUp,
+		down: sqlite.MigrateDown,
+		status: func(dsn string) (uint, uint, bool, error) {
+			s, err := sqlite.GetMigrationStatus(dsn)
+			return s.Version, s.Latest, s.Dirty, err
+		},
+	}
+)
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/pkg"
+)
+
+//  storageUsage describes storage subcommand.
+const storageUsage = "usage: shortener storage compact [flags]"
+
+//  runStorage runs storage subcommand: compacts file storage.
+//  Must be run on stopped server, server does not reread file.
+//  Storage file is locked by server, so compact fails at once if server is running.
+//  args - subcommand args without "storage", config flags are read after action.
+func runStorage(args []string) error {
+	if len(args) == 0 || args[0] != "compact" {
+		return errors.New(storageUsage)
+	}
+
+	//  leave only config flags for config parsing
+	os.Args = append([]string{os.Args[0]}, args[1:]...)
+	cfg, err := pkg.NewConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.DatabaseDSN != "" || cfg.FileStoragePath == "" {
+		return errors.New("сжатие доступно только для файлового хранилища, путь до файла пуст или задана строка соединения с бд")
+	}
+
+	db, err := infile.NewFileStorage(context.Background(), cfg.FileStoragePath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	removed, err := db.Compact()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("file:%v\n", cfg.FileStoragePath)
+	fmt.Printf("removed records:%v\n", removed)
+
+	return nil
+}
@@ -2,18 +2,20 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
+	"strings"
 
 	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/atrush/pract_01.git/internal/shterrors"
 	"github.com/google/uuid"
 )
 
 type (
 	//  Auth implements user authorisation
 	Auth struct {
-		crypt AuthCrypt
+		crypt *AuthCrypt
 		Svc   service.UserManager
 	}
 	contextKey string
@@ -21,82 +23,144 @@ type (
 
 var (
 	ContextKeyUserID = contextKey("user-id")
+
+	//  ErrNotAuthenticated returns if user not authenticated and anonymous users not allowed, or api key not valid.
+	ErrNotAuthenticated = errors.New("пользователь не авторизован")
 )
 
 // NewAuth activates new Auth.
 // Using UserManager service for accessing to storage.
-// And crypto tool s from AuthCrypt
-func NewAuth(svc service.UserManager) Auth {
+// And crypto tool s from AuthCrypt, if crypt is nil uses AuthCrypt with random key.
+func NewAuth(svc service.UserManager, crypt *AuthCrypt) Auth {
+	if crypt == nil {
+		crypt = NewAuthCrypt()
+	}
+
 	return Auth{
-		crypt: *NewAuthCrypt(),
+		crypt: crypt,
 		Svc:   svc,
 	}
 }
 
-// Middleware sets token for user
+// Middleware sets token for user, creates new anonymous user if user not authenticated.
 func (a *Auth) Middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-
-		userID, err := a.authUser(w, r)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+	return a.Handler(true)(next)
+}
 
-		ctx := context.WithValue(r.Context(), ContextKeyUserID, userID.String())
-		r = r.WithContext(ctx)
+// RequiredMiddleware authenticates user, returns status 401 if user not authenticated.
+func (a *Auth) RequiredMiddleware(next http.Handler) http.Handler {
+	return a.Handler(false)(next)
+}
 
-		next.ServeHTTP(w, r)
-	})
+// Handler returns auth middleware.
+// User is authenticated by api key from "Authorization: Bearer" header, or by token cookie.
+// If allowAnonymous is true, new user is created for not authenticated request, else returns status 401.
+// Not valid api key always returns status 401.
+func (a *Auth) Handler(allowAnonymous bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, err := a.authUser(w, r, allowAnonymous)
+			if err != nil {
+				writeProblem(w, r, requestProblem(r, err))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ContextKeyUserID, userID.String())
+			r = r.WithContext(ctx)
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-//  authUser reads uuid from cookie token. If ok and user exist set ctx, else generate new user and set cookie
-func (a *Auth) authUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, error) {
+//  authUser authenticates user by bearer api key, if header is set.
+//  Else reads uuid from cookie token. If ok and user exist set ctx, else generate new user and set cookie.
+//  Not valid or expired token is ignored. Old format token, or token that must be rotated, is reissued.
+func (a *Auth) authUser(w http.ResponseWriter, r *http.Request, allowAnonymous bool) (uuid.UUID, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		return a.authAPIKey(r.Context(), header)
+	}
+
 	if cookie, errCookie := r.Cookie("token"); errCookie == nil {
 		//  decode token
-		id, err := a.crypt.DecodeToken(cookie.Value)
-		if err != nil {
-			return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+		claims, err := a.crypt.ParseToken(cookie.Value)
+		if err == nil {
+			//  check user
+			exist, err := a.Svc.Exist(r.Context(), claims.UserID)
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+			}
+
+			if exist {
+				if a.crypt.NeedRenew(claims) {
+					if err := a.setTokenCookie(w, claims.UserID); err != nil {
+						return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+					}
+				}
+				return claims.UserID, nil
+			}
 		}
+	}
 
-		//  check user
-		exist, err := a.Svc.Exist(r.Context(), id)
-		if err != nil {
-			return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
-		}
+	if !allowAnonymous {
+		return uuid.Nil, ErrNotAuthenticated
+	}
+
+	newUserUUID, err := a.newUser(r.Context())
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+	}
 
-		if exist {
-			return id, nil
+	if err := a.setTokenCookie(w, newUserUUID); err != nil {
+		return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+	}
+
+	return newUserUUID, nil
+}
+
+//  authAPIKey authenticates user by "Authorization: Bearer <api key>" header.
+func (a *Auth) authAPIKey(ctx context.Context, header string) (uuid.UUID, error) {
+	const bearerPrefix = "Bearer "
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return uuid.Nil, fmt.Errorf("%w: ожидается заголовок Authorization: Bearer <key>", ErrNotAuthenticated)
+	}
+
+	userID, err := a.Svc.AuthAPIKey(ctx, strings.TrimSpace(header[len(bearerPrefix):]))
+	if err != nil {
+		if errors.Is(err, shterrors.ErrAPIKeyNotFound) {
+			return uuid.Nil, fmt.Errorf("%w: %v", ErrNotAuthenticated, err.Error())
 		}
+		return uuid.Nil, fmt.Errorf("ошибка проверки ключа API:%w", err)
 	}
 
-	newUserUUID, newUserToken, err := a.newUser(r.Context())
+	return userID, nil
+}
+
+//  setTokenCookie issues new token for user and sets cookie.
+func (a *Auth) setTokenCookie(w http.ResponseWriter, userID uuid.UUID) error {
+	token, err := a.crypt.EncodeUUID(userID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("ошибка установки ключа пользователя:%w", err)
+		return err
 	}
 
 	newCookie := http.Cookie{
 		Name:     "token",
-		Value:    newUserToken,
-		MaxAge:   int((time.Hour * 24 * 30).Seconds()),
+		Value:    token,
+		MaxAge:   int(a.crypt.TTL().Seconds()),
 		HttpOnly: true,
 		Path:     "/",
 	}
 	http.SetCookie(w, &newCookie)
 
-	return newUserUUID, nil
+	return nil
 }
 
-// newUser adds new user, return UUID and token
-func (a *Auth) newUser(ctx context.Context) (uuid.UUID, string, error) {
+// newUser adds new user, return UUID
+func (a *Auth) newUser(ctx context.Context) (uuid.UUID, error) {
 	newUser, err := a.Svc.AddUser(ctx)
-	if err == nil {
-		token, err := a.crypt.EncodeUUID(newUser.ID)
-		if err == nil {
-
-			return newUser.ID, string(token), nil
-		}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("ошибка гнерации нового пользователя: %w", err)
 	}
 
-	return uuid.Nil, "", fmt.Errorf("ошибка гнерации нового пользователя: %w", err)
+	return newUser.ID, nil
 }
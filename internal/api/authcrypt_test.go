@@ -1,8 +1,20 @@
 package api
 
 import (
+	"context"
+	"crypto/aes"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/pkg"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
@@ -19,3 +31,149 @@ func TestCrypt_EncodeDecodeUUID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, id, dec, "Закодированный uuid %v не равен раскодированому %v", id.String(), dec.String())
 }
+
+func TestCrypt_KeyRotation(t *testing.T) {
+	id := uuid.New()
+	oldKey := AuthKey{ID: "k1", Secret: []byte("0123456789abcdef")}
+	newKey := AuthKey{ID: "k2", Secret: []byte("fedcba9876543210")}
+
+	oldToken, err := NewAuthCrypt(WithAuthKeys(oldKey)).EncodeUUID(id)
+	require.NoError(t, err)
+
+	//  new key signs, old key verifies
+	crypt := NewAuthCrypt(WithAuthKeys(newKey, oldKey))
+	claims, err := crypt.ParseToken(oldToken)
+	require.NoError(t, err)
+	require.Equal(t, id, claims.UserID)
+	require.Equal(t, "k1", claims.KeyID)
+	require.True(t, crypt.NeedRenew(claims))
+
+	newToken, err := crypt.EncodeUUID(id)
+	require.NoError(t, err)
+	claims, err = crypt.ParseToken(newToken)
+	require.NoError(t, err)
+	require.False(t, crypt.NeedRenew(claims))
+
+	//  removed key
+	_, err = NewAuthCrypt(WithAuthKeys(newKey)).ParseToken(oldToken)
+	require.ErrorIs(t, err, ErrTokenNotValid)
+}
+
+func TestCrypt_NotValidTokens(t *testing.T) {
+	id := uuid.New()
+	key := AuthKey{ID: "k1", Secret: []byte("0123456789abcdef")}
+
+	crypt := NewAuthCrypt(WithAuthKeys(key))
+	token, err := crypt.EncodeUUID(id)
+	require.NoError(t, err)
+
+	//  tampered payload
+	parts := strings.Split(token, ".")
+	tampered, err := crypt.EncodeUUID(uuid.New())
+	require.NoError(t, err)
+	_, err = crypt.ParseToken(parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2])
+	require.ErrorIs(t, err, ErrTokenNotValid)
+
+	//  expired
+	expiredCrypt := NewAuthCrypt(WithAuthKeys(key), WithTokenTTL(time.Nanosecond))
+	expired, err := expiredCrypt.EncodeUUID(id)
+	require.NoError(t, err)
+	_, err = crypt.ParseToken(expired)
+	require.ErrorIs(t, err, ErrTokenExpired)
+
+	//  legacy token is accepted by default and must be reissued
+	legacy := encodeLegacyToken(t, id)
+	claims, err := crypt.ParseToken(legacy)
+	require.NoError(t, err)
+	require.Equal(t, id, claims.UserID)
+	require.True(t, crypt.NeedRenew(claims))
+
+	_, err = NewAuthCrypt(WithAuthKeys(key), WithLegacyTokens(false)).ParseToken(legacy)
+	require.ErrorIs(t, err, ErrTokenNotValid)
+}
+
+func TestAuth_LegacyCookieMigration(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = tstSt.User().AddUser(context.Background(), model.User{ID: userID})
+	require.NoError(t, err)
+
+	h := initHandler(t, tstSt)
+	r := NewRouter(h, false)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	request.AddCookie(&http.Cookie{Name: "token", Value: encodeLegacyToken(t, userID)})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	//  cookie reissued in new format for same user
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	claims, err := h.auth.crypt.ParseToken(cookies[0].Value)
+	require.NoError(t, err)
+	require.False(t, claims.Legacy)
+	require.Equal(t, userID, claims.UserID)
+
+	//  new cookie is not reissued
+	request = httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	request.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Result().Cookies())
+}
+
+func TestNewAuthCryptFromConfig(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	//  keys are required
+	_, err := newAuthCryptFromConfig(&pkg.Config{}, logger)
+	require.Error(t, err)
+
+	//  random key in debug mode
+	crypt, err := newAuthCryptFromConfig(&pkg.Config{Debug: true}, logger)
+	require.NoError(t, err)
+	require.Equal(t, randomKeyID, crypt.keys[0].ID)
+
+	//  legacy tokens are accepted by default
+	crypt, err = newAuthCryptFromConfig(&pkg.Config{AuthKeys: "k1:0123456789abcdef"}, logger)
+	require.NoError(t, err)
+	require.Equal(t, "k1", crypt.keys[0].ID)
+	require.True(t, crypt.allowLegacy)
+
+	crypt, err = newAuthCryptFromConfig(&pkg.Config{AuthKeys: "k1:0123456789abcdef", AuthDisableLegacy: true}, logger)
+	require.NoError(t, err)
+	require.False(t, crypt.allowLegacy)
+}
+
+func TestParseAuthKeys(t *testing.T) {
+	keys, err := ParseAuthKeys("k1:0123456789abcdef, k2:fedcba9876543210\n# comment\n")
+	require.NoError(t, err)
+	require.Equal(t, []AuthKey{
+		{ID: "k1", Secret: []byte("0123456789abcdef")},
+		{ID: "k2", Secret: []byte("fedcba9876543210")},
+	}, keys)
+
+	_, err = ParseAuthKeys("k1:short")
+	require.Error(t, err)
+
+	_, err = ParseAuthKeys("k.1:0123456789abcdef")
+	require.Error(t, err)
+
+	_, err = ParseAuthKeys("0123456789abcdef")
+	require.Error(t, err)
+}
+
+//  encodeLegacyToken encodes uuid to old AES token.
+func encodeLegacyToken(t *testing.T, id uuid.UUID) string {
+	aesblock, err := aes.NewCipher([]byte(legacyKey))
+	require.NoError(t, err)
+
+	dst := make([]byte, uuidBlockSize)
+	aesblock.Encrypt(dst, id[:])
+
+	return hex.EncodeToString(dst)
+}
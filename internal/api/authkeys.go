@@ -0,0 +1,54 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//  minAuthSecretLength is min length of token signing secret.
+const minAuthSecretLength = 16
+
+//  ParseAuthKeys parses comma or new line separated list of "<key id>:<secret>" token keys.
+//  Empty lines and lines started with # are ignored.
+func ParseAuthKeys(s string) ([]AuthKey, error) {
+	keys := make([]AuthKey, 0)
+
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '\n'
+	})
+	for _, v := range fields {
+		v = strings.TrimSpace(v)
+		if v == "" || strings.HasPrefix(v, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("ошибка чтения ключа токенов: ожидается формат <id>:<secret>")
+		}
+
+		id, secret := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("ошибка чтения ключа токенов: неверный id ключа %q", id)
+		}
+		if len(secret) < minAuthSecretLength {
+			return nil, fmt.Errorf("ошибка чтения ключа токенов %v: длина секрета меньше %v", id, minAuthSecretLength)
+		}
+
+		keys = append(keys, AuthKey{ID: id, Secret: []byte(secret)})
+	}
+
+	return keys, nil
+}
+
+//  ReadAuthKeysFile reads token keys from file, one "<key id>:<secret>" per line.
+func ReadAuthKeysFile(path string) ([]AuthKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла ключей токенов: %w", err)
+	}
+
+	return ParseAuthKeys(string(data))
+}
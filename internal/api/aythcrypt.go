@@ -2,65 +2,249 @@ package api
 
 import (
 	"crypto/aes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 const (
-	key           = "5885c4a300814d5c"
+	//  legacyKey is key of old AES tokens, used only for migrating old cookies.
+	//  Key is public, so old tokens can be forged, accepting must be disabled after migration.
+	legacyKey     = "5885c4a300814d5c"
 	uuidBlockSize = 16
+
+	//  DefTokenTTL is default auth token time to live.
+	DefTokenTTL = time.Hour * 24 * 30
+
+	//  tokenPayloadSize is size of token payload: user id, issued at and expires at unix seconds.
+	tokenPayloadSize = uuidBlockSize + 8 + 8
+	//  randomKeyID is id of random key, generated if keys are not set.
+	randomKeyID = "random"
 )
 
-// AuthCrypt provides crypto tools for auth tokens.
-type AuthCrypt struct {
+var (
+	//  ErrTokenNotValid returns if token has wrong format or signature.
+	ErrTokenNotValid = errors.New("неверный токен")
+	//  ErrTokenExpired returns if token expired.
+	ErrTokenExpired = errors.New("срок действия токена истек")
+)
+
+type (
+	// AuthCrypt provides crypto tools for auth tokens.
+	// Token is "<key id>.<payload>.<signature>", payload contains user id, issued at and expires at time,
+	// signature is HMAC-SHA256 of key id and payload.
+	// First key signs new tokens, all keys verify tokens, so keys can be rotated.
+	AuthCrypt struct {
+		keys        []AuthKey
+		ttl         time.Duration
+		allowLegacy bool
+	}
+
+	// AuthKey is token signing key.
+	AuthKey struct {
+		ID     string
+		Secret []byte
+	}
+
+	// TokenClaims is decoded token data.
+	TokenClaims struct {
+		UserID    uuid.UUID
+		IssuedAt  time.Time
+		ExpiresAt time.Time
+		KeyID     string
+		Legacy    bool
+	}
+
+	// AuthCryptOption sets optional params of AuthCrypt.
+	AuthCryptOption func(c *AuthCrypt)
+)
+
+// WithAuthKeys sets signing keys, first key signs new tokens.
+func WithAuthKeys(keys ...AuthKey) AuthCryptOption {
+	return func(c *AuthCrypt) {
+		c.keys = append(c.keys, keys...)
+	}
+}
+
+// WithTokenTTL sets token time to live, zero ttl is ignored.
+func WithTokenTTL(ttl time.Duration) AuthCryptOption {
+	return func(c *AuthCrypt) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// WithLegacyTokens sets accepting of old AES tokens, signed with public legacy key.
+// Accepted old tokens are reissued, so accepting can be disabled after migration of old cookies.
+func WithLegacyTokens(allow bool) AuthCryptOption {
+	return func(c *AuthCrypt) {
+		c.allowLegacy = allow
+	}
 }
 
 // NewAuthCrypt activates new AuthCrypt.
-func NewAuthCrypt() *AuthCrypt {
-	return &AuthCrypt{}
+// If keys are not set, random key is generated, tokens are not valid after restart.
+// Old AES tokens are accepted by default.
+func NewAuthCrypt(opts ...AuthCryptOption) *AuthCrypt {
+	c := &AuthCrypt{
+		ttl:         DefTokenTTL,
+		allowLegacy: true,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if len(c.keys) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(fmt.Errorf("ошибка генерации ключа токенов: %w", err))
+		}
+		c.keys = []AuthKey{{ID: randomKeyID, Secret: secret}}
+	}
+
+	return c
 }
 
-// EncodeUUID encodes uuid to token.
+// TTL returns token time to live.
+func (c *AuthCrypt) TTL() time.Duration {
+	return c.ttl
+}
+
+// EncodeUUID encodes uuid to signed token.
 func (c *AuthCrypt) EncodeUUID(id uuid.UUID) (string, error) {
 	if id == uuid.Nil {
 		return "", errors.New("ошибка шифрации: входной UUID nil")
 	}
 
-	aesblock, err := aes.NewCipher([]byte(key))
+	now := time.Now()
+	payload := make([]byte, tokenPayloadSize)
+	copy(payload, id[:])
+	binary.BigEndian.PutUint64(payload[uuidBlockSize:], uint64(now.Unix()))
+	binary.BigEndian.PutUint64(payload[uuidBlockSize+8:], uint64(now.Add(c.ttl).Unix()))
+
+	key := c.keys[0]
+	signed := key.ID + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(key.Secret, signed)), nil
+}
+
+// DecodeToken decodes token to uuid.
+func (c *AuthCrypt) DecodeToken(token string) (uuid.UUID, error) {
+	claims, err := c.ParseToken(token)
 	if err != nil {
-		return "", fmt.Errorf("ошибка шифрации: %w", err)
+		return uuid.Nil, err
 	}
 
-	dst := make([]byte, uuidBlockSize)
-	aesblock.Encrypt(dst, id[:])
+	return claims.UserID, nil
+}
+
+// ParseToken checks token signature and expiration, returns token claims.
+// Returns wrapped ErrTokenNotValid or ErrTokenExpired if token not valid.
+func (c *AuthCrypt) ParseToken(token string) (TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		if c.allowLegacy {
+			return decodeLegacyToken(token)
+		}
+		return TokenClaims{}, fmt.Errorf("%w: неверный формат", ErrTokenNotValid)
+	}
+
+	key, ok := c.key(parts[0])
+	if !ok {
+		return TokenClaims{}, fmt.Errorf("%w: неизвестный ключ %v", ErrTokenNotValid, parts[0])
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("%w: %v", ErrTokenNotValid, err.Error())
+	}
+
+	if !hmac.Equal(signature, sign(key.Secret, parts[0]+"."+parts[1])) {
+		return TokenClaims{}, fmt.Errorf("%w: неверная подпись", ErrTokenNotValid)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(payload) != tokenPayloadSize {
+		return TokenClaims{}, fmt.Errorf("%w: неверные данные", ErrTokenNotValid)
+	}
+
+	id, err := uuid.FromBytes(payload[:uuidBlockSize])
+	if err != nil {
+		return TokenClaims{}, fmt.Errorf("%w: %v", ErrTokenNotValid, err.Error())
+	}
+
+	claims := TokenClaims{
+		UserID:    id,
+		IssuedAt:  time.Unix(int64(binary.BigEndian.Uint64(payload[uuidBlockSize:])), 0),
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint64(payload[uuidBlockSize+8:])), 0),
+		KeyID:     key.ID,
+	}
 
-	dstHEX := make([]byte, hex.EncodedLen(len(dst)))
-	hex.Encode(dstHEX, dst)
+	if !time.Now().Before(claims.ExpiresAt) {
+		return TokenClaims{}, ErrTokenExpired
+	}
 
-	return string(dstHEX), nil
+	return claims, nil
 }
 
-// DecodeToken decodes token to uuid.
-func (c *AuthCrypt) DecodeToken(token string) (uuid.UUID, error) {
+// NeedRenew checks that token must be reissued:
+// old AES token, token signed with not primary key, or less than half of ttl left.
+func (c *AuthCrypt) NeedRenew(claims TokenClaims) bool {
+	if claims.Legacy || claims.KeyID != c.keys[0].ID {
+		return true
+	}
+
+	return time.Until(claims.ExpiresAt) < c.ttl/2
+}
+
+// key returns verification key by id.
+func (c *AuthCrypt) key(id string) (AuthKey, bool) {
+	for _, k := range c.keys {
+		if k.ID == id {
+			return k, true
+		}
+	}
+
+	return AuthKey{}, false
+}
+
+// sign returns HMAC-SHA256 of data.
+func sign(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
+
+// decodeLegacyToken decodes old AES token to claims.
+func decodeLegacyToken(token string) (TokenClaims, error) {
 	byteToken, err := hex.DecodeString(token)
-	if err != nil {
-		return uuid.Nil, fmt.Errorf("ошибка дешифрации: %w", err)
+	if err != nil || len(byteToken) != aes.BlockSize {
+		return TokenClaims{}, fmt.Errorf("%w: неверный формат", ErrTokenNotValid)
 	}
 
-	aesblock, err := aes.NewCipher([]byte(key))
+	aesblock, err := aes.NewCipher([]byte(legacyKey))
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("ошибка дешифрации: %w", err)
+		return TokenClaims{}, fmt.Errorf("ошибка дешифрации: %w", err)
 	}
 
 	dst := make([]byte, aes.BlockSize)
 	aesblock.Decrypt(dst, byteToken)
 	id, err := uuid.FromBytes(dst)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("ошибка дешифрации: %w", err)
+		return TokenClaims{}, fmt.Errorf("ошибка дешифрации: %w", err)
 	}
 
-	return id, nil
+	return TokenClaims{UserID: id, Legacy: true}, nil
 }
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/tracing"
+	"github.com/atrush/pract_01.git/pkg"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+//  GatewayPrefix is path prefix of REST gateway routes, generated from v2 proto HTTP annotations.
+const GatewayPrefix = "/api/v2"
+
+//  NewGateway returns REST gateway of v2 gRPC API, calls gRPC server by conn.
+//  Requests must be authenticated by Auth middleware,
+//  user from context is passed to gRPC server as bearer token, X-Real-IP header as x-real-ip metadata,
+//  request ID as x-request-id metadata.
+func NewGateway(ctx context.Context, conn *grpc.ClientConn, crypt *AuthCrypt) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+			md := metadata.MD{}
+			if ip := r.Header.Get("X-Real-IP"); ip != "" {
+				md.Set("x-real-ip", ip)
+			}
+			if id := middleware.GetReqID(ctx); id != "" {
+				md.Set(logging.RequestIDMetadata, id)
+			}
+
+			ctxID, ok := ctx.Value(ContextKeyUserID).(string)
+			if !ok {
+				return md
+			}
+			userID, err := uuid.Parse(ctxID)
+			if err != nil {
+				return md
+			}
+			if token, err := crypt.EncodeUUID(userID); err == nil {
+				md.Set("authorization", "Bearer "+token)
+			}
+
+			return md
+		}),
+	)
+
+	if err := pbv2.RegisterURLsHandlerClient(ctx, mux, pbv2.NewURLsClient(conn)); err != nil {
+		return nil, fmt.Errorf("ошибка инициализации gateway:%w", err)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//  user is authenticated by Auth middleware and passed as token
+		r.Header.Del("Authorization")
+		mux.ServeHTTP(w, r)
+	}), nil
+}
+
+//  dialGRPC returns connection to gRPC server of config, used by gateway.
+//  If EnableHTTPS true, server certificate is verified by the same certificate file.
+func dialGRPC(cfg *pkg.Config) (*grpc.ClientConn, error) {
+	host, port, err := net.SplitHostPort(cfg.GRPCAddress)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка адреса gRPC:%w", err)
+	}
+	//  server listens all interfaces, certificate is issued for loopback
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	creds := insecure.NewCredentials()
+	if cfg.EnableHTTPS {
+		certPath, _, err := pkg.GetCertX509Files()
+		if err != nil {
+			return nil, fmt.Errorf("error serve ssl:%w", err)
+		}
+		creds, err = credentials.NewClientTLSFromFile(certPath, "")
+		if err != nil {
+			return nil, fmt.Errorf("error serve ssl:%w", err)
+		}
+	}
+
+	//  client interceptors send trace context of REST request to gRPC server
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, tracing.DialOptions()...)
+
+	return grpc.Dial(net.JoinHostPort(host, port), opts...)
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mgrpc "github.com/atrush/pract_01.git/internal/grpc"
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+//  initGatewayRouter returns router with gateway to v2 gRPC server, started on loopback as in server.
+func initGatewayRouter(t *testing.T) (*chi.Mux, *Handler) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	h := initHandler(t, tstSt)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcAuth := mgrpc.NewAuthInterceptor(h.auth.crypt, h.auth.Svc, NewSubnet("10.0.0.0/8"))
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcAuth.Unary()), grpc.StreamInterceptor(grpcAuth.Stream()))
+	pbv2.RegisterURLsServer(server, mgrpc.NewURLServerV2(h.svc, h.auth.Svc, h.baseURL))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	gateway, err := NewGateway(context.Background(), conn, h.auth.crypt)
+	require.NoError(t, err)
+
+	return NewRouter(h, false, WithGateway(gateway)), h
+}
+
+func TestGateway(t *testing.T) {
+	r, h := initGatewayRouter(t)
+
+	//  save url as anonymous user, token cookie is set
+	request := httptest.NewRequest(http.MethodPost, "/api/v2/shorten", strings.NewReader(`{"src_url":"https://practicum.yandex.ru/","alias":"practicum"}`))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.JSONEq(t, `{"shortUrl":"`+h.baseURL+`/practicum"}`, w.Body.String())
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	//  conflict is 409 with existing short url in details
+	request = httptest.NewRequest(http.MethodPost, "/api/v2/shorten", strings.NewReader(`{"src_url":"https://practicum.yandex.ru/"}`))
+	request.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Contains(t, w.Body.String(), h.baseURL+"/practicum")
+
+	//  public get
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/urls/practicum", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"srcUrl":"https://practicum.yandex.ru/"}`, w.Body.String())
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/urls/notexist", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	//  user urls by cookie
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/user/urls", nil)
+	request.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list struct {
+		List []struct {
+			ShortURL string `json:"shortUrl"`
+		} `json:"list"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.List, 1)
+	require.Equal(t, h.baseURL+"/practicum", list.List[0].ShortURL)
+
+	//  user id of other user is rejected
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/user/urls?user_id=34e693a6-78e5-4a2f-a6bb-2fad5da50de1", nil)
+	request.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	//  internal stats allowed only from trusted subnet
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/internal/stats", nil)
+	request.Header.Set("X-Real-IP", "10.1.1.1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v2/internal/stats", nil)
+	request.Header.Set("X-Real-IP", "192.168.1.1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	//  v1 routes stay
+	request = httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	request.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -4,9 +4,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"log/slog"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/metrics"
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	"github.com/atrush/pract_01.git/internal/shterrors"
@@ -14,35 +21,66 @@ import (
 	"github.com/google/uuid"
 )
 
-type Handler struct {
-	auth    Auth
-	svc     service.URLShortener
-	baseURL string
-	subnet  *Subnet
+type (
+	Handler struct {
+		auth    Auth
+		svc     service.URLShortener
+		baseURL string
+		subnet  *Subnet
+		metrics *metrics.Metrics
+		logger  *slog.Logger
+	}
+
+	//  HandlerOption sets optional params of handler.
+	HandlerOption func(h *Handler)
+)
+
+//  WithMetrics sets metrics of handler: requests are counted by route, redirects by result,
+//  metrics are served on /metrics for trusted subnet.
+func WithMetrics(m *metrics.Metrics) HandlerOption {
+	return func(h *Handler) {
+		h.metrics = m
+	}
+}
+
+//  WithLogger sets logger of handler, passed to requests with request ID.
+func WithLogger(logger *slog.Logger) HandlerOption {
+	return func(h *Handler) {
+		h.logger = logger
+	}
 }
 
 //  NewHandler init new handler object and return pointer.
-func NewHandler(shtSvc service.URLShortener, authSvc service.UserManager, baseURL string, network string) (*Handler, error) {
-	return &Handler{
+//  crypt signs auth tokens, if nil uses AuthCrypt with random key.
+func NewHandler(shtSvc service.URLShortener, authSvc service.UserManager, crypt *AuthCrypt, baseURL string, network string, opts ...HandlerOption) (*Handler, error) {
+	h := &Handler{
 		svc:     shtSvc,
 		baseURL: baseURL,
-		auth:    NewAuth(authSvc),
+		auth:    NewAuth(authSvc, crypt),
 		subnet:  NewSubnet(network),
-	}, nil
+		logger:  slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
 }
 
-//  Stats stats of stored users and not deleted urls.
+//  Stats stats of stored users and not deleted urls, and state of async delete queue.
 //  Return status 200 and stats respone.
 //  Return status 403 if екгыеув subnet not given, or request subnet not trusted.
 func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
-	urls, err := h.svc.GetCount()
+	urls, err := h.svc.GetCount(r.Context())
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
+		return
 	}
 
 	users, err := h.auth.Svc.GetCount()
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
+		return
 	}
 
 	resp := StatsResponse{
@@ -50,9 +88,20 @@ func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
 		Users: users,
 	}
 
+	if queue, ok := h.svc.GetDeleteQueueStats(); ok {
+		resp.DeleteQueue = &DeleteQueueStatsResponse{
+			Depth:        queue.Depth,
+			Capacity:     queue.Capacity,
+			Deleted:      queue.Deleted,
+			Rejected:     queue.Rejected,
+			Retries:      queue.Retries,
+			DeadLettered: queue.DeadLettered,
+		}
+	}
+
 	jsResult, err := json.Marshal(resp)
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
 		return
 	}
 
@@ -66,7 +115,7 @@ func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
 //  Return status 500 if db not active.
 func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
 	if err := h.svc.Ping(r.Context()); err != nil {
-		h.serverError(w, err.Error())
+		h.serverError(w, r, err.Error())
 		return
 	}
 
@@ -74,30 +123,69 @@ func (h *Handler) Ping(w http.ResponseWriter, r *http.Request) {
 }
 
 //  DeleteBatch handler async soft remove list urls for user.
-//	Return status 202 if list of urls accepted to delete.
+//	Return status 202 and delete job in json format, model DeleteJobResponse, if list of urls accepted to delete.
+//	Location header points to job status route.
 func (h *Handler) DeleteBatch(w http.ResponseWriter, r *http.Request) {
 	var batch BatchDeleteRequest
 	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidJSON, "неверный формат JSON"))
 
 		return
 	}
 
 	userID := h.getUserIDFromContext(r)
 	if userID == uuid.Nil {
-		h.serverError(w, "User ID is empty")
+		h.errorProblem(w, r, errors.New("User ID is empty"))
 
 		return
 	}
 
-	if err := h.svc.DeleteURLList(userID, batch...); err != nil {
-		h.serverError(w, err.Error())
+	job, err := h.svc.DeleteURLList(r.Context(), userID, batch...)
+	if err != nil {
+		h.errorProblem(w, r, err)
 
 		return
 	}
 
-	w.Header().Set("content-type", "text/plain")
+	jsResult, err := json.Marshal(NewDeleteJobResponseFromCanonical(job))
+	if err != nil {
+		h.errorProblem(w, r, err)
+
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.Header().Set("Location", "/api/user/urls/deletions/"+job.ID.String())
 	w.WriteHeader(http.StatusAccepted)
+	w.Write(jsResult)
+}
+
+//  GetDeleteJob handler returns status of user urls delete job.
+//	Return status 200 and delete job in json format, model DeleteJobResponse.
+//	Return status 404 if job not exist or belongs to another user.
+func (h *Handler) GetDeleteJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemBadRequest, "неверный id задачи удаления"))
+		return
+	}
+
+	userID := h.getUserIDFromContext(r)
+	job, err := h.svc.GetDeleteJob(r.Context(), userID, jobID)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	jsResult, err := json.Marshal(NewDeleteJobResponseFromCanonical(job))
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsResult)
 }
 
 // SaveBatch handler save list of urls and return list of shorten urls.
@@ -108,24 +196,30 @@ func (h *Handler) SaveBatch(w http.ResponseWriter, r *http.Request) {
 	//  read batch
 	var batch []BatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidJSON, "неверный формат JSON"))
 		return
 	}
 
 	defer r.Body.Close()
 
 	//  make map id[url] to add
-	listToAdd := make(map[string]string, len(batch))
+	listToAdd := make(map[string]model.ShortURL, len(batch))
 	for _, batchEl := range batch {
-		listToAdd[batchEl.ID] = batchEl.URL
+		expiresAt, err := batchEl.Expiration.Time()
+		if err != nil {
+			h.errorProblem(w, r, err)
+			return
+		}
+
+		listToAdd[batchEl.ID] = model.ShortURL{URL: batchEl.URL, ShortID: batchEl.Alias, ExpiresAt: expiresAt}
 	}
 
 	userID := h.getUserIDFromContext(r)
 
 	//  save mp to db, values in map updates to shortURL
-	savedUrls, err := h.svc.SaveURLList(listToAdd, userID)
+	savedUrls, err := h.svc.SaveURLList(r.Context(), listToAdd, userID)
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
 
 		return
 	}
@@ -135,7 +229,7 @@ func (h *Handler) SaveBatch(w http.ResponseWriter, r *http.Request) {
 	encoder := json.NewEncoder(&buffer)
 	encoder.SetIndent("", "   ")
 	if err := encoder.Encode(NewBatchListResponseFromMap(savedUrls, h.baseURL)); err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
 
 		return
 	}
@@ -145,9 +239,12 @@ func (h *Handler) SaveBatch(w http.ResponseWriter, r *http.Request) {
 	w.Write(buffer.Bytes())
 }
 
-// GetUserUrls handler return list of stored urls for user.
+// GetUserUrls handler return page of stored urls for user.
+// Accept optional query params: cursor, limit, include_deleted, url_contains, created_after and created_before (RFC3339).
 // Return status 200 and list of [short url, url] in json format, model ShortenListResponse,  if user urls founded.
+// If next page exist, sets Link header with rel="next".
 // Return status 204 if urls not founded.
+// Return status 400 if query params not valid.
 func (h *Handler) GetUserUrls(w http.ResponseWriter, r *http.Request) {
 	userID := h.getUserIDFromContext(r)
 
@@ -156,33 +253,160 @@ func (h *Handler) GetUserUrls(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	urlList, err := h.svc.GetUserURLList(r.Context(), userID)
+	filter, err := urlListFilterFromQuery(r)
 	if err != nil {
-		h.serverError(w, err.Error())
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidQuery, err.Error()))
 		return
 	}
 
-	if urlList == nil {
-		w.WriteHeader(http.StatusNoContent)
+	page, err := h.svc.GetUserURLList(r.Context(), userID, filter)
+	if err != nil {
+		h.errorProblem(w, r, err)
 		return
 	}
 
-	if len(urlList) == 0 {
+	if len(page.URLs) == 0 {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	jsResult, err := json.Marshal(NewShortenListResponseFromCanonical(urlList, h.baseURL))
+	jsResult, err := json.Marshal(NewShortenListResponseFromCanonical(page.URLs, h.baseURL))
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
 		return
 	}
 
+	if page.NextCursor != "" {
+		query := r.URL.Query()
+		query.Set("cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf("<%v%v?%v>; rel=\"next\"", h.baseURL, r.URL.Path, query.Encode()))
+	}
+
 	w.Header().Set("content-type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte(jsResult))
 }
 
+// GetURLStats handler return redirect stats for user url.
+// Accept shortID from route params.
+// Return status 200 and stats in json format, model URLStatsResponse, if url belongs to user.
+// Return status 404 if url not founded or belongs to another user.
+func (h *Handler) GetURLStats(w http.ResponseWriter, r *http.Request) {
+	shortID := chi.URLParam(r, "shortID")
+	if shortID == "" {
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemBadRequest, "короткая ссылка не может быть пустой"))
+		return
+	}
+
+	userID := h.getUserIDFromContext(r)
+	stats, err := h.svc.GetClickStats(r.Context(), userID, shortID)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	jsResult, err := json.Marshal(NewURLStatsResponseFromCanonical(stats, h.baseURL))
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsResult)
+}
+
+// CreateAPIKey handler issues new api key for user.
+// Accept optional key name in json format, model APIKeyRequest.
+// Return status 201 and key in json format, model APIKeyResponse, raw key is returned only once.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	incoming := APIKeyRequest{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&incoming); err != nil {
+			writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidJSON, "неверный формат JSON"))
+			return
+		}
+	}
+	defer r.Body.Close()
+
+	if len(incoming.Name) > model.MaxAPIKeyNameLen {
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemBadRequest, fmt.Sprintf("длина имени ключа больше %v", model.MaxAPIKeyNameLen)))
+		return
+	}
+
+	userID := h.getUserIDFromContext(r)
+	key, raw, err := h.auth.Svc.CreateAPIKey(r.Context(), userID, incoming.Name)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	resp := NewAPIKeyResponseFromCanonical(key)
+	resp.Key = raw
+
+	jsResult, err := json.Marshal(resp)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(jsResult)
+}
+
+// GetAPIKeys handler returns list of user api keys, including revoked.
+// Return status 200 and list in json format, model APIKeyResponse.
+// Return status 204 if user has no keys.
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID := h.getUserIDFromContext(r)
+	keys, err := h.auth.Svc.GetAPIKeys(r.Context(), userID)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp := make([]APIKeyResponse, 0, len(keys))
+	for _, v := range keys {
+		resp = append(resp, NewAPIKeyResponseFromCanonical(v))
+	}
+
+	jsResult, err := json.Marshal(resp)
+	if err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsResult)
+}
+
+// RevokeAPIKey handler revokes user api key.
+// Accept key id from route params.
+// Return status 204 if key revoked.
+// Return status 404 if key not found, already revoked or belongs to another user.
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemBadRequest, "неверный id ключа"))
+		return
+	}
+
+	userID := h.getUserIDFromContext(r)
+	if err := h.auth.Svc.RevokeAPIKey(r.Context(), userID, keyID); err != nil {
+		h.errorProblem(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // SaveURLJSONHandler save incoming url and return short url.
 // Accept url in json format, model ShortenRequest.
 // Return status 201 and short url in json format, model ShortenResponse, if saved.
@@ -192,43 +416,58 @@ func (h *Handler) SaveURLJSONHandler(w http.ResponseWriter, r *http.Request) {
 
 	jsBody, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		h.badRequestError(w, err.Error())
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemBadRequest, "ошибка чтения тела запроса"))
 		return
 	}
 	defer r.Body.Close()
 
 	incoming := ShortenRequest{}
 	if err := json.Unmarshal(jsBody, &incoming); err != nil {
-		h.badRequestError(w, "неверный формат JSON")
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidJSON, "неверный формат JSON"))
 		return
 	}
 
 	if err := incoming.Validate(); err != nil {
-		h.badRequestError(w, err.Error())
+		writeProblem(w, r, NewProblem(http.StatusBadRequest, ProblemInvalidURL, err.Error()))
+		return
+	}
+
+	expiresAt, err := incoming.Expiration.Time()
+	if err != nil {
+		h.errorProblem(w, r, err)
 		return
 	}
 
 	// save to db and get shortID
 	userID := h.getUserIDFromContext(r)
-	shortID, err := h.svc.SaveURL(r.Context(), incoming.SrcURL, userID)
+	shortID, err := h.svc.SaveURL(r.Context(), incoming.SrcURL, userID, model.WithAlias(incoming.Alias), model.WithExpiresAt(expiresAt))
 
 	// handle conflict Add
 	isConflict := false
+	code := ""
 	if err != nil {
-		shortID, isConflict = processConflictErr(err)
+		var conflictErr *shterrors.ErrorConflictSaveURL
+		conflictErr, isConflict = processConflictErr(err)
 		if !isConflict {
-			h.badRequestError(w, err.Error())
+			h.errorProblem(w, r, err)
 
 			return
 		}
+
+		shortID = conflictErr.ExistShortURL
+		// url conflict response is kept without code for compatibility
+		if conflictErr.Code == shterrors.ConflictCodeAlias {
+			code = conflictErr.Code
+		}
 	}
 
 	// marshal response
 	jsResult, err := json.Marshal(ShortenResponse{
 		Result: h.baseURL + "/" + shortID,
+		Code:   code,
 	})
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.errorProblem(w, r, err)
 		return
 	}
 
@@ -243,14 +482,14 @@ func (h *Handler) SaveURLJSONHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // SaveURLHandler save incoming url and return short url.
-// Accept url in text format.
+// Accept url in text format, optional expiration in query params expires_at (RFC3339) or ttl (seconds).
 // Return status 201 and short url in text format if saved.
 // Return status 409 and stored short url in text format, if url is exist in db.
 func (h *Handler) SaveURLHandler(w http.ResponseWriter, r *http.Request) {
 	// read incoming URL
 	srcURL, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		h.serverError(w, err.Error())
+		h.serverError(w, r, err.Error())
 		return
 	}
 	defer r.Body.Close()
@@ -260,18 +499,32 @@ func (h *Handler) SaveURLHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expiration, err := expirationFromQuery(r)
+	if err != nil {
+		h.badRequestError(w, err.Error())
+		return
+	}
+
+	expiresAt, err := expiration.Time()
+	if err != nil {
+		h.textError(w, r, err)
+		return
+	}
+
 	userID := h.getUserIDFromContext(r)
-	shortID, err := h.svc.SaveURL(r.Context(), string(srcURL), userID)
+	shortID, err := h.svc.SaveURL(r.Context(), string(srcURL), userID, model.WithExpiresAt(expiresAt))
 
 	// handle conflict Add
 	isConflict := false
 	if err != nil {
-		shortID, isConflict = processConflictErr(err)
+		var conflictErr *shterrors.ErrorConflictSaveURL
+		conflictErr, isConflict = processConflictErr(err)
 		if !isConflict {
-			h.badRequestError(w, err.Error())
+			h.textError(w, r, err)
 
 			return
 		}
+		shortID = conflictErr.ExistShortURL
 	}
 
 	// write response
@@ -287,7 +540,7 @@ func (h *Handler) SaveURLHandler(w http.ResponseWriter, r *http.Request) {
 // GetURLHandler return redirect for url by incoming shortID param.
 // Accept shortID from route params.
 // Return status 307 and Location field with stored url in header, if short url founded.
-// Return status 410 if short url founded, but mark as deleted.
+// Return status 410 if short url founded, but mark as deleted or expired.
 // Return status 404 if short url not founded.
 func (h *Handler) GetURLHandler(w http.ResponseWriter, r *http.Request) {
 	shortID := chi.URLParam(r, "shortID")
@@ -297,22 +550,31 @@ func (h *Handler) GetURLHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	storedURL, err := h.svc.GetURL(r.Context(), shortID)
-	if err != nil {
-		h.badRequestError(w, err.Error())
+	if errors.Is(err, shterrors.ErrNotFound) {
+		h.metrics.Redirect(metrics.RedirectMiss)
+		h.notFoundError(w)
 		return
 	}
-
-	if storedURL == (model.ShortURL{}) {
-		h.notFoundError(w)
+	if err != nil {
+		h.textError(w, r, err)
 		return
 	}
 
-	if storedURL.IsDeleted {
+	if storedURL.IsDeleted || storedURL.Expired(time.Now()) {
+		h.metrics.Redirect(metrics.RedirectGone)
 		w.Header().Set("content-type", "text/plain")
 		w.WriteHeader(http.StatusGone)
 		return
 	}
 
+	//  record redirect, errors must not break redirect
+	h.metrics.Redirect(metrics.RedirectHit)
+	click := model.NewClick(shortID, r.Referer(), r.UserAgent(), clientIP(r))
+	if err := h.svc.AddClick(r.Context(), click); err != nil {
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "ошибка записи перехода по ссылке",
+			"short_id", shortID, "error", err.Error())
+	}
+
 	w.Header().Set("content-type", "text/plain")
 	w.Header().Set("Location", storedURL.URL)
 	w.WriteHeader(http.StatusTemporaryRedirect)
@@ -334,18 +596,124 @@ func (h *Handler) getUserIDFromContext(r *http.Request) uuid.UUID {
 	return uuid.Nil
 }
 
+// clientIP returns client ip from X-Real-IP header, or from remote address if header is empty.
+func clientIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// expirationFromQuery reads optional expiration params expires_at and ttl from query.
+func expirationFromQuery(r *http.Request) (Expiration, error) {
+	expiration := Expiration{}
+	query := r.URL.Query()
+
+	if v := query.Get("expires_at"); v != "" {
+		expiresAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return Expiration{}, fmt.Errorf("%w: %v", shterrors.ErrExpirationNotValid, err.Error())
+		}
+		expiration.ExpiresAt = &expiresAt
+	}
+
+	if v := query.Get("ttl"); v != "" {
+		ttl, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Expiration{}, fmt.Errorf("%w: %v", shterrors.ErrExpirationNotValid, err.Error())
+		}
+		expiration.TTL = ttl
+	}
+
+	return expiration, nil
+}
+
+// urlListFilterFromQuery reads user urls list filter from query params.
+func urlListFilterFromQuery(r *http.Request) (model.URLListFilter, error) {
+	filter := model.URLListFilter{}
+	query := r.URL.Query()
+
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := model.DecodeURLCursor(v)
+		if err != nil {
+			return model.URLListFilter{}, err
+		}
+		filter.After = &cursor
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return model.URLListFilter{}, fmt.Errorf("неверный limit: %v", v)
+		}
+		filter.Limit = limit
+	}
+
+	if v := query.Get("include_deleted"); v != "" {
+		includeDeleted, err := strconv.ParseBool(v)
+		if err != nil {
+			return model.URLListFilter{}, fmt.Errorf("неверный include_deleted: %v", v)
+		}
+		filter.IncludeDeleted = includeDeleted
+	}
+
+	filter.URLContains = query.Get("url_contains")
+
+	if v := query.Get("created_after"); v != "" {
+		createdAfter, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.URLListFilter{}, fmt.Errorf("неверный created_after: %w", err)
+		}
+		filter.CreatedAfter = createdAfter
+	}
+
+	if v := query.Get("created_before"); v != "" {
+		createdBefore, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return model.URLListFilter{}, fmt.Errorf("неверный created_before: %w", err)
+		}
+		filter.CreatedBefore = createdBefore
+	}
+
+	return filter, nil
+}
+
 // processConflictErr check if error is conflict adding URL.
-// If incoming url exist return shortID from error and true.
-func processConflictErr(err error) (string, bool) {
-	if err != nil && errors.Is(err, &shterrors.ErrorConflictSaveURL{}) {
-		conflictErr, _ := err.(*shterrors.ErrorConflictSaveURL)
-		return conflictErr.ExistShortURL, true
+// If incoming url or alias exist return conflict error and true.
+func processConflictErr(err error) (*shterrors.ErrorConflictSaveURL, bool) {
+	var conflictErr *shterrors.ErrorConflictSaveURL
+	if err != nil && errors.As(err, &conflictErr) {
+		return conflictErr, true
 	}
-	return "", false
+	return nil, false
+}
+
+//  errorProblem writes error of JSON route as problem, conflict short url is returned with base url.
+func (h *Handler) errorProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := requestProblem(r, err)
+	if p.ShortURL != "" {
+		p.ShortURL = h.baseURL + "/" + p.ShortURL
+	}
+
+	writeProblem(w, r, p)
+}
+
+//  textError writes error of text route as plain text, internal errors are logged and not returned.
+func (h *Handler) textError(w http.ResponseWriter, r *http.Request, err error) {
+	p := requestProblem(r, err)
+
+	http.Error(w, p.Detail, p.Status)
 }
 
-func (h *Handler) serverError(w http.ResponseWriter, errText string) {
-	http.Error(w, errText, http.StatusInternalServerError)
+//  serverError logs internal error of text route, returns error without details.
+func (h *Handler) serverError(w http.ResponseWriter, r *http.Request, errText string) {
+	logging.FromContext(r.Context()).ErrorContext(r.Context(), "внутренняя ошибка сервера", "error", errText)
+	http.Error(w, "внутренняя ошибка сервера", http.StatusInternalServerError)
 }
 
 func (h *Handler) badRequestError(w http.ResponseWriter, errText string) {
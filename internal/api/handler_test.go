@@ -14,13 +14,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/metrics"
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	st "github.com/atrush/pract_01.git/internal/storage"
 	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/internal/storage/instrumented"
 	"github.com/atrush/pract_01.git/pkg"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -88,7 +94,7 @@ func TestHandler_SaveConflict(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			tstSt, err := infile.NewFileStorage("")
+			tstSt, err := infile.NewFileStorage(context.Background(), "")
 			require.NoError(t, err)
 
 			if tt.initFixtures != nil {
@@ -99,6 +105,304 @@ func TestHandler_SaveConflict(t *testing.T) {
 	}
 }
 
+func TestHandler_SaveAlias(t *testing.T) {
+	tests := []HandlerTest{
+		{
+			name:                "POST alias /api/shorten",
+			method:              http.MethodPost,
+			url:                 "/api/shorten",
+			body:                "{\"url\": \"https://practicum.yandex.ru\", \"alias\": \"spring-sale\"}",
+			contentType:         "application/json",
+			outCodeExpected:     201,
+			outBodyExpected:     "{\"result\":\"http://localhost:8080/spring-sale\"}",
+			outContTypeExpected: "application/json",
+		},
+		{
+			name:                "POST alias taken /api/shorten",
+			method:              http.MethodPost,
+			url:                 "/api/shorten",
+			body:                "{\"url\": \"https://practicum.yandex.ru\", \"alias\": \"spring-sale\"}",
+			contentType:         "application/json",
+			outCodeExpected:     409,
+			outBodyExpected:     "{\"result\":\"http://localhost:8080/spring-sale\",\"code\":\"alias_taken\"}",
+			outContTypeExpected: "application/json",
+			initFixtures: func(storage st.Storage) {
+				storage.User().AddUser(context.Background(), model.User{
+					ID: uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1"),
+				})
+				storage.URL().SaveURL(context.Background(), model.ShortURL{
+					ID:      uuid.MustParse("49dad1e7-983a-4101-a991-aa0e9523a3b1"),
+					ShortID: "spring-sale",
+					URL:     "https://yandex.ru",
+					UserID:  uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1")})
+			},
+		},
+		{
+			name:            "POST reserved alias /api/shorten",
+			method:          http.MethodPost,
+			url:             "/api/shorten",
+			body:            "{\"url\": \"https://practicum.yandex.ru\", \"alias\": \"api\"}",
+			contentType:     "application/json",
+			outCodeExpected: 400,
+		},
+		{
+			name:            "POST not valid alias /api/shorten/batch",
+			method:          http.MethodPost,
+			url:             "/api/shorten/batch",
+			body:            "[{\"correlation_id\": \"1\", \"original_url\": \"https://practicum.yandex.ru\", \"alias\": \"a/b\"}]",
+			contentType:     "application/json",
+			outCodeExpected: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tstSt, err := infile.NewFileStorage(context.Background(), "")
+			require.NoError(t, err)
+
+			if tt.initFixtures != nil {
+				tt.initFixtures(tstSt)
+			}
+			tt.CheckTest(tstSt, t)
+		})
+	}
+}
+
+func TestHandler_GetURLStats(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	ownerID := uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1")
+	_, err = tstSt.User().AddUser(context.Background(), model.User{ID: ownerID})
+	require.NoError(t, err)
+	_, err = tstSt.URL().SaveURL(context.Background(), model.ShortURL{
+		ID:      uuid.MustParse("49dad1e7-983a-4101-a991-aa0e9523a3b1"),
+		ShortID: "1xQ6p+JI",
+		URL:     "https://practicum.yandex.ru/",
+		UserID:  ownerID})
+	require.NoError(t, err)
+
+	h := initHandler(t, tstSt)
+	r := NewRouter(h, false)
+
+	//  redirects
+	for _, ip := range []string{"192.168.1.1", "192.168.1.2", "192.168.1.1"} {
+		request := httptest.NewRequest(http.MethodGet, "/1xQ6p+JI", nil)
+		request.Header.Set("X-Real-IP", ip)
+		request.Header.Set("Referer", "https://ya.ru/")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	}
+
+	token, err := h.auth.crypt.EncodeUUID(ownerID)
+	require.NoError(t, err)
+
+	//  owner stats, clicks are written async
+	stats := URLStatsResponse{}
+	require.Eventually(t, func() bool {
+		request := httptest.NewRequest(http.MethodGet, "/api/user/urls/1xQ6p+JI/stats", nil)
+		request.AddCookie(&http.Cookie{Name: "token", Value: token})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		stats = URLStatsResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+		return stats.Clicks == 3
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, 2, stats.UniqueIPs)
+	assert.Equal(t, map[string]int{"https://ya.ru/": 3}, stats.Referrers)
+	assert.NotNil(t, stats.LastClickAt)
+
+	//  stats of another user
+	request := httptest.NewRequest(http.MethodGet, "/api/user/urls/1xQ6p+JI/stats", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandler_GetUserUrlsPagination(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	ownerID := uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1")
+	_, err = tstSt.User().AddUser(context.Background(), model.User{ID: ownerID})
+	require.NoError(t, err)
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		_, err = tstSt.URL().SaveURL(context.Background(), model.ShortURL{
+			ID:        uuid.New(),
+			ShortID:   fmt.Sprintf("url%v", i),
+			URL:       fmt.Sprintf("https://practicum.yandex.ru/%v", i),
+			IsDeleted: i == 4,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+			UserID:    ownerID})
+		require.NoError(t, err)
+	}
+
+	h := initHandler(t, tstSt)
+	r := NewRouter(h, false)
+	token, err := h.auth.crypt.EncodeUUID(ownerID)
+	require.NoError(t, err)
+
+	getPage := func(url string) ([]ShortenListResponse, string, int) {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		request.AddCookie(&http.Cookie{Name: "token", Value: token})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+
+		list := make([]ShortenListResponse, 0)
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		}
+		return list, w.Header().Get("Link"), w.Code
+	}
+
+	//  walk pages by Link header
+	shortURLs := make([]string, 0)
+	link := "/api/user/urls?limit=3"
+	for link != "" {
+		list, nextLink, code := getPage(link)
+		require.Equal(t, http.StatusOK, code)
+		for _, v := range list {
+			shortURLs = append(shortURLs, v.ShortURL)
+		}
+
+		link = ""
+		if nextLink != "" {
+			require.True(t, strings.HasSuffix(nextLink, `>; rel="next"`))
+			link = strings.TrimPrefix(strings.TrimSuffix(nextLink, `>; rel="next"`), "<http://localhost:8080")
+		}
+	}
+	assert.Equal(t, []string{
+		"http://localhost:8080/url0",
+		"http://localhost:8080/url1",
+		"http://localhost:8080/url2",
+		"http://localhost:8080/url3",
+	}, shortURLs)
+
+	//  filters
+	list, nextLink, code := getPage("/api/user/urls?include_deleted=true&url_contains=yandex.ru/4")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, nextLink)
+	require.Len(t, list, 1)
+	assert.Equal(t, "http://localhost:8080/url4", list[0].ShortURL)
+
+	after := created.Add(time.Minute).Format(time.RFC3339)
+	before := created.Add(3 * time.Minute).Format(time.RFC3339)
+	list, _, code = getPage("/api/user/urls?created_after=" + after + "&created_before=" + before)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, list, 1)
+	assert.Equal(t, "http://localhost:8080/url2", list[0].ShortURL)
+
+	_, _, code = getPage("/api/user/urls?url_contains=google")
+	assert.Equal(t, http.StatusNoContent, code)
+
+	_, _, code = getPage("/api/user/urls?cursor=wrong")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	_, _, code = getPage("/api/user/urls?limit=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandler_APIKeys(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	h := initHandler(t, tstSt)
+	r := NewRouter(h, false)
+
+	do := func(method string, url string, body string, cookie *http.Cookie, bearer string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, url, strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		if cookie != nil {
+			request.AddCookie(cookie)
+		}
+		if bearer != "" {
+			request.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		return w
+	}
+
+	//  keys routes require authentication
+	w := do(http.MethodPost, "/api/user/keys", `{"name":"backend"}`, nil, "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	//  anonymous user gets cookie on user routes
+	w = do(http.MethodGet, "/api/user/urls", "", nil, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Len(t, w.Result().Cookies(), 1)
+	cookie := w.Result().Cookies()[0]
+
+	w = do(http.MethodPost, "/api/user/keys", `{"name":"backend"}`, cookie, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	created := APIKeyResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "backend", created.Name)
+	require.True(t, strings.HasPrefix(created.Key, created.Prefix))
+
+	//  only key hash is stored
+	userID, err := h.auth.Svc.AuthAPIKey(context.Background(), created.Key)
+	require.NoError(t, err)
+	keys, err := tstSt.APIKey().GetUserKeys(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotContains(t, keys[0].Hash, created.Key)
+
+	//  bearer key authenticates same user, without cookie
+	w = do(http.MethodPost, "/api/shorten", `{"url":"https://practicum.yandex.ru/"}`, nil, created.Key)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+
+	w = do(http.MethodGet, "/api/user/urls", "", cookie, "")
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = do(http.MethodGet, "/api/user/keys", "", nil, created.Key)
+	require.Equal(t, http.StatusOK, w.Code)
+	list := make([]APIKeyResponse, 0)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Empty(t, list[0].Key)
+	assert.Equal(t, created.ID, list[0].ID)
+
+	//  not valid key never creates user
+	w = do(http.MethodGet, "/api/user/urls", "", nil, "shk_wrong")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	//  revoke
+	w = do(http.MethodDelete, "/api/user/keys/"+uuid.New().String(), "", cookie, "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = do(http.MethodDelete, "/api/user/keys/"+created.ID, "", cookie, "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = do(http.MethodGet, "/api/user/urls", "", nil, created.Key)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHandler_NoAnonymousRouteGroup(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	r := NewRouter(initHandler(t, tstSt), false, WithAnonymous(RouteGroupUser, false))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, w.Result().Cookies())
+
+	//  other groups still create anonymous users
+	request = httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url":"https://practicum.yandex.ru/"}`))
+	request.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Len(t, w.Result().Cookies(), 1)
+}
+
 type GoSaveBatch struct {
 	count  int
 	cookie *http.Cookie
@@ -188,12 +492,46 @@ func (g *GoSaveBatch) DeleteBatch() error {
 	g.r.ServeHTTP(w, request)
 
 	res := w.Result()
-	res.Body.Close()
+	defer res.Body.Close()
 
 	if res.StatusCode != 202 {
 		return fmt.Errorf("проверка ответа хендлера удаления, код ответа %v вместо 202", res)
 	}
 
+	var job DeleteJobResponse
+	if err := json.NewDecoder(res.Body).Decode(&job); err != nil {
+		return err
+	}
+	if res.Header.Get("Location") != "/api/user/urls/deletions/"+job.JobID {
+		return fmt.Errorf("проверка ответа хендлера удаления, Location %v не указывает на задачу %v", res.Header.Get("Location"), job.JobID)
+	}
+
+	return g.CheckDeleteJob(res.Header.Get("Location"))
+}
+
+// check delete job status
+func (g *GoSaveBatch) CheckDeleteJob(location string) error {
+	request := httptest.NewRequest("GET", location, nil)
+	request.AddCookie(g.cookie)
+
+	w := httptest.NewRecorder()
+	g.r.ServeHTTP(w, request)
+
+	res := w.Result()
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return fmt.Errorf("проверка ответа хендлера статуса удаления, код ответа %v вместо 200", res.StatusCode)
+	}
+
+	var job DeleteJobResponse
+	if err := json.NewDecoder(res.Body).Decode(&job); err != nil {
+		return err
+	}
+	if len(job.URLs) != len(g.saved) {
+		return fmt.Errorf("проверка ответа хендлера статуса удаления, в задаче %v ссылок вместо %v", len(job.URLs), len(g.saved))
+	}
+
 	return nil
 }
 
@@ -226,7 +564,7 @@ func TestHandler_BatchDelete(t *testing.T) {
 	//	tstSt.Close()
 	//}()
 
-	tstSt, err := infile.NewFileStorage("")
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
 	require.NoError(t, err)
 
 	h := initHandler(t, tstSt)
@@ -269,7 +607,7 @@ func TestHandler_ShortenBatch(t *testing.T) {
 	// 	tstSt.Close()
 	// }()
 
-	tstSt, err := infile.NewFileStorage("")
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
 	require.NoError(t, err)
 
 	h := initHandler(t, tstSt)
@@ -384,6 +722,53 @@ func TestHandler_SaveURLHandler(t *testing.T) {
 					UserID:    uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1")})
 			},
 		},
+		{
+			name:            "GET expired URL",
+			method:          http.MethodGet,
+			url:             "/1xQ6p+JI",
+			outCodeExpected: 410,
+			initFixtures: func(storage st.Storage) {
+				storage.User().AddUser(context.Background(), model.User{
+					ID: uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1"),
+				})
+				storage.URL().SaveURL(context.Background(), model.ShortURL{
+					ID:        uuid.MustParse("49dad1e7-983a-4101-a991-aa0e9523a3b1"),
+					ShortID:   "1xQ6p+JI",
+					URL:       "https://practicum.yandex.ru/",
+					ExpiresAt: time.Now().Add(-time.Minute),
+					UserID:    uuid.MustParse("34e693a6-78e5-4a2f-a6bb-2fad5da50de1")})
+			},
+		},
+		{
+			name:            "POST URL with ttl",
+			method:          http.MethodPost,
+			url:             "/?ttl=3600",
+			body:            "https://practicum.yandex.ru/",
+			outCodeExpected: 201,
+		},
+		{
+			name:            "POST URL with not valid ttl",
+			method:          http.MethodPost,
+			url:             "/?ttl=-1",
+			body:            "https://practicum.yandex.ru/",
+			outCodeExpected: 400,
+		},
+		{
+			name:            "POST JSON URL with ttl and expires_at",
+			method:          http.MethodPost,
+			url:             "/api/shorten",
+			body:            "{\"url\": \"https://practicum.yandex.ru/\", \"ttl\": 60, \"expires_at\": \"2030-01-01T00:00:00Z\"}",
+			contentType:     "application/json",
+			outCodeExpected: 400,
+		},
+		{
+			name:            "POST JSON URL with passed expires_at",
+			method:          http.MethodPost,
+			url:             "/api/shorten",
+			body:            "{\"url\": \"https://practicum.yandex.ru/\", \"expires_at\": \"2020-01-01T00:00:00Z\"}",
+			contentType:     "application/json",
+			outCodeExpected: 400,
+		},
 		{
 			name:            "POST JSON URL not valid content-type",
 			method:          http.MethodPost,
@@ -449,7 +834,7 @@ func TestHandler_SaveURLHandler(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db, err := infile.NewFileStorage("")
+			db, err := infile.NewFileStorage(context.Background(), "")
 			require.NoError(t, err)
 
 			if tt.initFixtures != nil {
@@ -495,15 +880,134 @@ func (tt *HandlerTest) CheckTest(db st.Storage, t *testing.T) {
 
 }
 
+func TestHandler_Metrics(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	svcSht, err := service.NewShortURLService(tstSt, nil)
+	require.NoError(t, err)
+	svcUser, err := service.NewUserService(tstSt)
+	require.NoError(t, err)
+
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "127.0.0.0/8", WithMetrics(metrics.New()))
+	require.NoError(t, err)
+	r := NewRouter(h, false)
+
+	//  redirect miss
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/1xQ6p+JI", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	//  not trusted subnet
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	request := httptest.NewRequest(http.MethodGet, MetricsPath, nil)
+	request.Header.Set("X-Real-IP", "127.0.0.1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `shortener_redirects_total{result="miss"} 1`)
+	assert.Contains(t, body, `shortener_http_requests_total{code="404",method="GET",route="/{shortID}"} 1`)
+	assert.Contains(t, body, `shortener_http_requests_total{code="403",method="GET",route="/metrics"} 1`)
+}
+
+func TestHandler_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	fileSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+	tstSt, err := instrumented.NewStorage(fileSt, func(string, string, time.Duration) {})
+	require.NoError(t, err)
+
+	ownerID := uuid.New()
+	_, err = fileSt.User().AddUser(context.Background(), model.User{ID: ownerID})
+	require.NoError(t, err)
+	_, err = fileSt.URL().SaveURL(context.Background(), model.NewShortURL("https://practicum.yandex.ru/", ownerID, model.WithAlias("traced")))
+	require.NoError(t, err)
+
+	h := initHandler(t, tstSt)
+	r := NewRouter(h, false)
+
+	token, err := h.auth.crypt.EncodeUUID(ownerID)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/traced", nil)
+	request.AddCookie(&http.Cookie{Name: "token", Value: token})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	require.Contains(t, spans, "GET /{shortID}")
+	serverSpan := spans["GET /{shortID}"].SpanContext()
+
+	//  user lookup of auth middleware and url lookup are children of request span
+	for parent, name := range map[string]string{
+		"GET /{shortID}":         "UserService.Exist",
+		"UserService.Exist":      "storage.user.exist",
+		"ShortURLService.GetURL": "storage.url.get_url",
+	} {
+		require.Contains(t, spans, name)
+		require.Contains(t, spans, parent)
+		assert.Equal(t, spans[parent].SpanContext().SpanID(), spans[name].Parent().SpanID(), name)
+		assert.Equal(t, serverSpan.TraceID(), spans[name].SpanContext().TraceID(), name)
+	}
+	assert.Equal(t, serverSpan.SpanID(), spans["ShortURLService.GetURL"].Parent().SpanID())
+
+	require.Contains(t, spans, "ShortURLService.AddClick")
+	assert.Equal(t, serverSpan.SpanID(), spans["ShortURLService.AddClick"].Parent().SpanID())
+}
+
+func TestHandler_RequestID(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	svcSht, err := service.NewShortURLService(tstSt, nil)
+	require.NoError(t, err)
+	svcUser, err := service.NewUserService(tstSt)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	logger, err := logging.New(buf, "info", logging.FormatJSON)
+	require.NoError(t, err)
+
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "", WithLogger(logger))
+	require.NoError(t, err)
+	r := NewRouter(h, false)
+
+	//  memory storage ping fails, internal error is logged with request ID
+	request := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	request.Header.Set(logging.RequestIDHeader, "test-request")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "test-request", w.Header().Get(logging.RequestIDHeader))
+
+	line := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "ERROR", line["level"])
+	assert.Equal(t, "test-request", line[logging.KeyRequestID])
+}
+
 // init handler from db
 func initHandler(t *testing.T, tstSt st.Storage) *Handler {
-	svcSht, err := service.NewShortURLService(tstSt)
+	svcSht, err := service.NewShortURLService(tstSt, nil)
 	require.NoError(t, err)
 
 	svcUser, err := service.NewUserService(tstSt)
 	require.NoError(t, err)
 
-	h, err := NewHandler(svcSht, svcUser, "http://localhost:8080", "")
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "")
 	require.NoError(t, err)
 
 	return h
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -107,14 +108,14 @@ func initTestHandler(b *testing.B) (*chi.Mux, *httptest.ResponseRecorder, *http.
 	b.StopTimer() // останавливаем таймер
 
 	// init db
-	db, err := infile.NewFileStorage("")
+	db, err := infile.NewFileStorage(context.Background(), "")
 
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
 	// init url service
-	svcSht, err := service.NewShortURLService(db)
+	svcSht, err := service.NewShortURLService(db, nil)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -126,7 +127,7 @@ func initTestHandler(b *testing.B) (*chi.Mux, *httptest.ResponseRecorder, *http.
 	}
 
 	// init handler
-	h, err := NewHandler(svcSht, svcUser, "http://localhost:8080", "")
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "")
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"github.com/atrush/pract_01.git/internal/service"
 	"github.com/atrush/pract_01.git/internal/storage/infile"
@@ -115,12 +116,12 @@ func ExampleHandler_SaveBatch() {
 }
 
 func initExampleHandler() (*Handler, error) {
-	tstSt, err := infile.NewFileStorage("")
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
 	if err != nil {
 		return nil, err
 	}
 
-	svcSht, err := service.NewShortURLService(tstSt)
+	svcSht, err := service.NewShortURLService(tstSt, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +131,7 @@ func initExampleHandler() (*Handler, error) {
 		return nil, err
 	}
 
-	h, err := NewHandler(svcSht, svcUser, "http://localhost:8080", "")
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "")
 	if err != nil {
 		return nil, err
 	}
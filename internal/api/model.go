@@ -3,19 +3,31 @@ package api
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/service"
 )
 
 type (
-	//  ShortenRequest request to save the link.
+	//  Expiration optional expiration params of saved link: absolute time or ttl in seconds.
+	Expiration struct {
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+		TTL       int64      `json:"ttl,omitempty"`
+	}
+
+	//  ShortenRequest request to save the link, with optional user defined alias and expiration.
 	ShortenRequest struct {
 		SrcURL string `json:"url" validate:"required,url"`
+		Alias  string `json:"alias,omitempty"`
+		Expiration
 	}
 
 	//  ShortenRequest response with shorten url.
+	//  Code returns on alias conflict.
 	ShortenResponse struct {
 		Result string `json:"result"`
+		Code   string `json:"code,omitempty"`
 	}
 
 	//  ShortenListResponse response list item with shorten url.
@@ -24,10 +36,12 @@ type (
 		SrcURL   string `json:"original_url"`
 	}
 
-	//  BatchRequest request item of list links to save, with external id.
+	//  BatchRequest request item of list links to save, with external id, optional alias and expiration.
 	BatchRequest struct {
-		ID  string `json:"correlation_id"`
-		URL string `json:"original_url"`
+		ID    string `json:"correlation_id"`
+		URL   string `json:"original_url"`
+		Alias string `json:"alias,omitempty"`
+		Expiration
 	}
 
 	//  BatchResponse response list item with external id and shorten url.
@@ -39,10 +53,56 @@ type (
 	//  BatchDeleteRequest request array of urls to delete.
 	BatchDeleteRequest []string
 
+	//  DeleteJobResponse response with status of urls delete job.
+	DeleteJobResponse struct {
+		JobID      string     `json:"job_id"`
+		Status     string     `json:"status"`
+		URLs       []string   `json:"urls"`
+		Error      string     `json:"error,omitempty"`
+		CreatedAt  time.Time  `json:"created_at"`
+		FinishedAt *time.Time `json:"finished_at,omitempty"`
+	}
+
+	//  URLStatsResponse response redirect stats of short url.
+	URLStatsResponse struct {
+		ShortURL    string         `json:"short_url"`
+		Clicks      int            `json:"clicks"`
+		UniqueIPs   int            `json:"unique_ips"`
+		LastClickAt *time.Time     `json:"last_click_at,omitempty"`
+		Referrers   map[string]int `json:"referrers"`
+	}
+
+	//  APIKeyRequest request to issue api key with optional name.
+	APIKeyRequest struct {
+		Name string `json:"name"`
+	}
+
+	//  APIKeyResponse response with api key info, raw key returns only on key creation.
+	APIKeyResponse struct {
+		ID        string     `json:"id"`
+		Name      string     `json:"name"`
+		Prefix    string     `json:"prefix"`
+		Key       string     `json:"key,omitempty"`
+		CreatedAt time.Time  `json:"created_at"`
+		RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	}
+
 	//  StatsResponse response stats of stored users and not deleted urls.
+	//  Delete queue stats are returned if storage deletes urls with async queue.
 	StatsResponse struct {
-		Urls  int `json:"urls"`
-		Users int `json:"users"`
+		Urls        int                       `json:"urls"`
+		Users       int                       `json:"users"`
+		DeleteQueue *DeleteQueueStatsResponse `json:"delete_queue,omitempty"`
+	}
+
+	//  DeleteQueueStatsResponse response state of async url delete queue.
+	DeleteQueueStatsResponse struct {
+		Depth        int   `json:"depth"`
+		Capacity     int   `json:"capacity"`
+		Deleted      int64 `json:"deleted"`
+		Rejected     int64 `json:"rejected"`
+		Retries      int64 `json:"retries"`
+		DeadLettered int64 `json:"dead_lettered"`
 	}
 )
 
@@ -54,6 +114,16 @@ func (s *ShortenRequest) Validate() error {
 	return nil
 }
 
+//  Time returns link expiration time, zero time if expiration not given.
+func (e Expiration) Time() (time.Time, error) {
+	expiresAt := time.Time{}
+	if e.ExpiresAt != nil {
+		expiresAt = *e.ExpiresAt
+	}
+
+	return service.ExpirationTime(expiresAt, time.Duration(e.TTL)*time.Second)
+}
+
 // NewBatchListResponseFromMap makes list of batch response from map[incoming-id]shotID.
 func NewBatchListResponseFromMap(objs map[string]string, baseURL string) []BatchResponse {
 	responseArr := make([]BatchResponse, 0, len(objs))
@@ -78,6 +148,58 @@ func NewShortenListResponseFromCanonical(objs []model.ShortURL, baseURL string)
 	return responseArr
 }
 
+//  NewURLStatsResponseFromCanonical makes redirect stats response from canonical stats.
+func NewURLStatsResponseFromCanonical(obj model.ClickStats, baseURL string) URLStatsResponse {
+	resp := URLStatsResponse{
+		ShortURL:  baseURL + "/" + obj.ShortID,
+		Clicks:    obj.Clicks,
+		UniqueIPs: obj.UniqueIPs,
+		Referrers: obj.Referrers,
+	}
+
+	if !obj.LastClickAt.IsZero() {
+		lastClickAt := obj.LastClickAt
+		resp.LastClickAt = &lastClickAt
+	}
+	return resp
+}
+
+//  NewDeleteJobResponseFromCanonical makes delete job response from canonical delete job.
+func NewDeleteJobResponseFromCanonical(obj model.DeleteJob) DeleteJobResponse {
+	resp := DeleteJobResponse{
+		JobID:     obj.ID.String(),
+		Status:    string(obj.Status),
+		URLs:      obj.ShortIDs,
+		Error:     obj.Error,
+		CreatedAt: obj.CreatedAt,
+	}
+
+	if resp.URLs == nil {
+		resp.URLs = []string{}
+	}
+	if !obj.FinishedAt.IsZero() {
+		finishedAt := obj.FinishedAt
+		resp.FinishedAt = &finishedAt
+	}
+	return resp
+}
+
+//  NewAPIKeyResponseFromCanonical makes api key response from canonical api key.
+func NewAPIKeyResponseFromCanonical(obj model.APIKey) APIKeyResponse {
+	resp := APIKeyResponse{
+		ID:        obj.ID.String(),
+		Name:      obj.Name,
+		Prefix:    obj.Prefix,
+		CreatedAt: obj.CreatedAt,
+	}
+
+	if obj.Revoked() {
+		revokedAt := obj.RevokedAt
+		resp.RevokedAt = &revokedAt
+	}
+	return resp
+}
+
 //  isNotEmpty3986URL checks that string not empty and contains only RFC3986 symbols.
 func isNotEmpty3986URL(url string) bool {
 	ch := `ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789:/?#[]@!$&'()*+,;=-_.~%`
@@ -0,0 +1,466 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//  OpenAPI document routes.
+const (
+	OpenAPIPath = "/api/openapi.json"
+	DocsPath    = "/api/docs"
+)
+
+//go:embed openapi.html
+var docsPage []byte
+
+type (
+	//  apiOperation describes JSON API route for OpenAPI document.
+	apiOperation struct {
+		Method      string
+		Path        string
+		Summary     string
+		Tag         string
+		Params      []apiParam
+		Request     interface{} //  request body type, nil if route has no body
+		RequestType string      //  request content type, application/json if empty
+		Responses   []apiResponse
+		Public      bool //  route does not use auth
+	}
+
+	//  apiParam describes path or query param.
+	apiParam struct {
+		Name        string
+		In          string
+		Description string
+		Type        string
+		Format      string
+	}
+
+	//  apiResponse describes response of route.
+	apiResponse struct {
+		Code        int
+		Description string
+		Body        interface{} //  response body type, nil if response has no json body
+		ContentType string      //  not json content type, or content type of body if set
+		Headers     map[string]string
+	}
+)
+
+var (
+	openAPIOnce sync.Once
+	openAPIDoc  []byte
+)
+
+//  shortIDParam is short url path param.
+var shortIDParam = apiParam{Name: "shortID", In: "path", Description: "короткая ссылка", Type: "string"}
+
+//  problemResponse describes application/problem+json error response.
+func problemResponse(code int, description string) apiResponse {
+	return apiResponse{Code: code, Description: description, Body: Problem{}, ContentType: ProblemContentType}
+}
+
+//  apiOperations returns described routes of NewRouter.
+//  Routes of REST gateway are described by v2 proto.
+func apiOperations() []apiOperation {
+	textURL := "text/plain"
+	return []apiOperation{
+		{
+			Method: http.MethodGet, Path: "/api/internal/stats", Tag: "internal", Public: true,
+			Summary: "Количество сохраненных ссылок и пользователей, состояние очереди удаления, доступно только из доверенной подсети (X-Real-IP)",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "статистика", Body: StatsResponse{}},
+				problemResponse(http.StatusForbidden, "подсеть не доверенная"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: MetricsPath, Tag: "internal", Public: true,
+			Summary: "Метрики Prometheus, доступно только из доверенной подсети (X-Real-IP)",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "метрики в текстовом формате Prometheus", ContentType: textURL},
+				problemResponse(http.StatusForbidden, "подсеть не доверенная"),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/shorten", Tag: "shorten",
+			Summary: "Сохранение ссылки с необязательным alias и временем жизни",
+			Request: ShortenRequest{},
+			Responses: []apiResponse{
+				{Code: http.StatusCreated, Description: "ссылка сохранена", Body: ShortenResponse{}},
+				problemResponse(http.StatusBadRequest, "неверный запрос"),
+				{Code: http.StatusConflict, Description: "ссылка или alias уже существуют, возвращается существующая короткая ссылка", Body: ShortenResponse{}},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/shorten/batch", Tag: "shorten",
+			Summary: "Сохранение списка ссылок",
+			Request: []BatchRequest{},
+			Responses: []apiResponse{
+				{Code: http.StatusCreated, Description: "ссылки сохранены", Body: []BatchResponse{}},
+				problemResponse(http.StatusBadRequest, "неверный запрос"),
+				problemResponse(http.StatusConflict, "ссылка или alias уже существуют, short_url содержит существующую короткую ссылку"),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/user/urls", Tag: "shorten",
+			Summary: "Асинхронное удаление списка коротких ссылок пользователя",
+			Request: BatchDeleteRequest{},
+			Responses: []apiResponse{
+				{Code: http.StatusAccepted, Description: "список принят к удалению, задача удаления", Body: DeleteJobResponse{},
+					Headers: map[string]string{"Location": "ссылка на статус задачи удаления"}},
+				problemResponse(http.StatusBadRequest, "неверный запрос"),
+				problemResponse(http.StatusRequestEntityTooLarge, "список удаления больше размера очереди удаления, разделить список"),
+				problemResponse(http.StatusServiceUnavailable, "очередь удаления переполнена, повторить после Retry-After секунд"),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/user/keys", Tag: "keys",
+			Summary: "Выпуск ключа API, ключ возвращается только один раз",
+			Request: APIKeyRequest{},
+			Responses: []apiResponse{
+				{Code: http.StatusCreated, Description: "ключ выпущен", Body: APIKeyResponse{}},
+				problemResponse(http.StatusBadRequest, "неверный запрос"),
+				problemResponse(http.StatusUnauthorized, "пользователь не авторизован"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/user/keys", Tag: "keys",
+			Summary: "Список ключей API пользователя, включая отозванные",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "список ключей", Body: []APIKeyResponse{}},
+				{Code: http.StatusNoContent, Description: "ключей нет"},
+				problemResponse(http.StatusUnauthorized, "пользователь не авторизован"),
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/api/user/keys/{keyID}", Tag: "keys",
+			Summary: "Отзыв ключа API",
+			Params:  []apiParam{{Name: "keyID", In: "path", Description: "id ключа", Type: "string", Format: "uuid"}},
+			Responses: []apiResponse{
+				{Code: http.StatusNoContent, Description: "ключ отозван"},
+				problemResponse(http.StatusNotFound, "ключ не найден"),
+				problemResponse(http.StatusUnauthorized, "пользователь не авторизован"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/ping", Tag: "user",
+			Summary: "Проверка соединения с хранилищем",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "хранилище доступно"},
+				{Code: http.StatusInternalServerError, Description: "хранилище недоступно", ContentType: textURL},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/user/urls", Tag: "user",
+			Summary: "Страница ссылок пользователя",
+			Params: []apiParam{
+				{Name: "cursor", In: "query", Description: "курсор следующей страницы из заголовка Link", Type: "string"},
+				{Name: "limit", In: "query", Description: "размер страницы", Type: "integer"},
+				{Name: "include_deleted", In: "query", Description: "включая удаленные", Type: "boolean"},
+				{Name: "url_contains", In: "query", Description: "подстрока исходной ссылки", Type: "string"},
+				{Name: "created_after", In: "query", Description: "созданные после", Type: "string", Format: "date-time"},
+				{Name: "created_before", In: "query", Description: "созданные до", Type: "string", Format: "date-time"},
+			},
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "страница ссылок", Body: []ShortenListResponse{},
+					Headers: map[string]string{"Link": "ссылка на следующую страницу, rel=\"next\""}},
+				{Code: http.StatusNoContent, Description: "ссылок нет"},
+				problemResponse(http.StatusBadRequest, "неверные параметры"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/user/urls/{shortID}/stats", Tag: "user",
+			Summary: "Статистика переходов по ссылке пользователя",
+			Params:  []apiParam{shortIDParam},
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "статистика", Body: URLStatsResponse{}},
+				problemResponse(http.StatusNotFound, "ссылка не найдена у пользователя"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/user/urls/deletions/{jobID}", Tag: "user",
+			Summary: "Статус задачи удаления ссылок пользователя",
+			Params:  []apiParam{{Name: "jobID", In: "path", Description: "id задачи удаления", Type: "string", Format: "uuid"}},
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "задача удаления", Body: DeleteJobResponse{}},
+				problemResponse(http.StatusBadRequest, "неверный id задачи"),
+				problemResponse(http.StatusNotFound, "задача не найдена у пользователя"),
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/{shortID}", Tag: "user",
+			Summary: "Переход по короткой ссылке",
+			Params:  []apiParam{shortIDParam},
+			Responses: []apiResponse{
+				{Code: http.StatusTemporaryRedirect, Description: "редирект на исходную ссылку",
+					Headers: map[string]string{"Location": "исходная ссылка"}},
+				{Code: http.StatusNotFound, Description: "ссылка не найдена", ContentType: textURL},
+				{Code: http.StatusGone, Description: "ссылка удалена или истекла", ContentType: textURL},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/", Tag: "user",
+			Summary:     "Сохранение ссылки в текстовом формате",
+			Request:     "",
+			RequestType: textURL,
+			Params: []apiParam{
+				{Name: "expires_at", In: "query", Description: "время истечения", Type: "string", Format: "date-time"},
+				{Name: "ttl", In: "query", Description: "время жизни в секундах", Type: "integer"},
+			},
+			Responses: []apiResponse{
+				{Code: http.StatusCreated, Description: "короткая ссылка", ContentType: textURL},
+				{Code: http.StatusConflict, Description: "ссылка уже существует, возвращается существующая короткая ссылка", ContentType: textURL},
+				{Code: http.StatusBadRequest, Description: "неверный запрос", ContentType: textURL},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: OpenAPIPath, Tag: "docs", Public: true,
+			Summary: "OpenAPI документ",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "OpenAPI 3 документ", ContentType: "application/json"},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: DocsPath, Tag: "docs", Public: true,
+			Summary: "Документация API",
+			Responses: []apiResponse{
+				{Code: http.StatusOK, Description: "HTML страница документации", ContentType: "text/html"},
+			},
+		},
+	}
+}
+
+//  OpenAPIDocument returns OpenAPI 3 document of JSON API, schemas are generated from request and response types.
+func OpenAPIDocument() []byte {
+	openAPIOnce.Do(func() {
+		doc, err := json.MarshalIndent(buildOpenAPI(apiOperations()), "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		openAPIDoc = doc
+	})
+
+	return openAPIDoc
+}
+
+//  OpenAPI handler returns OpenAPI document.
+func (h *Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(OpenAPIDocument())
+}
+
+//  Docs handler returns embedded API documentation page.
+func (h *Handler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("content-type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docsPage)
+}
+
+//  buildOpenAPI builds OpenAPI document from operations.
+func buildOpenAPI(ops []apiOperation) map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]map[string]interface{}{}
+
+	for _, op := range ops {
+		operation := map[string]interface{}{
+			"summary":     op.Summary,
+			"tags":        []string{op.Tag},
+			"operationId": strings.ToLower(op.Method) + operationName(op.Path),
+		}
+		if !op.Public {
+			operation["security"] = []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {}}}
+		}
+
+		if len(op.Params) != 0 {
+			params := make([]map[string]interface{}, 0, len(op.Params))
+			for _, p := range op.Params {
+				schema := map[string]interface{}{"type": p.Type}
+				if p.Format != "" {
+					schema["format"] = p.Format
+				}
+				params = append(params, map[string]interface{}{
+					"name":        p.Name,
+					"in":          p.In,
+					"description": p.Description,
+					"required":    p.In == "path",
+					"schema":      schema,
+				})
+			}
+			operation["parameters"] = params
+		}
+
+		if op.Request != nil {
+			contentType := op.RequestType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					contentType: map[string]interface{}{"schema": typeSchema(reflect.TypeOf(op.Request), schemas)},
+				},
+			}
+		}
+
+		responses := map[string]interface{}{}
+		for _, resp := range op.Responses {
+			r := map[string]interface{}{"description": resp.Description}
+			switch {
+			case resp.Body != nil:
+				bodyType := "application/json"
+				if resp.ContentType != "" {
+					bodyType = resp.ContentType
+				}
+				r["content"] = map[string]interface{}{
+					bodyType: map[string]interface{}{"schema": typeSchema(reflect.TypeOf(resp.Body), schemas)},
+				}
+			case resp.ContentType != "":
+				r["content"] = map[string]interface{}{
+					resp.ContentType: map[string]interface{}{"schema": map[string]interface{}{"type": "string"}},
+				}
+			}
+			if len(resp.Headers) != 0 {
+				headers := map[string]interface{}{}
+				for k, v := range resp.Headers {
+					headers[k] = map[string]interface{}{"description": v, "schema": map[string]interface{}{"type": "string"}}
+				}
+				r["headers"] = headers
+			}
+			responses[strconv.Itoa(resp.Code)] = r
+		}
+		operation["responses"] = responses
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]interface{}{}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Shortener API",
+			"version":     "1.0.0",
+			"description": "JSON API сервиса сокращения ссылок. REST API v2 доступен по " + GatewayPrefix + " и описан в proto/v2.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"cookieAuth": map[string]interface{}{"type": "apiKey", "in": "cookie", "name": "token"},
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "ключ API"},
+			},
+		},
+	}
+}
+
+//  typeSchema returns JSON schema of type, named structs are added to schemas and referenced.
+func typeSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			//  placeholder for recursive types
+			schemas[t.Name()] = nil
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+//  structSchema returns object schema of struct json fields, embedded structs are inlined.
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, omitempty, skip := jsonField(f)
+			if skip {
+				continue
+			}
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				addFields(f.Type)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+
+			properties[name] = typeSchema(f.Type, schemas)
+			if !omitempty && f.Type.Kind() != reflect.Ptr {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) != 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+//  jsonField returns json name and omitempty option of struct field.
+func jsonField(f reflect.StructField) (name string, omitempty bool, skip bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false, true
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, false
+}
+
+//  operationName makes operation id part from path.
+func operationName(path string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '{' || r == '}' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	if b.Len() == 0 {
+		return "Root"
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/atrush/pract_01.git/internal/metrics"
+	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/pkg"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//  openAPISpec is part of OpenAPI document used in tests.
+type openAPISpec struct {
+	OpenAPI    string                                       `json:"openapi"`
+	Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+			Required   []string               `json:"required"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func TestOpenAPI_RoutesDocumented(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	spec := openAPISpec{}
+	require.NoError(t, json.Unmarshal(OpenAPIDocument(), &spec))
+
+	//  router is built as in NewServer, so all served routes are checked
+	m := metrics.New()
+	svcSht, err := service.NewShortURLService(tstSt, nil, service.WithMetrics(m))
+	require.NoError(t, err)
+	svcUser, err := service.NewUserService(tstSt)
+	require.NoError(t, err)
+	h, err := NewHandler(svcSht, svcUser, nil, "http://localhost:8080", "", WithMetrics(m))
+	require.NoError(t, err)
+	routerOpts, err := routerOptionsFromConfig(&pkg.Config{})
+	require.NoError(t, err)
+
+	routes := map[string]bool{}
+	r := NewRouter(h, false, append(routerOpts, WithGateway(http.NotFoundHandler()))...)
+	err = chi.Walk(r, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		//  gateway routes are described by v2 proto
+		if strings.HasPrefix(route, GatewayPrefix+"/") {
+			return nil
+		}
+
+		routes[strings.ToLower(method)+" "+route] = true
+		_, ok := spec.Paths[route][strings.ToLower(method)]
+		assert.True(t, ok, "маршрут %v %v не описан в OpenAPI документе", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, ops := range spec.Paths {
+		for method := range ops {
+			assert.True(t, routes[method+" "+path], "операция %v %v OpenAPI документа отсутствует в роутере", method, path)
+		}
+	}
+}
+
+func TestOpenAPI_Schemas(t *testing.T) {
+	spec := openAPISpec{}
+	require.NoError(t, json.Unmarshal(OpenAPIDocument(), &spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+
+	//  embedded expiration fields are inlined
+	shorten := spec.Components.Schemas["ShortenRequest"]
+	for _, field := range []string{"url", "alias", "expires_at", "ttl"} {
+		assert.Contains(t, shorten.Properties, field)
+	}
+	assert.Equal(t, []string{"url"}, shorten.Required)
+
+	for _, name := range []string{"ShortenResponse", "BatchRequest", "BatchResponse", "ShortenListResponse",
+		"URLStatsResponse", "APIKeyRequest", "APIKeyResponse", "StatsResponse"} {
+		assert.Contains(t, spec.Components.Schemas, name)
+	}
+
+	//  all references are resolved
+	doc := string(OpenAPIDocument())
+	for _, part := range strings.Split(doc, `"$ref": "#/components/schemas/`)[1:] {
+		name := part[:strings.Index(part, `"`)]
+		assert.Contains(t, spec.Components.Schemas, name)
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	tstSt, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+	r := NewRouter(initHandler(t, tstSt), false)
+
+	request := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, string(OpenAPIDocument()), w.Body.String())
+
+	request = httptest.NewRequest(http.MethodGet, DocsPath, nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), OpenAPIPath)
+}
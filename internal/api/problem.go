@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+)
+
+//  ProblemContentType is content type of RFC 7807 error response.
+const ProblemContentType = "application/problem+json"
+
+//  problemTypePrefix is prefix of problem type URI, problem code is appended.
+const problemTypePrefix = "urn:shortener:problem:"
+
+//  deleteRetryAfter is seconds to wait before retry of delete, if delete queue is full.
+const deleteRetryAfter = 1
+
+//  Stable machine-readable codes of error responses.
+const (
+	ProblemBadRequest         = "bad_request"
+	ProblemInvalidJSON        = "invalid_json"
+	ProblemInvalidURL         = "invalid_url"
+	ProblemInvalidQuery       = "invalid_query"
+	ProblemAliasNotValid      = "alias_not_valid"
+	ProblemExpirationNotValid = "expiration_not_valid"
+	ProblemURLExists          = shterrors.ConflictCodeURL
+	ProblemAliasTaken         = shterrors.ConflictCodeAlias
+	ProblemNotFound           = "not_found"
+	ProblemURLNotFound        = "url_not_found"
+	ProblemAPIKeyNotFound     = "api_key_not_found"
+	ProblemUnauthenticated    = "unauthenticated"
+	ProblemForbidden          = "forbidden"
+	ProblemDeleteQueueFull    = "delete_queue_full"
+	ProblemDeleteListTooLarge = "delete_list_too_large"
+	ProblemDeleteJobNotFound  = "delete_job_not_found"
+	ProblemInternal           = "internal_error"
+)
+
+//  Problem is RFC 7807 error response.
+//  Code is stable machine-readable error code, ShortURL is set for conflicts of saved url.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	ShortURL string `json:"short_url,omitempty"`
+
+	//  RetryAfter is seconds to wait before retry, sent in Retry-After header if not zero.
+	RetryAfter int `json:"-"`
+}
+
+//  NewProblem returns problem with code, status and client safe detail.
+func NewProblem(status int, code string, detail string) Problem {
+	return Problem{
+		Type:   problemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+//  problemFromError maps error to problem.
+//  Validation and not found errors are returned with error message,
+//  unknown errors, such as storage errors, are returned without details.
+func problemFromError(err error) Problem {
+	var conflictErr *shterrors.ErrorConflictSaveURL
+
+	switch {
+	case errors.As(err, &conflictErr):
+		code := conflictErr.Code
+		if code == "" {
+			code = ProblemURLExists
+		}
+		p := NewProblem(http.StatusConflict, code, conflictErr.Error())
+		p.ShortURL = conflictErr.ExistShortURL
+		return p
+	case errors.Is(err, shterrors.ErrAliasNotValid):
+		return NewProblem(http.StatusBadRequest, ProblemAliasNotValid, err.Error())
+	case errors.Is(err, shterrors.ErrExpirationNotValid):
+		return NewProblem(http.StatusBadRequest, ProblemExpirationNotValid, err.Error())
+	case errors.Is(err, shterrors.ErrNotFound), errors.Is(err, shterrors.ErrAccessDenied):
+		return NewProblem(http.StatusNotFound, ProblemURLNotFound, err.Error())
+	case errors.Is(err, shterrors.ErrAPIKeyNotFound):
+		return NewProblem(http.StatusNotFound, ProblemAPIKeyNotFound, err.Error())
+	case errors.Is(err, shterrors.ErrDeleteJobNotFound):
+		return NewProblem(http.StatusNotFound, ProblemDeleteJobNotFound, err.Error())
+	case errors.Is(err, shterrors.ErrDeleteQueueFull):
+		p := NewProblem(http.StatusServiceUnavailable, ProblemDeleteQueueFull, shterrors.ErrDeleteQueueFull.Error())
+		p.RetryAfter = deleteRetryAfter
+		return p
+	case errors.Is(err, shterrors.ErrDeleteListTooLarge):
+		return NewProblem(http.StatusRequestEntityTooLarge, ProblemDeleteListTooLarge, err.Error())
+	case errors.Is(err, ErrNotAuthenticated):
+		return NewProblem(http.StatusUnauthorized, ProblemUnauthenticated, err.Error())
+	default:
+		return NewProblem(http.StatusInternalServerError, ProblemInternal, "внутренняя ошибка сервера")
+	}
+}
+
+//  writeProblem writes problem as application/problem+json response.
+func writeProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, p.Title, p.Status)
+		return
+	}
+
+	w.Header().Set("content-type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if p.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(p.RetryAfter))
+	}
+	w.WriteHeader(p.Status)
+	w.Write(body)
+}
+
+//  requestProblem maps error of request to problem, internal errors are logged with details.
+func requestProblem(r *http.Request, err error) Problem {
+	p := problemFromError(err)
+	if p.Status == http.StatusInternalServerError {
+		logging.FromContext(r.Context()).ErrorContext(r.Context(), "ошибка обработки запроса",
+			"method", r.Method, "path", r.URL.Path, "error", err.Error())
+	}
+
+	return p
+}
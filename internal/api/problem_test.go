@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "url conflict",
+			err:        &shterrors.ErrorConflictSaveURL{Err: errors.New("conflict"), ExistShortURL: "1xQ6p+JI", Code: shterrors.ConflictCodeURL},
+			wantStatus: http.StatusConflict,
+			wantCode:   ProblemURLExists,
+		},
+		{
+			name:       "alias not valid",
+			err:        fmt.Errorf("%w: короткий", shterrors.ErrAliasNotValid),
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemAliasNotValid,
+		},
+		{
+			name:       "expiration not valid",
+			err:        shterrors.ErrExpirationNotValid,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemExpirationNotValid,
+		},
+		{
+			name:       "access denied",
+			err:        shterrors.ErrAccessDenied,
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemURLNotFound,
+		},
+		{
+			name:       "api key not found",
+			err:        shterrors.ErrAPIKeyNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemAPIKeyNotFound,
+		},
+		{
+			name:       "delete queue full",
+			err:        fmt.Errorf("ошибка хранилища:%w", shterrors.ErrDeleteQueueFull),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   ProblemDeleteQueueFull,
+		},
+		{
+			name:       "delete list too large",
+			err:        fmt.Errorf("ошибка хранилища:%w", shterrors.ErrDeleteListTooLarge),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   ProblemDeleteListTooLarge,
+		},
+		{
+			name:       "delete job not found",
+			err:        shterrors.ErrDeleteJobNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemDeleteJobNotFound,
+		},
+		{
+			name:       "not authenticated",
+			err:        ErrNotAuthenticated,
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   ProblemUnauthenticated,
+		},
+		{
+			name:       "storage error",
+			err:        errors.New("pq: password authentication failed for user shortener"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ProblemInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := problemFromError(tt.err)
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, tt.wantCode, p.Code)
+			assert.Equal(t, problemTypePrefix+tt.wantCode, p.Type)
+			assert.Equal(t, http.StatusText(tt.wantStatus), p.Title)
+			if tt.wantStatus == http.StatusInternalServerError {
+				assert.NotContains(t, p.Detail, "pq:")
+			}
+		})
+	}
+}
+
+func TestHandler_Problems(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "invalid json /api/shorten",
+			method:     http.MethodPost,
+			url:        "/api/shorten",
+			body:       "{\"url\":",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidJSON,
+		},
+		{
+			name:       "invalid url /api/shorten",
+			method:     http.MethodPost,
+			url:        "/api/shorten",
+			body:       "{\"url\": \"not url\"}",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidURL,
+		},
+		{
+			name:       "invalid json /api/shorten/batch",
+			method:     http.MethodPost,
+			url:        "/api/shorten/batch",
+			body:       "[{",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidJSON,
+		},
+		{
+			name:       "invalid query /api/user/urls",
+			method:     http.MethodGet,
+			url:        "/api/user/urls?limit=abc",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemInvalidQuery,
+		},
+		{
+			name:       "not found /api/user/urls/{shortID}/stats",
+			method:     http.MethodGet,
+			url:        "/api/user/urls/1xQ6p+JI/stats",
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemURLNotFound,
+		},
+		{
+			name:       "bad request /api/user/urls/deletions/{jobID}",
+			method:     http.MethodGet,
+			url:        "/api/user/urls/deletions/not-uuid",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   ProblemBadRequest,
+		},
+		{
+			name:       "not found /api/user/urls/deletions/{jobID}",
+			method:     http.MethodGet,
+			url:        "/api/user/urls/deletions/" + uuid.NewString(),
+			wantStatus: http.StatusNotFound,
+			wantCode:   ProblemDeleteJobNotFound,
+		},
+		{
+			name:       "unauthenticated /api/user/keys",
+			method:     http.MethodGet,
+			url:        "/api/user/keys",
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   ProblemUnauthenticated,
+		},
+		{
+			name:       "forbidden /api/internal/stats",
+			method:     http.MethodGet,
+			url:        "/api/internal/stats",
+			wantStatus: http.StatusForbidden,
+			wantCode:   ProblemForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tstSt, err := infile.NewFileStorage(context.Background(), "")
+			require.NoError(t, err)
+
+			r := NewRouter(initHandler(t, tstSt), false)
+			request := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, request)
+
+			result := w.Result()
+			defer result.Body.Close()
+
+			require.Equal(t, tt.wantStatus, result.StatusCode)
+			require.Equal(t, ProblemContentType, result.Header.Get("Content-Type"))
+
+			p := Problem{}
+			require.NoError(t, json.NewDecoder(result.Body).Decode(&p))
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, tt.wantCode, p.Code)
+			assert.Equal(t, problemTypePrefix+tt.wantCode, p.Type)
+			assert.Equal(t, request.URL.Path, p.Instance)
+		})
+	}
+}
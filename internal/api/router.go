@@ -1,14 +1,83 @@
 package api
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/tracing"
+)
+
+//  MetricsPath is route of prometheus metrics, available for trusted subnet.
+const MetricsPath = "/metrics"
+
+//  Route groups with configurable anonymous user creation.
+const (
+	RouteGroupShorten = "shorten" //  json routes of saving and deleting urls
+	RouteGroupUser    = "user"    //  redirect, text saving and user urls routes
+	RouteGroupKeys    = "keys"    //  user api keys routes
+	RouteGroupGateway = "gateway" //  REST gateway routes of v2 gRPC API
+)
+
+//  RouteGroups is list of route groups with configurable anonymous user creation.
+var RouteGroups = []string{RouteGroupShorten, RouteGroupUser, RouteGroupKeys, RouteGroupGateway}
+
+type (
+	//  RouterOption sets optional params of router.
+	RouterOption func(o *routerOptions)
+
+	//  routerOptions stores optional params of router.
+	routerOptions struct {
+		anonymous map[string]bool
+		gateway   http.Handler
+	}
 )
 
+//  WithAnonymous sets creating of new anonymous user for not authenticated requests of route group.
+//  If not allowed, not authenticated requests get status 401.
+func WithAnonymous(group string, allow bool) RouterOption {
+	return func(o *routerOptions) {
+		o.anonymous[group] = allow
+	}
+}
+
+//  WithGateway mounts REST gateway of v2 gRPC API under GatewayPrefix.
+func WithGateway(gateway http.Handler) RouterOption {
+	return func(o *routerOptions) {
+		o.gateway = gateway
+	}
+}
+
 //  NewRouter init server routes.
-func NewRouter(handler *Handler, debug bool) *chi.Mux {
+//  By default anonymous users are created in shorten and user route groups, api keys routes require authentication.
+func NewRouter(handler *Handler, debug bool, opts ...RouterOption) *chi.Mux {
+	o := routerOptions{
+		anonymous: map[string]bool{
+			RouteGroupShorten: true,
+			RouteGroupUser:    true,
+			RouteGroupKeys:    false,
+			RouteGroupGateway: true,
+		},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	r := chi.NewRouter()
 
+	//  request ID is first, so every middleware logs with request ID
+	r.Use(middleware.RequestID)
+	r.Use(logging.Middleware(handler.logger))
+
+	//  metrics middleware is before other middlewares, so latency includes them
+	if handler.metrics != nil {
+		r.Use(handler.metrics.Middleware)
+	}
+	//  tracing middleware is before auth, so span of request includes user lookup
+	r.Use(tracing.Middleware)
+
 	//  compress middlewares
 	r.Use(middleware.Compress(5, "text/html",
 		"text/css",
@@ -27,26 +96,51 @@ func NewRouter(handler *Handler, debug bool) *chi.Mux {
 		r.Mount("/debug", middleware.Profiler())
 	}
 
+	//  api documentation routes
+	r.Get(OpenAPIPath, handler.OpenAPI)
+	r.Get(DocsPath, handler.Docs)
+
 	//  route for allowed subnets
 	r.Group(func(r chi.Router) {
 		r.Use(handler.subnet.Middleware)
 		r.Get("/api/internal/stats", handler.Stats)
+		if handler.metrics != nil {
+			r.Method(http.MethodGet, MetricsPath, handler.metrics.Handler())
+		}
 	})
 
 	//  json auth routes
 	r.Group(func(r chi.Router) {
-		r.Use(handler.auth.Middleware)
+		r.Use(handler.auth.Handler(o.anonymous[RouteGroupShorten]))
 		r.Use(middleware.AllowContentType("application/json"))
 		r.Post("/api/shorten/batch", handler.SaveBatch)
 		r.Post("/api/shorten", handler.SaveURLJSONHandler)
 		r.Delete("/api/user/urls", handler.DeleteBatch)
 	})
 
+	//  api keys routes
+	r.Group(func(r chi.Router) {
+		r.Use(handler.auth.Handler(o.anonymous[RouteGroupKeys]))
+		r.Post("/api/user/keys", handler.CreateAPIKey)
+		r.Get("/api/user/keys", handler.GetAPIKeys)
+		r.Delete("/api/user/keys/{keyID}", handler.RevokeAPIKey)
+	})
+
+	//  v2 REST gateway routes
+	if o.gateway != nil {
+		r.Group(func(r chi.Router) {
+			r.Use(handler.auth.Handler(o.anonymous[RouteGroupGateway]))
+			r.Handle(GatewayPrefix+"/*", o.gateway)
+		})
+	}
+
 	// auth routes
 	r.Group(func(r chi.Router) {
-		r.Use(handler.auth.Middleware)
+		r.Use(handler.auth.Handler(o.anonymous[RouteGroupUser]))
 		r.Get("/ping", handler.Ping)
 		r.Get("/api/user/urls", handler.GetUserUrls)
+		r.Get("/api/user/urls/{shortID}/stats", handler.GetURLStats)
+		r.Get("/api/user/urls/deletions/{jobID}", handler.GetDeleteJob)
 		r.Get("/{shortID}", handler.GetURLHandler)
 		r.Post("/", handler.SaveURLHandler)
 	})
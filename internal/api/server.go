@@ -6,60 +6,218 @@ import (
 	"fmt"
 	mgrpc "github.com/atrush/pract_01.git/internal/grpc"
 	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/metrics"
 	"github.com/atrush/pract_01.git/internal/service"
 	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/tracing"
 	"github.com/atrush/pract_01.git/pkg"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"log/slog"
 	"net"
 	"net/http"
+	"strings"
+	"time"
 )
 
 //  Server implements http server
 type Server struct {
 	httpServer http.Server
 	grpcServer *grpc.Server
+	grpcHealth *health.Server
+	grpcConn   *grpc.ClientConn
 	cfg        *pkg.Config
+	logger     *slog.Logger
 }
 
 //  NewServer return new server
-func NewServer(cfg *pkg.Config, db storage.Storage) (*Server, error) {
+//  If metrics not nil, http and gRPC requests are counted and metrics are served on MetricsPath.
+//  Logger is passed to http and gRPC requests with request ID, if nil default logger is used.
+func NewServer(cfg *pkg.Config, db storage.Storage, m *metrics.Metrics, logger *slog.Logger) (*Server, error) {
 	if cfg == nil {
 		return nil, errors.New("error server initiation: config is nil")
 	}
+	if logger == nil {
+		logger = slog.Default()
+	}
 
-	svcSht, err := service.NewShortURLService(db)
+	aliasValidator := service.NewAliasValidator(cfg.AliasCharset, cfg.AliasMinLength, cfg.AliasMaxLength, cfg.AliasReserved)
+	svcSht, err := service.NewShortURLService(db, aliasValidator, service.WithMetrics(m))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации handler:%w", err)
 	}
+	if err := m.RegisterDeleteQueue(svcSht.GetDeleteQueueStats); err != nil {
+		return nil, fmt.Errorf("ошибка инициализации метрик:%w", err)
+	}
 	svcUser, err := service.NewUserService(db)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации handler:%w", err)
 	}
 
-	handler, err := NewHandler(svcSht, svcUser, cfg.BaseURL, cfg.TrustedSubnet)
+	crypt, err := newAuthCryptFromConfig(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации handler:%w", err)
+	}
+
+	handler, err := NewHandler(svcSht, svcUser, crypt, cfg.BaseURL, cfg.TrustedSubnet, WithMetrics(m), WithLogger(logger))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации handler:%w", err)
 	}
 
-	grpcServer := grpc.NewServer()
-	pb.RegisterURLsServer(grpcServer, mgrpc.NewURLServer(svcSht, cfg.BaseURL))
+	routerOpts, err := routerOptionsFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации handler:%w", err)
+	}
+
+	//  REST gateway calls gRPC server by network, connection is lazy
+	grpcConn, err := dialGRPC(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации gateway:%w", err)
+	}
+	gateway, err := NewGateway(context.Background(), grpcConn, crypt)
+	if err != nil {
+		return nil, err
+	}
+	routerOpts = append(routerOpts, WithGateway(gateway))
+
+	grpcOpts, err := grpcOptionsFromConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации gRPC:%w", err)
+	}
+
+	grpcAuth := mgrpc.NewAuthInterceptor(crypt, svcUser, NewSubnet(cfg.TrustedSubnet), mgrpc.WithTrustedProxies(NewSubnet(cfg.TrustedProxies)))
+	//  metrics, tracing and logging interceptors are first, so calls rejected by auth are counted, traced and logged
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(m.UnaryServerInterceptor(), tracing.UnaryServerInterceptor(),
+			logging.UnaryServerInterceptor(logger), grpcAuth.Unary()),
+		grpc.ChainStreamInterceptor(m.StreamServerInterceptor(), tracing.StreamServerInterceptor(),
+			logging.StreamServerInterceptor(logger), grpcAuth.Stream()),
+	)
+
+	grpcServer := grpc.NewServer(grpcOpts...)
+	pb.RegisterURLsServer(grpcServer, mgrpc.NewURLServer(svcSht, svcUser, cfg.BaseURL))
+	pbv2.RegisterURLsServer(grpcServer, mgrpc.NewURLServerV2(svcSht, svcUser, cfg.BaseURL))
+
+	//  health service for load balancers, reflection for grpcurl
+	grpcHealth := health.NewServer()
+	for service := range grpcServer.GetServiceInfo() {
+		grpcHealth.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(grpcServer, grpcHealth)
+	reflection.Register(grpcServer)
 
 	return &Server{
 		httpServer: http.Server{
-			Addr:    cfg.ServerPort,
-			Handler: NewRouter(handler, cfg.Debug),
+			Addr:     cfg.ServerPort,
+			Handler:  NewRouter(handler, cfg.Debug, routerOpts...),
+			ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
 		},
 		grpcServer: grpcServer,
+		grpcHealth: grpcHealth,
+		grpcConn:   grpcConn,
 		cfg:        cfg,
+		logger:     logger,
 	}, nil
 }
 
+//  grpcOptionsFromConfig returns gRPC server options from config.
+//  If EnableHTTPS true, gRPC server uses the same certificate as HTTPS server.
+func grpcOptionsFromConfig(cfg *pkg.Config) ([]grpc.ServerOption, error) {
+	if !cfg.EnableHTTPS {
+		return nil, nil
+	}
+
+	certPath, keyPath, err := pkg.GetCertX509Files()
+	if err != nil {
+		return nil, fmt.Errorf("error serve ssl:%w", err)
+	}
+
+	creds, err := credentials.NewServerTLSFromFile(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error serve ssl:%w", err)
+	}
+
+	return []grpc.ServerOption{grpc.Creds(creds)}, nil
+}
+
+//  newAuthCryptFromConfig inits auth tokens crypt with keys from config and keys file.
+//  Keys from config are first, first key signs new tokens.
+//  Keys are required, only in debug mode random key is used if keys are not set.
+func newAuthCryptFromConfig(cfg *pkg.Config, logger *slog.Logger) (*AuthCrypt, error) {
+	keys, err := ParseAuthKeys(cfg.AuthKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AuthKeysFile != "" {
+		fileKeys, err := ReadAuthKeysFile(cfg.AuthKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, fileKeys...)
+	}
+
+	if len(keys) == 0 {
+		if !cfg.Debug {
+			return nil, errors.New("ключи токенов не заданы: задайте AUTH_KEYS или AUTH_KEYS_FILE")
+		}
+		logger.Warn("ключи токенов не заданы, используется случайный ключ: токены не действительны после перезапуска")
+	}
+	if !cfg.AuthDisableLegacy {
+		logger.Info("старые AES токены принимаются и перевыпускаются: после миграции cookie отключите прием через AUTH_DISABLE_LEGACY")
+	}
+
+	return NewAuthCrypt(
+		WithAuthKeys(keys...),
+		WithTokenTTL(time.Duration(cfg.AuthTokenTTL)*time.Second),
+		WithLegacyTokens(!cfg.AuthDisableLegacy),
+	), nil
+}
+
+//  routerOptionsFromConfig returns router options from config.
+//  AuthRequired is comma separated list of route groups, that require authentication, "-" means none.
+func routerOptionsFromConfig(cfg *pkg.Config) ([]RouterOption, error) {
+	required := make(map[string]bool)
+	for _, v := range strings.Split(cfg.AuthRequired, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" || v == "-" {
+			continue
+		}
+		if !isRouteGroup(v) {
+			return nil, fmt.Errorf("неизвестная группа маршрутов: %v", v)
+		}
+		required[v] = true
+	}
+
+	opts := make([]RouterOption, 0, len(RouteGroups))
+	for _, v := range RouteGroups {
+		opts = append(opts, WithAnonymous(v, !required[v]))
+	}
+	return opts, nil
+}
+
+//  isRouteGroup checks that route group exist.
+func isRouteGroup(group string) bool {
+	for _, v := range RouteGroups {
+		if v == group {
+			return true
+		}
+	}
+	return false
+}
+
 //  Run starts GRPC server
 func (s *Server) RunGRPC() error {
-	listen, err := net.Listen("tcp", ":3201")
+	listen, err := net.Listen("tcp", s.cfg.GRPCAddress)
 	if err != nil {
 		return err
 	}
+	s.logger.Info("gRPC сервер запущен", "address", listen.Addr().String())
 
 	return s.grpcServer.Serve(listen)
 }
@@ -67,6 +225,7 @@ func (s *Server) RunGRPC() error {
 //  Run starts http server
 //  if config EnableHTTPS true runs in HTTPS mode
 func (s *Server) RunHTTP() error {
+	s.logger.Info("HTTP сервер запущен", "address", s.cfg.ServerPort, "https", s.cfg.EnableHTTPS)
 	if s.cfg.EnableHTTPS {
 		certPath, keyPath, err := pkg.GetCertX509Files()
 		if err != nil {
@@ -89,10 +248,15 @@ func handleServerCloseErr(err error) error {
 
 //  Shutdown sutdown http server
 func (s *Server) ShutdownHTTP(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	return s.grpcConn.Close()
 }
 
-//  Shutdown sutdown http server
+//  Shutdown sutdown grpc server, marks services not serving before stop
 func (s *Server) ShutdownGRPC() {
+	s.grpcHealth.Shutdown()
 	s.grpcServer.GracefulStop()
 }
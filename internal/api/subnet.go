@@ -22,20 +22,30 @@ func (s *Subnet) Middleware(next http.Handler) http.Handler {
 		strIP := r.Header.Get("X-Real-IP")
 		ip := net.ParseIP(strIP)
 
-		if ip != nil && len(s.AllowMasks) != 0 {
-			for _, mask := range s.AllowMasks {
-				if mask.Contains(ip) {
-
-					next.ServeHTTP(w, r)
-					return
-				}
-			}
+		if s.Contains(ip) {
+			next.ServeHTTP(w, r)
+			return
 		}
 
-		http.Error(w, "not allowed", http.StatusForbidden)
+		writeProblem(w, r, NewProblem(http.StatusForbidden, ProblemForbidden, "доступ запрещен"))
 	})
 }
 
+// Contains checks that ip in allowed network
+func (s *Subnet) Contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, mask := range s.AllowMasks {
+		if mask.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
 //  readMasks reads ip network masks from config string
 func readMasks(cfg string) []net.IPNet {
 	strMasks := strings.Split(cfg, ",")
@@ -0,0 +1,243 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	//  TokenDecoder decodes user auth token, the same as HTTP token cookie.
+	TokenDecoder interface {
+		DecodeToken(token string) (uuid.UUID, error)
+	}
+
+	//  TrustedSubnet checks that ip is in trusted subnet.
+	TrustedSubnet interface {
+		Contains(ip net.IP) bool
+	}
+
+	//  AuthInterceptor authenticates users of gRPC methods by "authorization: Bearer <token or api key>" metadata
+	//  and puts user id to context. Public methods are not authenticated,
+	//  internal methods are allowed only from trusted subnet.
+	AuthInterceptor struct {
+		crypt    TokenDecoder
+		users    service.UserManager
+		subnet   TrustedSubnet
+		proxies  TrustedSubnet
+		public   map[string]bool
+		internal map[string]bool
+	}
+
+	//  AuthOption sets optional params of AuthInterceptor.
+	AuthOption func(a *AuthInterceptor)
+
+	contextKey string
+)
+
+//  ContextKeyUserID is context key of authenticated user id.
+var ContextKeyUserID = contextKey("user-id")
+
+//  Full names of methods with special auth rules.
+const (
+	MethodGet                = "/grpc.URLs/Get"
+	MethodGetInternalStats   = "/grpc.URLs/GetInternalStats"
+	MethodGetV2              = "/grpc.v2.URLs/Get"
+	MethodGetInternalStatsV2 = "/grpc.v2.URLs/GetInternalStats"
+
+	//  ServiceHealth and ServiceReflection are public services, used by load balancers and grpcurl.
+	ServiceHealth     = "/grpc.health.v1.Health/"
+	ServiceReflection = "/grpc.reflection.v1alpha.ServerReflection/"
+)
+
+//  NewAuthInterceptor inits new gRPC auth interceptor.
+//  Get methods, health and reflection services are public.
+func NewAuthInterceptor(crypt TokenDecoder, users service.UserManager, subnet TrustedSubnet, opts ...AuthOption) *AuthInterceptor {
+	a := &AuthInterceptor{
+		crypt:    crypt,
+		users:    users,
+		subnet:   subnet,
+		public:   map[string]bool{MethodGet: true, MethodGetV2: true, ServiceHealth: true, ServiceReflection: true},
+		internal: map[string]bool{MethodGetInternalStats: true, MethodGetInternalStatsV2: true},
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+//  WithTrustedProxies sets proxies, that can pass client ip in x-real-ip metadata.
+//  Loopback peer, the REST gateway of server, is always trusted.
+func WithTrustedProxies(proxies TrustedSubnet) AuthOption {
+	return func(a *AuthInterceptor) {
+		a.proxies = proxies
+	}
+}
+
+//  Unary returns unary server interceptor.
+func (a *AuthInterceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+//  Stream returns stream server interceptor.
+func (a *AuthInterceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+//  authServerStream overrides stream context with authenticated user context.
+type authServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+//  Context returns stream context with authenticated user id.
+func (s *authServerStream) Context() context.Context {
+	return s.ctx
+}
+
+//  authorize checks method access, returns context with user id for authenticated methods.
+func (a *AuthInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	if a.public[method] || a.public[serviceName(method)] {
+		return ctx, nil
+	}
+
+	if a.internal[method] {
+		ip := a.clientIP(ctx)
+		if ip == nil || a.subnet == nil || !a.subnet.Contains(ip) {
+			return nil, status.Error(codes.PermissionDenied, "not allowed")
+		}
+		return ctx, nil
+	}
+
+	userID, err := a.authUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return context.WithValue(ctx, ContextKeyUserID, userID), nil
+}
+
+//  authUser authenticates user by bearer token or api key from metadata.
+func (a *AuthInterceptor) authUser(ctx context.Context) (uuid.UUID, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "authorization metadata is empty")
+	}
+
+	const bearerPrefix = "bearer "
+	if len(values[0]) <= len(bearerPrefix) || !strings.EqualFold(values[0][:len(bearerPrefix)], bearerPrefix) {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "authorization must be Bearer <token>")
+	}
+	token := strings.TrimSpace(values[0][len(bearerPrefix):])
+
+	if model.IsAPIKey(token) {
+		userID, err := a.users.AuthAPIKey(ctx, token)
+		if err != nil {
+			return uuid.Nil, status.Error(codes.Unauthenticated, "api key is not valid")
+		}
+		return userID, nil
+	}
+
+	userID, err := a.crypt.DecodeToken(token)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "token is not valid")
+	}
+
+	exist, err := a.users.Exist(ctx, userID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.Internal, "user check failed")
+	}
+	if !exist {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "user not found")
+	}
+
+	return userID, nil
+}
+
+//  serviceName returns service part "/package.Service/" of full method name.
+func serviceName(method string) string {
+	return method[:strings.LastIndex(method, "/")+1]
+}
+
+//  clientIP returns client ip from peer address.
+//  If peer is loopback or trusted proxy, ip is taken from x-real-ip metadata, the same as HTTP X-Real-IP header.
+//  Metadata of other peers is ignored, it can contain any ip.
+func (a *AuthInterceptor) clientIP(ctx context.Context) net.IP {
+	ip := peerIP(ctx)
+	if ip == nil {
+		return nil
+	}
+
+	if ip.IsLoopback() || (a.proxies != nil && a.proxies.Contains(ip)) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("x-real-ip"); len(values) > 0 {
+			return net.ParseIP(values[0])
+		}
+	}
+
+	return ip
+}
+
+//  peerIP returns ip of peer address, nil if peer address is not ip.
+func peerIP(ctx context.Context) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+//  requestUserID returns user id of request.
+//  If user authenticated, request user id is optional and must match authenticated user.
+//  If user not authenticated, request user id is required.
+func requestUserID(ctx context.Context, reqUserID string) (uuid.UUID, error) {
+	authID, authOK := ctx.Value(ContextKeyUserID).(uuid.UUID)
+
+	if reqUserID == "" {
+		if authOK {
+			return authID, nil
+		}
+		return uuid.Nil, ErrorWrongUserID
+	}
+
+	userID, err := uuid.Parse(reqUserID)
+	if err != nil {
+		return uuid.Nil, ErrorWrongUserID
+	}
+
+	if authOK && userID != authID {
+		return uuid.Nil, ErrorUserIDMismatch
+	}
+	return userID, nil
+}
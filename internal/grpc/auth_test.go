@@ -0,0 +1,235 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/model"
+	mk "github.com/atrush/pract_01.git/internal/service/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testToken  = "valid-token"
+	testAPIKey = model.APIKeyPrefix + "valid-key"
+)
+
+//  testDecoder decodes only testToken to userID.
+type testDecoder struct{}
+
+func (d testDecoder) DecodeToken(token string) (uuid.UUID, error) {
+	if token != testToken {
+		return uuid.Nil, errors.New("token is not valid")
+	}
+	return userID, nil
+}
+
+//  testSubnet trusts only 10.0.0.0/8.
+type testSubnet struct{}
+
+func (s testSubnet) Contains(ip net.IP) bool {
+	return ip != nil && ip.To4() != nil && ip.To4()[0] == 10
+}
+
+//  testProxies trusts only proxy 172.16.0.1.
+type testProxies struct{}
+
+func (s testProxies) Contains(ip net.IP) bool {
+	return ip.Equal(net.ParseIP("172.16.0.1"))
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mk.NewMockUserManager(ctrl)
+	users.EXPECT().Exist(gomock.Any(), userID).Return(true, nil).AnyTimes()
+	users.EXPECT().AuthAPIKey(gomock.Any(), testAPIKey).Return(userID, nil).AnyTimes()
+	users.EXPECT().AuthAPIKey(gomock.Any(), gomock.Any()).Return(uuid.Nil, errors.New("not found")).AnyTimes()
+	users.EXPECT().GetCount().Return(2, nil).AnyTimes()
+
+	svc := mk.NewMockURLShortener(ctrl)
+	svc.EXPECT().GetURL(gomock.Any(), url.ShortID).Return(url, nil).AnyTimes()
+	svc.EXPECT().GetUserURLList(gomock.Any(), userID, gomock.Any()).Return(model.URLPage{URLs: []model.ShortURL{url}}, nil).AnyTimes()
+	svc.EXPECT().GetCount(gomock.Any()).Return(5, nil).AnyTimes()
+
+	ctx := context.Background()
+	urlServer := NewURLServer(svc, users, baseURL)
+	auth := NewAuthInterceptor(testDecoder{}, users, testSubnet{})
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer(func(s *grpc.Server) { pb.RegisterURLsServer(s, urlServer) }, grpc.UnaryInterceptor(auth.Unary()))))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pb.NewURLsClient(conn)
+	withMD := func(kv ...string) context.Context {
+		return metadata.NewOutgoingContext(ctx, metadata.Pairs(kv...))
+	}
+
+	t.Run("public method without auth", func(t *testing.T) {
+		resp, err := client.Get(ctx, &pb.GetRequest{ShortId: url.ShortID})
+		require.NoError(t, err)
+		require.Equal(t, url.URL, resp.SrcUrl)
+	})
+
+	t.Run("no auth metadata", func(t *testing.T) {
+		_, err := client.GetList(ctx, &pb.GetListRequest{UserId: userID.String()})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("not bearer auth", func(t *testing.T) {
+		_, err := client.GetList(withMD("authorization", "Basic "+testToken), &pb.GetListRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("not valid token", func(t *testing.T) {
+		_, err := client.GetList(withMD("authorization", "Bearer wrong"), &pb.GetListRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("not valid api key", func(t *testing.T) {
+		_, err := client.GetList(withMD("authorization", "Bearer "+model.APIKeyPrefix+"wrong"), &pb.GetListRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("token without user id in request", func(t *testing.T) {
+		resp, err := client.GetList(withMD("authorization", "Bearer "+testToken), &pb.GetListRequest{})
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+		require.Len(t, resp.List, 1)
+	})
+
+	t.Run("api key with matching user id", func(t *testing.T) {
+		resp, err := client.GetList(withMD("authorization", "Bearer "+testAPIKey), &pb.GetListRequest{UserId: userID.String()})
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+		require.Len(t, resp.List, 1)
+	})
+
+	t.Run("user id not match token", func(t *testing.T) {
+		resp, err := client.GetList(withMD("authorization", "Bearer "+testToken), &pb.GetListRequest{UserId: uuid.New().String()})
+		require.NoError(t, err)
+		require.Equal(t, ErrorUserIDMismatch.Error(), resp.Error)
+	})
+
+	t.Run("x-real-ip of not trusted peer is ignored", func(t *testing.T) {
+		_, err := client.GetInternalStats(withMD("x-real-ip", "10.1.2.3"), &pb.GetInternalStatsRequest{})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("internal stats without ip", func(t *testing.T) {
+		_, err := client.GetInternalStats(withMD("authorization", "Bearer "+testToken), &pb.GetInternalStatsRequest{})
+		require.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestAuthInterceptor_InternalMethods(t *testing.T) {
+	auth := NewAuthInterceptor(testDecoder{}, nil, testSubnet{}, WithTrustedProxies(testProxies{}))
+
+	tests := []struct {
+		name    string
+		peer    string
+		realIP  string
+		allowed bool
+	}{
+		{name: "peer from trusted subnet", peer: "10.1.2.3", allowed: true},
+		{name: "peer from not trusted subnet", peer: "192.168.1.1"},
+		{name: "not trusted peer sends trusted ip", peer: "192.168.1.1", realIP: "10.1.2.3"},
+		{name: "gateway sends trusted ip", peer: "127.0.0.1", realIP: "10.1.2.3", allowed: true},
+		{name: "gateway sends not trusted ip", peer: "127.0.0.1", realIP: "192.168.1.1"},
+		{name: "trusted proxy sends trusted ip", peer: "172.16.0.1", realIP: "10.1.2.3", allowed: true},
+		{name: "trusted proxy sends not trusted ip", peer: "172.16.0.1", realIP: "192.168.1.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 50000}})
+			if tt.realIP != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-real-ip", tt.realIP))
+			}
+
+			_, err := auth.authorize(ctx, MethodGetInternalStatsV2)
+			if tt.allowed {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, codes.PermissionDenied, status.Code(err))
+		})
+	}
+}
+
+func TestAuthInterceptor_Stream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	users := mk.NewMockUserManager(ctrl)
+	users.EXPECT().Exist(gomock.Any(), userID).Return(true, nil).AnyTimes()
+
+	svc := mk.NewMockURLShortener(ctrl)
+	svc.EXPECT().GetUserURLList(gomock.Any(), userID, gomock.Any()).Return(model.URLPage{URLs: []model.ShortURL{url}}, nil)
+
+	ctx := context.Background()
+	urlServer := NewURLServerV2(svc, users, baseURL)
+	auth := NewAuthInterceptor(testDecoder{}, users, testSubnet{})
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer(func(s *grpc.Server) { pbv2.RegisterURLsServer(s, urlServer) }, grpc.StreamInterceptor(auth.Stream()))))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pbv2.NewURLsClient(conn)
+
+	t.Run("no auth metadata", func(t *testing.T) {
+		stream, err := client.ExportStream(ctx, &pbv2.ExportStreamRequest{})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("user from token", func(t *testing.T) {
+		md := metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", "Bearer "+testToken))
+		stream, err := client.ExportStream(md, &pbv2.ExportStreamRequest{})
+		require.NoError(t, err)
+
+		item, err := stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, url.URL, item.SrcUrl)
+	})
+}
+
+func TestAuthInterceptor_PublicServices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	auth := NewAuthInterceptor(testDecoder{}, mk.NewMockUserManager(ctrl), testSubnet{})
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer(func(s *grpc.Server) { healthpb.RegisterHealthServer(s, health.NewServer()) },
+			grpc.UnaryInterceptor(auth.Unary()))))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -1,11 +1,79 @@
 package grpc
 
-import "errors"
+import (
+	"context"
+	"errors"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 var (
 	ErrorURLNotFounded  = errors.New("url not founded")
 	ErrorURLIsDeleted   = errors.New("url is deleted")
+	ErrorURLIsExpired   = errors.New("url is expired")
 	ErrorURLIsExist     = errors.New("url is exist")
+	ErrorAliasIsTaken   = errors.New("alias is taken")
 	ErrorWrongUserID    = errors.New("wrong user id")
 	ErrorURLListIsEmpty = errors.New("url list is empty")
+	ErrorWrongPageToken = errors.New("wrong page token")
+	ErrorUserIDMismatch = errors.New("user id does not match authenticated user")
 )
+
+//  errInternal is message of internal error status, error text is logged and not returned to client.
+const errInternal = "внутренняя ошибка сервера"
+
+//  statusError converts error to gRPC status error.
+//  Unknown errors are logged with request logger and returned as internal error without details,
+//  storage errors of buffer flush are logged and returned as unavailable error without details.
+func statusError(ctx context.Context, err error) error {
+	var conflictErr *shterrors.ErrorConflictSaveURL
+
+	switch {
+	case errors.As(err, &conflictErr):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, ErrorURLNotFounded), errors.Is(err, shterrors.ErrNotFound), errors.Is(err, shterrors.ErrAccessDenied),
+		errors.Is(err, shterrors.ErrDeleteJobNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrorURLIsDeleted), errors.Is(err, ErrorURLIsExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, ErrorUserIDMismatch):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrorWrongUserID),
+		errors.Is(err, ErrorURLListIsEmpty),
+		errors.Is(err, ErrorWrongPageToken),
+		errors.Is(err, shterrors.ErrAliasNotValid),
+		errors.Is(err, shterrors.ErrExpirationNotValid),
+		errors.Is(err, shterrors.ErrDeleteListTooLarge):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, shterrors.ErrBufferFlush):
+		logging.FromContext(ctx).ErrorContext(ctx, "ошибка записи буфера ссылок gRPC вызова", "error", err.Error())
+		return status.Error(codes.Unavailable, shterrors.ErrBufferFlush.Error())
+	case errors.Is(err, shterrors.ErrDeleteQueueFull):
+		return status.Error(codes.Unavailable, shterrors.ErrDeleteQueueFull.Error())
+	default:
+		logging.FromContext(ctx).ErrorContext(ctx, "внутренняя ошибка gRPC вызова", "error", err.Error())
+		return status.Error(codes.Internal, errInternal)
+	}
+}
+
+//  conflictStatusError returns AlreadyExists status with existing short url in SaveConflict detail.
+func conflictStatusError(err *shterrors.ErrorConflictSaveURL, shortURL string) error {
+	msg := ErrorURLIsExist.Error()
+	if err.Code == shterrors.ConflictCodeAlias {
+		msg = ErrorAliasIsTaken.Error()
+	}
+
+	st, dErr := status.New(codes.AlreadyExists, msg).WithDetails(&pbv2.SaveConflict{
+		ShortUrl: shortURL,
+		Reason:   err.Code,
+	})
+	if dErr != nil {
+		return status.Error(codes.AlreadyExists, msg)
+	}
+
+	return st.Err()
+}
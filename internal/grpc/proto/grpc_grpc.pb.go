@@ -27,6 +27,8 @@ type URLsClient interface {
 	Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveResponse, error)
 	SaveList(ctx context.Context, in *SaveListRequest, opts ...grpc.CallOption) (*SaveListResponse, error)
 	DelList(ctx context.Context, in *DelListRequest, opts ...grpc.CallOption) (*DelListResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	GetInternalStats(ctx context.Context, in *GetInternalStatsRequest, opts ...grpc.CallOption) (*GetInternalStatsResponse, error)
 }
 
 type uRLsClient struct {
@@ -82,6 +84,24 @@ func (c *uRLsClient) DelList(ctx context.Context, in *DelListRequest, opts ...gr
 	return out, nil
 }
 
+func (c *uRLsClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.URLs/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) GetInternalStats(ctx context.Context, in *GetInternalStatsRequest, opts ...grpc.CallOption) (*GetInternalStatsResponse, error) {
+	out := new(GetInternalStatsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.URLs/GetInternalStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // URLsServer is the server API for URLs service.
 // All implementations must embed UnimplementedURLsServer
 // for forward compatibility
@@ -91,6 +111,8 @@ type URLsServer interface {
 	Save(context.Context, *SaveRequest) (*SaveResponse, error)
 	SaveList(context.Context, *SaveListRequest) (*SaveListResponse, error)
 	DelList(context.Context, *DelListRequest) (*DelListResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	GetInternalStats(context.Context, *GetInternalStatsRequest) (*GetInternalStatsResponse, error)
 	mustEmbedUnimplementedURLsServer()
 }
 
@@ -113,6 +135,12 @@ func (UnimplementedURLsServer) SaveList(context.Context, *SaveListRequest) (*Sav
 func (UnimplementedURLsServer) DelList(context.Context, *DelListRequest) (*DelListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DelList not implemented")
 }
+func (UnimplementedURLsServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedURLsServer) GetInternalStats(context.Context, *GetInternalStatsRequest) (*GetInternalStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInternalStats not implemented")
+}
 func (UnimplementedURLsServer) mustEmbedUnimplementedURLsServer() {}
 
 // UnsafeURLsServer may be embedded to opt out of forward compatibility for this service.
@@ -216,6 +244,42 @@ func _URLs_DelList_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLs_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.URLs/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_GetInternalStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInternalStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).GetInternalStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.URLs/GetInternalStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).GetInternalStats(ctx, req.(*GetInternalStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // URLs_ServiceDesc is the grpc.ServiceDesc for URLs service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -243,6 +307,14 @@ var URLs_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DelList",
 			Handler:    _URLs_DelList_Handler,
 		},
+		{
+			MethodName: "GetStats",
+			Handler:    _URLs_GetStats_Handler,
+		},
+		{
+			MethodName: "GetInternalStats",
+			Handler:    _URLs_GetInternalStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/grpc.proto",
@@ -0,0 +1,461 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.20.1
+// source: proto/v2/grpc.proto
+
+package v2
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// URLsClient is the client API for URLs service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type URLsClient interface {
+	Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error)
+	GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*GetListResponse, error)
+	Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveResponse, error)
+	SaveList(ctx context.Context, in *SaveListRequest, opts ...grpc.CallOption) (*SaveListResponse, error)
+	// saves urls as they arrive, over HTTP body is newline delimited json
+	SaveStream(ctx context.Context, opts ...grpc.CallOption) (URLs_SaveStreamClient, error)
+	// streams all user urls page by page, over HTTP response is newline delimited json
+	ExportStream(ctx context.Context, in *ExportStreamRequest, opts ...grpc.CallOption) (URLs_ExportStreamClient, error)
+	DelList(ctx context.Context, in *DelListRequest, opts ...grpc.CallOption) (*DelListResponse, error)
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// allowed only from trusted subnet
+	GetInternalStats(ctx context.Context, in *GetInternalStatsRequest, opts ...grpc.CallOption) (*GetInternalStatsResponse, error)
+}
+
+type uRLsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewURLsClient(cc grpc.ClientConnInterface) URLsClient {
+	return &uRLsClient{cc}
+}
+
+func (c *uRLsClient) Get(ctx context.Context, in *GetRequest, opts ...grpc.CallOption) (*GetResponse, error) {
+	out := new(GetResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) GetList(ctx context.Context, in *GetListRequest, opts ...grpc.CallOption) (*GetListResponse, error) {
+	out := new(GetListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/GetList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) Save(ctx context.Context, in *SaveRequest, opts ...grpc.CallOption) (*SaveResponse, error) {
+	out := new(SaveResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/Save", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) SaveList(ctx context.Context, in *SaveListRequest, opts ...grpc.CallOption) (*SaveListResponse, error) {
+	out := new(SaveListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/SaveList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) SaveStream(ctx context.Context, opts ...grpc.CallOption) (URLs_SaveStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &URLs_ServiceDesc.Streams[0], "/grpc.v2.URLs/SaveStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &uRLsSaveStreamClient{stream}
+	return x, nil
+}
+
+type URLs_SaveStreamClient interface {
+	Send(*SaveStreamRequest) error
+	CloseAndRecv() (*SaveStreamResponse, error)
+	grpc.ClientStream
+}
+
+type uRLsSaveStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *uRLsSaveStreamClient) Send(m *SaveStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *uRLsSaveStreamClient) CloseAndRecv() (*SaveStreamResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SaveStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *uRLsClient) ExportStream(ctx context.Context, in *ExportStreamRequest, opts ...grpc.CallOption) (URLs_ExportStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &URLs_ServiceDesc.Streams[1], "/grpc.v2.URLs/ExportStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &uRLsExportStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type URLs_ExportStreamClient interface {
+	Recv() (*ExportStreamItem, error)
+	grpc.ClientStream
+}
+
+type uRLsExportStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *uRLsExportStreamClient) Recv() (*ExportStreamItem, error) {
+	m := new(ExportStreamItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *uRLsClient) DelList(ctx context.Context, in *DelListRequest, opts ...grpc.CallOption) (*DelListResponse, error) {
+	out := new(DelListResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/DelList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/GetStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLsClient) GetInternalStats(ctx context.Context, in *GetInternalStatsRequest, opts ...grpc.CallOption) (*GetInternalStatsResponse, error) {
+	out := new(GetInternalStatsResponse)
+	err := c.cc.Invoke(ctx, "/grpc.v2.URLs/GetInternalStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// URLsServer is the server API for URLs service.
+// All implementations must embed UnimplementedURLsServer
+// for forward compatibility
+type URLsServer interface {
+	Get(context.Context, *GetRequest) (*GetResponse, error)
+	GetList(context.Context, *GetListRequest) (*GetListResponse, error)
+	Save(context.Context, *SaveRequest) (*SaveResponse, error)
+	SaveList(context.Context, *SaveListRequest) (*SaveListResponse, error)
+	// saves urls as they arrive, over HTTP body is newline delimited json
+	SaveStream(URLs_SaveStreamServer) error
+	// streams all user urls page by page, over HTTP response is newline delimited json
+	ExportStream(*ExportStreamRequest, URLs_ExportStreamServer) error
+	DelList(context.Context, *DelListRequest) (*DelListResponse, error)
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// allowed only from trusted subnet
+	GetInternalStats(context.Context, *GetInternalStatsRequest) (*GetInternalStatsResponse, error)
+	mustEmbedUnimplementedURLsServer()
+}
+
+// UnimplementedURLsServer must be embedded to have forward compatible implementations.
+type UnimplementedURLsServer struct {
+}
+
+func (UnimplementedURLsServer) Get(context.Context, *GetRequest) (*GetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (UnimplementedURLsServer) GetList(context.Context, *GetListRequest) (*GetListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
+}
+func (UnimplementedURLsServer) Save(context.Context, *SaveRequest) (*SaveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Save not implemented")
+}
+func (UnimplementedURLsServer) SaveList(context.Context, *SaveListRequest) (*SaveListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveList not implemented")
+}
+func (UnimplementedURLsServer) SaveStream(URLs_SaveStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SaveStream not implemented")
+}
+func (UnimplementedURLsServer) ExportStream(*ExportStreamRequest, URLs_ExportStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportStream not implemented")
+}
+func (UnimplementedURLsServer) DelList(context.Context, *DelListRequest) (*DelListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DelList not implemented")
+}
+func (UnimplementedURLsServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedURLsServer) GetInternalStats(context.Context, *GetInternalStatsRequest) (*GetInternalStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInternalStats not implemented")
+}
+func (UnimplementedURLsServer) mustEmbedUnimplementedURLsServer() {}
+
+// UnsafeURLsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to URLsServer will
+// result in compilation errors.
+type UnsafeURLsServer interface {
+	mustEmbedUnimplementedURLsServer()
+}
+
+func RegisterURLsServer(s grpc.ServiceRegistrar, srv URLsServer) {
+	s.RegisterService(&URLs_ServiceDesc, srv)
+}
+
+func _URLs_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).Get(ctx, req.(*GetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).GetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/GetList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).GetList(ctx, req.(*GetListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_Save_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).Save(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/Save",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).Save(ctx, req.(*SaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_SaveList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).SaveList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/SaveList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).SaveList(ctx, req.(*SaveListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_SaveStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(URLsServer).SaveStream(&uRLsSaveStreamServer{stream})
+}
+
+type URLs_SaveStreamServer interface {
+	SendAndClose(*SaveStreamResponse) error
+	Recv() (*SaveStreamRequest, error)
+	grpc.ServerStream
+}
+
+type uRLsSaveStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *uRLsSaveStreamServer) SendAndClose(m *SaveStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *uRLsSaveStreamServer) Recv() (*SaveStreamRequest, error) {
+	m := new(SaveStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _URLs_ExportStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(URLsServer).ExportStream(m, &uRLsExportStreamServer{stream})
+}
+
+type URLs_ExportStreamServer interface {
+	Send(*ExportStreamItem) error
+	grpc.ServerStream
+}
+
+type uRLsExportStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *uRLsExportStreamServer) Send(m *ExportStreamItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _URLs_DelList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DelListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).DelList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/DelList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).DelList(ctx, req.(*DelListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/GetStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLs_GetInternalStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInternalStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLsServer).GetInternalStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpc.v2.URLs/GetInternalStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLsServer).GetInternalStats(ctx, req.(*GetInternalStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// URLs_ServiceDesc is the grpc.ServiceDesc for URLs service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var URLs_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpc.v2.URLs",
+	HandlerType: (*URLsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _URLs_Get_Handler,
+		},
+		{
+			MethodName: "GetList",
+			Handler:    _URLs_GetList_Handler,
+		},
+		{
+			MethodName: "Save",
+			Handler:    _URLs_Save_Handler,
+		},
+		{
+			MethodName: "SaveList",
+			Handler:    _URLs_SaveList_Handler,
+		},
+		{
+			MethodName: "DelList",
+			Handler:    _URLs_DelList_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _URLs_GetStats_Handler,
+		},
+		{
+			MethodName: "GetInternalStats",
+			Handler:    _URLs_GetInternalStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SaveStream",
+			Handler:       _URLs_SaveStream_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportStream",
+			Handler:       _URLs_ExportStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/v2/grpc.proto",
+}
@@ -3,6 +3,7 @@ package grpc
 import (
 	"context"
 	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
 	"github.com/atrush/pract_01.git/internal/model"
 	mk "github.com/atrush/pract_01.git/internal/service/mock"
 	"github.com/golang/mock/gomock"
@@ -12,6 +13,7 @@ import (
 	"google.golang.org/grpc/test/bufconn"
 	"log"
 	"net"
+	"time"
 )
 
 var (
@@ -27,6 +29,13 @@ var (
 		IsDeleted: false,
 		ID:        uuid.New(),
 	}
+	urlExpired = model.ShortURL{
+		ShortID:   "3xQ6p+JI",
+		URL:       "https://yandex.ru/13",
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+		ID:        uuid.New(),
+	}
 	urlDeleted = model.ShortURL{
 		ShortID:   "2xQ6p+JI",
 		URL:       "https://yandex.ru/12",
@@ -34,6 +43,11 @@ var (
 		IsDeleted: true,
 		ID:        uuid.New(),
 	}
+
+	pageCursor = model.URLCursor{
+		CreatedAt: time.Unix(0, time.Now().UnixNano()),
+		ID:        uuid.New(),
+	}
 )
 
 func initTestGRPCConn(ctx context.Context) (*URLsServer, *grpc.ClientConn, error) {
@@ -41,17 +55,27 @@ func initTestGRPCConn(ctx context.Context) (*URLsServer, *grpc.ClientConn, error
 
 	conn, err := grpc.DialContext(ctx, "",
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(dialer(urlServer)))
+		grpc.WithContextDialer(dialer(func(s *grpc.Server) { pb.RegisterURLsServer(s, urlServer) })))
+
+	return urlServer, conn, err
+}
+
+func initTestGRPCConnV2(ctx context.Context) (*URLsServerV2, *grpc.ClientConn, error) {
+	urlServer := &URLsServerV2{baseURL: baseURL}
+
+	conn, err := grpc.DialContext(ctx, "",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialer(func(s *grpc.Server) { pbv2.RegisterURLsServer(s, urlServer) })))
 
 	return urlServer, conn, err
 }
 
-func dialer(s *URLsServer) func(context.Context, string) (net.Conn, error) {
+func dialer(register func(s *grpc.Server), opts ...grpc.ServerOption) func(context.Context, string) (net.Conn, error) {
 	listener := bufconn.Listen(1024 * 1024)
 
-	server := grpc.NewServer()
+	server := grpc.NewServer(opts...)
 
-	pb.RegisterURLsServer(server, s)
+	register(server)
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	mk "github.com/atrush/pract_01.git/internal/service/mock"
 	"github.com/golang/mock/gomock"
@@ -69,11 +70,11 @@ func TestURLsServer_DeleteList(t *testing.T) {
 //  service.URLShortener mocks
 func mockDeleteListOk(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().DeleteURLList(userID, []string{url.ShortID, urlDeleted.ShortID}).Return(nil)
+	mock.EXPECT().DeleteURLList(gomock.Any(), userID, []string{url.ShortID, urlDeleted.ShortID}).Return(model.DeleteJob{}, nil)
 	return mock
 }
 func mockDeleteListServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().DeleteURLList(userID, []string{url.ShortID, urlDeleted.ShortID}).Return(errors.New(serverErrMessage))
+	mock.EXPECT().DeleteURLList(gomock.Any(), userID, []string{url.ShortID, urlDeleted.ShortID}).Return(model.DeleteJob{}, errors.New(serverErrMessage))
 	return mock
 }
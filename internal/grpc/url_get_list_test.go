@@ -33,6 +33,23 @@ func TestURLsServer_GetList(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "next page",
+			svc:     mockGetListNextPage(ctrl),
+			request: &pb.GetListRequest{UserId: userID.String(), PageSize: 1, PageToken: pageCursor.Encode(), UrlContains: "ya"},
+			reqResponse: &pb.GetListResponse{
+				List: []*pb.GetListItem{
+					{SrcUrl: url.URL, ShortUrl: baseURL + "/" + url.ShortID},
+				},
+				NextPageToken: model.NewURLCursor(url).Encode(),
+			},
+		},
+		{
+			name:        "wrong page token",
+			svc:         mockGetListURLNoRun(ctrl),
+			request:     &pb.GetListRequest{UserId: userID.String(), PageToken: "wrong token"},
+			reqResponse: &pb.GetListResponse{Error: ErrorWrongPageToken.Error()},
+		},
 		{
 			name:        "wrong user id",
 			svc:         mockGetListURLNoRun(ctrl),
@@ -69,6 +86,7 @@ func TestURLsServer_GetList(t *testing.T) {
 			require.NoError(t, err)
 
 			require.Equal(t, resp.Error, tt.reqResponse.Error)
+			require.Equal(t, tt.reqResponse.NextPageToken, resp.NextPageToken)
 
 			for _, rs := range tt.reqResponse.List {
 				isFounded := false
@@ -87,12 +105,19 @@ func TestURLsServer_GetList(t *testing.T) {
 //  service.URLShortener mocks
 func mockGetListEmpty(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().GetUserURLList(gomock.Any(), userID).Return(nil, nil)
+	mock.EXPECT().GetUserURLList(gomock.Any(), userID, model.URLListFilter{}).Return(model.URLPage{}, nil)
 	return mock
 }
 func mockGetListExistURL(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().GetUserURLList(gomock.Any(), userID).Return([]model.ShortURL{url, urlDeleted}, nil)
+	mock.EXPECT().GetUserURLList(gomock.Any(), userID, model.URLListFilter{}).Return(model.URLPage{URLs: []model.ShortURL{url, urlDeleted}}, nil)
+	return mock
+}
+func mockGetListNextPage(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	filter := model.URLListFilter{Limit: 1, After: &pageCursor, URLContains: "ya"}
+	mock.EXPECT().GetUserURLList(gomock.Any(), userID, filter).Return(
+		model.URLPage{URLs: []model.ShortURL{url}, NextCursor: model.NewURLCursor(url).Encode()}, nil)
 	return mock
 }
 func mockGetListURLNoRun(ctrl *gomock.Controller) *mk.MockURLShortener {
@@ -101,6 +126,6 @@ func mockGetListURLNoRun(ctrl *gomock.Controller) *mk.MockURLShortener {
 }
 func mockGetListURLServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().GetUserURLList(gomock.Any(), userID).Return(nil, errors.New(serverErrMessage))
+	mock.EXPECT().GetUserURLList(gomock.Any(), userID, gomock.Any()).Return(model.URLPage{}, errors.New(serverErrMessage))
 	return mock
 }
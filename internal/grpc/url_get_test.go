@@ -7,6 +7,7 @@ import (
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	mk "github.com/atrush/pract_01.git/internal/service/mock"
+	"github.com/atrush/pract_01.git/internal/shterrors"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -40,6 +41,12 @@ func TestURLsServer_Get(t *testing.T) {
 			request:     &pb.GetRequest{ShortId: urlDeleted.ShortID},
 			reqResponse: &pb.GetResponse{Error: ErrorURLIsDeleted.Error()},
 		},
+		{
+			name:        "is expired",
+			svc:         mockGetExpiredURL(ctrl),
+			request:     &pb.GetRequest{ShortId: urlExpired.ShortID},
+			reqResponse: &pb.GetResponse{Error: ErrorURLIsExpired.Error()},
+		},
 		{
 			name:        "server error",
 			svc:         mockGetServerError(ctrl),
@@ -82,7 +89,7 @@ func mockGetDeletedURL(ctrl *gomock.Controller) *mk.MockURLShortener {
 }
 func mockGetNotExistURL(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().GetURL(gomock.Any(), gomock.Any()).Return(model.ShortURL{}, nil)
+	mock.EXPECT().GetURL(gomock.Any(), gomock.Any()).Return(model.ShortURL{}, shterrors.ErrNotFound)
 	return mock
 }
 func mockGetServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
@@ -90,3 +97,8 @@ func mockGetServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock.EXPECT().GetURL(gomock.Any(), gomock.Any()).Return(model.ShortURL{}, errors.New(serverErrMessage))
 	return mock
 }
+func mockGetExpiredURL(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	mock.EXPECT().GetURL(gomock.Any(), urlExpired.ShortID).Return(urlExpired, nil)
+	return mock
+}
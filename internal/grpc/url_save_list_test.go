@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	mk "github.com/atrush/pract_01.git/internal/service/mock"
 	"github.com/golang/mock/gomock"
@@ -92,12 +93,12 @@ func TestURLsServer_SaveList(t *testing.T) {
 //  service.URLShortener mocks
 func mockSaveListOk(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().SaveURLList(map[string]string{"01": url.URL, "02": urlDeleted.URL}, userID).Return(
+	mock.EXPECT().SaveURLList(gomock.Any(), map[string]model.ShortURL{"01": {URL: url.URL}, "02": {URL: urlDeleted.URL}}, userID).Return(
 		map[string]string{"01": url.ShortID, "02": urlDeleted.ShortID}, nil)
 	return mock
 }
 func mockSaveListServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().SaveURLList(gomock.Any(), userID).Return(nil, errors.New(serverErrMessage))
+	mock.EXPECT().SaveURLList(gomock.Any(), gomock.Any(), userID).Return(nil, errors.New(serverErrMessage))
 	return mock
 }
@@ -42,6 +42,12 @@ func TestURLsServer_Save(t *testing.T) {
 			reqResponse: &pb.SaveResponse{ShortUrl: baseURL + "/" + url.ShortID, Error: ErrorURLIsExist.Error()},
 		},
 
+		{
+			name:        "save alias taken",
+			svc:         mockSaveAliasTaken(ctrl),
+			request:     &pb.SaveRequest{UserId: url.UserID.String(), SrcUrl: url.URL, Alias: url.ShortID},
+			reqResponse: &pb.SaveResponse{ShortUrl: baseURL + "/" + url.ShortID, Error: ErrorAliasIsTaken.Error()},
+		},
 		{
 			name:        "server error",
 			svc:         mockSaveServerError(ctrl),
@@ -74,17 +80,23 @@ func TestURLsServer_Save(t *testing.T) {
 //  service.URLShortener mocks
 func mockSaveOk(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID).Return(url.ShortID, nil)
+	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID, gomock.Any()).Return(url.ShortID, nil)
 	return mock
 }
 func mockSaveServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
-	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID).Return("", errors.New(serverErrMessage))
+	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID, gomock.Any()).Return("", errors.New(serverErrMessage))
 	return mock
 }
 func mockSaveExist(ctrl *gomock.Controller) *mk.MockURLShortener {
 	mock := mk.NewMockURLShortener(ctrl)
 	var errExist error = &shterrors.ErrorConflictSaveURL{ExistShortURL: url.ShortID}
-	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID).Return("", errExist)
+	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID, gomock.Any()).Return("", errExist)
+	return mock
+}
+func mockSaveAliasTaken(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	var errExist error = &shterrors.ErrorConflictSaveURL{ExistShortURL: url.ShortID, Code: shterrors.ConflictCodeAlias}
+	mock.EXPECT().SaveURL(gomock.Any(), url.URL, userID, gomock.Any()).Return("", errExist)
 	return mock
 }
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	pb "github.com/atrush/pract_01.git/internal/grpc/proto"
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/service"
+	mk "github.com/atrush/pract_01.git/internal/service/mock"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestURLsServer_GetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name        string
+		svc         service.URLShortener
+		request     *pb.GetStatsRequest
+		reqResponse *pb.GetStatsResponse
+	}{
+		{
+			name:    "exist",
+			svc:     mockGetStatsOk(ctrl),
+			request: &pb.GetStatsRequest{UserId: userID.String(), ShortId: url.ShortID},
+			reqResponse: &pb.GetStatsResponse{
+				Clicks:    3,
+				UniqueIps: 2,
+				Referrers: []*pb.ReferrerStats{{Referrer: "https://ya.ru/", Clicks: 3}},
+			},
+		},
+		{
+			name:        "wrong user id",
+			svc:         mockNoRun(ctrl),
+			request:     &pb.GetStatsRequest{UserId: "wrong user id", ShortId: url.ShortID},
+			reqResponse: &pb.GetStatsResponse{Error: ErrorWrongUserID.Error()},
+		},
+		{
+			name:        "not found",
+			svc:         mockGetStatsAccessDenied(ctrl),
+			request:     &pb.GetStatsRequest{UserId: userID.String(), ShortId: url.ShortID},
+			reqResponse: &pb.GetStatsResponse{Error: ErrorURLNotFounded.Error()},
+		},
+		{
+			name:        "server error",
+			svc:         mockGetStatsServerError(ctrl),
+			request:     &pb.GetStatsRequest{UserId: userID.String(), ShortId: url.ShortID},
+			reqResponse: &pb.GetStatsResponse{Error: serverErrMessage},
+		},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// set service mock
+			urlServer.svc = tt.svc
+
+			client := pb.NewURLsClient(conn)
+			resp, err := client.GetStats(ctx, tt.request)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.reqResponse.Clicks, resp.Clicks)
+			require.Equal(t, tt.reqResponse.UniqueIps, resp.UniqueIps)
+			require.Equal(t, len(tt.reqResponse.Referrers), len(resp.Referrers))
+			for i, v := range tt.reqResponse.Referrers {
+				require.Equal(t, v.Referrer, resp.Referrers[i].Referrer)
+				require.Equal(t, v.Clicks, resp.Referrers[i].Clicks)
+			}
+			require.Equal(t, tt.reqResponse.Error, resp.Error)
+		})
+	}
+}
+
+//  service.URLShortener mocks
+func mockGetStatsOk(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	mock.EXPECT().GetClickStats(gomock.Any(), userID, url.ShortID).Return(model.ClickStats{
+		ShortID:   url.ShortID,
+		Clicks:    3,
+		UniqueIPs: 2,
+		Referrers: map[string]int{"https://ya.ru/": 3},
+	}, nil)
+	return mock
+}
+func mockGetStatsAccessDenied(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	mock.EXPECT().GetClickStats(gomock.Any(), userID, url.ShortID).Return(model.ClickStats{}, shterrors.ErrAccessDenied)
+	return mock
+}
+func mockGetStatsServerError(ctrl *gomock.Controller) *mk.MockURLShortener {
+	mock := mk.NewMockURLShortener(ctrl)
+	mock.EXPECT().GetClickStats(gomock.Any(), userID, url.ShortID).Return(model.ClickStats{}, errors.New(serverErrMessage))
+	return mock
+}
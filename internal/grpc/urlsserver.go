@@ -7,18 +7,21 @@ import (
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/service"
 	"github.com/atrush/pract_01.git/internal/shterrors"
-	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
 )
 
 type URLsServer struct {
 	pb.UnimplementedURLsServer
 	svc     service.URLShortener
+	users   service.UserManager
 	baseURL string
 }
 
-func NewURLServer(svc service.URLShortener, baseURL string) *URLsServer {
+func NewURLServer(svc service.URLShortener, users service.UserManager, baseURL string) *URLsServer {
 	return &URLsServer{
 		svc:     svc,
+		users:   users,
 		baseURL: baseURL,
 	}
 }
@@ -27,18 +30,22 @@ func (u *URLsServer) Get(ctx context.Context, request *pb.GetRequest) (*pb.GetRe
 	var response pb.GetResponse
 
 	url, err := u.svc.GetURL(ctx, request.ShortId)
+	if errors.Is(err, shterrors.ErrNotFound) {
+		response.Error = ErrorURLNotFounded.Error()
+		return &response, nil
+	}
 	if err != nil {
 		response.Error = err.Error()
 		return &response, nil
 	}
 
-	if url == (model.ShortURL{}) {
-		response.Error = ErrorURLNotFounded.Error()
+	if url.IsDeleted {
+		response.Error = ErrorURLIsDeleted.Error()
 		return &response, nil
 	}
 
-	if url.IsDeleted {
-		response.Error = ErrorURLIsDeleted.Error()
+	if url.Expired(time.Now()) {
+		response.Error = ErrorURLIsExpired.Error()
 		return &response, nil
 	}
 
@@ -48,20 +55,41 @@ func (u *URLsServer) Get(ctx context.Context, request *pb.GetRequest) (*pb.GetRe
 
 func (u *URLsServer) GetList(ctx context.Context, request *pb.GetListRequest) (*pb.GetListResponse, error) {
 	var response pb.GetListResponse
-	userID, err := uuid.Parse(request.UserId)
+	userID, err := requestUserID(ctx, request.UserId)
 	if err != nil {
-		response.Error = ErrorWrongUserID.Error()
+		response.Error = err.Error()
 		return &response, nil
 	}
 
-	urlList, err := u.svc.GetUserURLList(ctx, userID)
+	filter := model.URLListFilter{
+		Limit:          int(request.PageSize),
+		IncludeDeleted: request.IncludeDeleted,
+		URLContains:    request.UrlContains,
+	}
+	if request.PageToken != "" {
+		cursor, err := model.DecodeURLCursor(request.PageToken)
+		if err != nil {
+			response.Error = ErrorWrongPageToken.Error()
+			return &response, nil
+		}
+		filter.After = &cursor
+	}
+	if request.CreatedAfter != nil {
+		filter.CreatedAfter = request.CreatedAfter.AsTime()
+	}
+	if request.CreatedBefore != nil {
+		filter.CreatedBefore = request.CreatedBefore.AsTime()
+	}
+
+	page, err := u.svc.GetUserURLList(ctx, userID, filter)
 	if err != nil {
 		response.Error = err.Error()
 		return &response, nil
 	}
 
-	response.List = make([]*pb.GetListItem, len(urlList))
-	for i, v := range urlList {
+	response.NextPageToken = page.NextCursor
+	response.List = make([]*pb.GetListItem, len(page.URLs))
+	for i, v := range page.URLs {
 		response.List[i] = &pb.GetListItem{
 			ShortUrl: u.baseURL + "/" + v.ShortID,
 			SrcUrl:   v.URL,
@@ -74,19 +102,33 @@ func (u *URLsServer) GetList(ctx context.Context, request *pb.GetListRequest) (*
 func (u *URLsServer) Save(ctx context.Context, request *pb.SaveRequest) (*pb.SaveResponse, error) {
 	var response pb.SaveResponse
 
-	userID, err := uuid.Parse(request.UserId)
+	userID, err := requestUserID(ctx, request.UserId)
 	if err != nil {
-		response.Error = ErrorWrongUserID.Error()
+		response.Error = err.Error()
 		return &response, nil
 	}
 
-	shortID, err := u.svc.SaveURL(ctx, request.SrcUrl, userID)
+	expiresAt := time.Time{}
+	if request.ExpiresAt != nil {
+		expiresAt = request.ExpiresAt.AsTime()
+	}
+
+	expiresAt, err = service.ExpirationTime(expiresAt, time.Duration(request.Ttl)*time.Second)
 	if err != nil {
-		// if url exist, return url with error
-		if errors.Is(err, &shterrors.ErrorConflictSaveURL{}) {
-			conflictErr, _ := err.(*shterrors.ErrorConflictSaveURL)
+		response.Error = err.Error()
+		return &response, nil
+	}
+
+	shortID, err := u.svc.SaveURL(ctx, request.SrcUrl, userID, model.WithAlias(request.Alias), model.WithExpiresAt(expiresAt))
+	if err != nil {
+		// if url or alias exist, return url with error
+		var conflictErr *shterrors.ErrorConflictSaveURL
+		if errors.As(err, &conflictErr) {
 			response.ShortUrl = u.baseURL + "/" + conflictErr.ExistShortURL
 			response.Error = ErrorURLIsExist.Error()
+			if conflictErr.Code == shterrors.ConflictCodeAlias {
+				response.Error = ErrorAliasIsTaken.Error()
+			}
 
 			return &response, nil
 		}
@@ -104,9 +146,9 @@ func (u *URLsServer) Save(ctx context.Context, request *pb.SaveRequest) (*pb.Sav
 func (u *URLsServer) SaveList(ctx context.Context, request *pb.SaveListRequest) (*pb.SaveListResponse, error) {
 	var response pb.SaveListResponse
 
-	userID, err := uuid.Parse(request.UserId)
+	userID, err := requestUserID(ctx, request.UserId)
 	if err != nil {
-		response.Error = ErrorWrongUserID.Error()
+		response.Error = err.Error()
 		return &response, nil
 	}
 
@@ -116,12 +158,12 @@ func (u *URLsServer) SaveList(ctx context.Context, request *pb.SaveListRequest)
 	}
 
 	//  make map id[url] to add
-	listToAdd := make(map[string]string, len(request.List))
+	listToAdd := make(map[string]model.ShortURL, len(request.List))
 	for _, el := range request.List {
-		listToAdd[el.CorrelationId] = el.Url
+		listToAdd[el.CorrelationId] = model.ShortURL{URL: el.Url}
 	}
 
-	savedList, err := u.svc.SaveURLList(listToAdd, userID)
+	savedList, err := u.svc.SaveURLList(ctx, listToAdd, userID)
 	if err != nil {
 		response.Error = err.Error()
 		return &response, nil
@@ -141,9 +183,9 @@ func (u *URLsServer) SaveList(ctx context.Context, request *pb.SaveListRequest)
 
 func (u *URLsServer) DelList(ctx context.Context, request *pb.DelListRequest) (*pb.DelListResponse, error) {
 	var response pb.DelListResponse
-	userID, err := uuid.Parse(request.UserId)
+	userID, err := requestUserID(ctx, request.UserId)
 	if err != nil {
-		response.Error = ErrorWrongUserID.Error()
+		response.Error = err.Error()
 		return &response, nil
 	}
 
@@ -152,10 +194,68 @@ func (u *URLsServer) DelList(ctx context.Context, request *pb.DelListRequest) (*
 		return &response, nil
 	}
 
-	if err := u.svc.DeleteURLList(userID, request.List...); err != nil {
+	if _, err := u.svc.DeleteURLList(ctx, userID, request.List...); err != nil {
 		response.Error = err.Error()
 		return &response, nil
 	}
 
 	return &pb.DelListResponse{}, nil
 }
+
+func (u *URLsServer) GetStats(ctx context.Context, request *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	var response pb.GetStatsResponse
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		response.Error = err.Error()
+		return &response, nil
+	}
+
+	stats, err := u.svc.GetClickStats(ctx, userID, request.ShortId)
+	if err != nil {
+		if errors.Is(err, shterrors.ErrAccessDenied) {
+			response.Error = ErrorURLNotFounded.Error()
+			return &response, nil
+		}
+
+		response.Error = err.Error()
+		return &response, nil
+	}
+
+	response.Clicks = int64(stats.Clicks)
+	response.UniqueIps = int64(stats.UniqueIPs)
+	if !stats.LastClickAt.IsZero() {
+		response.LastClickAt = timestamppb.New(stats.LastClickAt)
+	}
+
+	response.Referrers = make([]*pb.ReferrerStats, 0, len(stats.Referrers))
+	for k, v := range stats.Referrers {
+		response.Referrers = append(response.Referrers, &pb.ReferrerStats{
+			Referrer: k,
+			Clicks:   int64(v),
+		})
+	}
+
+	return &response, nil
+}
+
+//  GetInternalStats returns count of stored users and not deleted urls.
+//  Allowed only from trusted subnet by AuthInterceptor.
+func (u *URLsServer) GetInternalStats(ctx context.Context, request *pb.GetInternalStatsRequest) (*pb.GetInternalStatsResponse, error) {
+	var response pb.GetInternalStatsResponse
+
+	urls, err := u.svc.GetCount(ctx)
+	if err != nil {
+		response.Error = err.Error()
+		return &response, nil
+	}
+
+	users, err := u.users.GetCount()
+	if err != nil {
+		response.Error = err.Error()
+		return &response, nil
+	}
+
+	response.Urls = int64(urls)
+	response.Users = int64(users)
+	return &response, nil
+}
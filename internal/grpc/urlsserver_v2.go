@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//  URLsServerV2 implements v2 URLs service, returns errors as gRPC status codes.
+type URLsServerV2 struct {
+	pbv2.UnimplementedURLsServer
+	svc     service.URLShortener
+	users   service.UserManager
+	baseURL string
+}
+
+func NewURLServerV2(svc service.URLShortener, users service.UserManager, baseURL string) *URLsServerV2 {
+	return &URLsServerV2{
+		svc:     svc,
+		users:   users,
+		baseURL: baseURL,
+	}
+}
+
+func (u *URLsServerV2) Get(ctx context.Context, request *pbv2.GetRequest) (*pbv2.GetResponse, error) {
+	url, err := u.svc.GetURL(ctx, request.ShortId)
+	if errors.Is(err, shterrors.ErrNotFound) {
+		return nil, statusError(ctx, ErrorURLNotFounded)
+	}
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	if url.IsDeleted {
+		return nil, statusError(ctx, ErrorURLIsDeleted)
+	}
+
+	if url.Expired(time.Now()) {
+		return nil, statusError(ctx, ErrorURLIsExpired)
+	}
+
+	return &pbv2.GetResponse{SrcUrl: url.URL}, nil
+}
+
+func (u *URLsServerV2) GetList(ctx context.Context, request *pbv2.GetListRequest) (*pbv2.GetListResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	filter := model.URLListFilter{
+		Limit:          int(request.PageSize),
+		IncludeDeleted: request.IncludeDeleted,
+		URLContains:    request.UrlContains,
+	}
+	if request.PageToken != "" {
+		cursor, err := model.DecodeURLCursor(request.PageToken)
+		if err != nil {
+			return nil, statusError(ctx, ErrorWrongPageToken)
+		}
+		filter.After = &cursor
+	}
+	if request.CreatedAfter != nil {
+		filter.CreatedAfter = request.CreatedAfter.AsTime()
+	}
+	if request.CreatedBefore != nil {
+		filter.CreatedBefore = request.CreatedBefore.AsTime()
+	}
+
+	page, err := u.svc.GetUserURLList(ctx, userID, filter)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	response := pbv2.GetListResponse{
+		NextPageToken: page.NextCursor,
+		List:          make([]*pbv2.GetListItem, len(page.URLs)),
+	}
+	for i, v := range page.URLs {
+		response.List[i] = &pbv2.GetListItem{
+			ShortUrl: u.baseURL + "/" + v.ShortID,
+			SrcUrl:   v.URL,
+		}
+	}
+
+	return &response, nil
+}
+
+func (u *URLsServerV2) Save(ctx context.Context, request *pbv2.SaveRequest) (*pbv2.SaveResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	expiresAt := time.Time{}
+	if request.ExpiresAt != nil {
+		expiresAt = request.ExpiresAt.AsTime()
+	}
+
+	expiresAt, err = service.ExpirationTime(expiresAt, time.Duration(request.Ttl)*time.Second)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	shortID, err := u.svc.SaveURL(ctx, request.SrcUrl, userID, model.WithAlias(request.Alias), model.WithExpiresAt(expiresAt))
+	if err != nil {
+		// if url or alias exist, return existing url in error detail
+		var conflictErr *shterrors.ErrorConflictSaveURL
+		if errors.As(err, &conflictErr) {
+			return nil, conflictStatusError(conflictErr, u.baseURL+"/"+conflictErr.ExistShortURL)
+		}
+
+		return nil, statusError(ctx, err)
+	}
+
+	return &pbv2.SaveResponse{ShortUrl: u.baseURL + "/" + shortID}, nil
+}
+
+func (u *URLsServerV2) SaveList(ctx context.Context, request *pbv2.SaveListRequest) (*pbv2.SaveListResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	if len(request.List) == 0 {
+		return nil, statusError(ctx, ErrorURLListIsEmpty)
+	}
+
+	//  make map id[url] to add
+	listToAdd := make(map[string]model.ShortURL, len(request.List))
+	for _, el := range request.List {
+		listToAdd[el.CorrelationId] = model.ShortURL{URL: el.Url}
+	}
+
+	savedList, err := u.svc.SaveURLList(ctx, listToAdd, userID)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	response := pbv2.SaveListResponse{List: make([]*pbv2.SaveListItem, 0, len(savedList))}
+	for k, v := range savedList {
+		response.List = append(response.List, &pbv2.SaveListItem{
+			CorrelationId: k,
+			Url:           u.baseURL + "/" + v,
+		})
+	}
+
+	return &response, nil
+}
+
+func (u *URLsServerV2) DelList(ctx context.Context, request *pbv2.DelListRequest) (*pbv2.DelListResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	if len(request.List) == 0 {
+		return nil, statusError(ctx, ErrorURLListIsEmpty)
+	}
+
+	if _, err := u.svc.DeleteURLList(ctx, userID, request.List...); err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return &pbv2.DelListResponse{}, nil
+}
+
+func (u *URLsServerV2) GetStats(ctx context.Context, request *pbv2.GetStatsRequest) (*pbv2.GetStatsResponse, error) {
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	stats, err := u.svc.GetClickStats(ctx, userID, request.ShortId)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	response := pbv2.GetStatsResponse{
+		Clicks:    int64(stats.Clicks),
+		UniqueIps: int64(stats.UniqueIPs),
+		Referrers: make([]*pbv2.ReferrerStats, 0, len(stats.Referrers)),
+	}
+	if !stats.LastClickAt.IsZero() {
+		response.LastClickAt = timestamppb.New(stats.LastClickAt)
+	}
+
+	for k, v := range stats.Referrers {
+		response.Referrers = append(response.Referrers, &pbv2.ReferrerStats{
+			Referrer: k,
+			Clicks:   int64(v),
+		})
+	}
+
+	return &response, nil
+}
+
+//  GetInternalStats returns count of stored users and not deleted urls.
+//  Allowed only from trusted subnet by AuthInterceptor.
+func (u *URLsServerV2) GetInternalStats(ctx context.Context, request *pbv2.GetInternalStatsRequest) (*pbv2.GetInternalStatsResponse, error) {
+	urls, err := u.svc.GetCount(ctx)
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	users, err := u.users.GetCount()
+	if err != nil {
+		return nil, statusError(ctx, err)
+	}
+
+	return &pbv2.GetInternalStatsResponse{
+		Urls:  int64(urls),
+		Users: int64(users),
+	}, nil
+}
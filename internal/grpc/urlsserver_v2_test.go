@@ -0,0 +1,201 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestURLsServerV2_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name    string
+		svc     service.URLShortener
+		shortID string
+		srcURL  string
+		code    codes.Code
+	}{
+		{name: "exist", svc: mockGetExistURL(ctrl), shortID: url.ShortID, srcURL: url.URL, code: codes.OK},
+		{name: "deleted", svc: mockGetDeletedURL(ctrl), shortID: urlDeleted.ShortID, code: codes.FailedPrecondition},
+		{name: "expired", svc: mockGetExpiredURL(ctrl), shortID: urlExpired.ShortID, code: codes.FailedPrecondition},
+		{name: "not exist", svc: mockGetNotExistURL(ctrl), shortID: url.ShortID, code: codes.NotFound},
+		{name: "server error", svc: mockGetServerError(ctrl), shortID: url.ShortID, code: codes.Internal},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServer.svc = tt.svc
+
+			client := pbv2.NewURLsClient(conn)
+			resp, err := client.Get(ctx, &pbv2.GetRequest{ShortId: tt.shortID})
+			require.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.Equal(t, tt.srcURL, resp.SrcUrl)
+			}
+			if tt.code == codes.Internal {
+				require.NotContains(t, status.Convert(err).Message(), serverErrMessage, "текст внутренней ошибки не передается клиенту")
+			}
+		})
+	}
+}
+
+func TestURLsServerV2_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name     string
+		svc      service.URLShortener
+		userID   string
+		code     codes.Code
+		shortURL string
+		reason   string
+	}{
+		{name: "saved", svc: mockSaveOk(ctrl), userID: userID.String(), code: codes.OK, shortURL: baseURL + "/" + url.ShortID},
+		{name: "wrong user id", svc: mockNoRun(ctrl), userID: "wrong user id", code: codes.InvalidArgument},
+		{name: "url exist", svc: mockSaveExist(ctrl), userID: userID.String(), code: codes.AlreadyExists, shortURL: baseURL + "/" + url.ShortID},
+		{name: "alias taken", svc: mockSaveAliasTaken(ctrl), userID: userID.String(), code: codes.AlreadyExists, shortURL: baseURL + "/" + url.ShortID, reason: shterrors.ConflictCodeAlias},
+		{name: "server error", svc: mockSaveServerError(ctrl), userID: userID.String(), code: codes.Internal},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServer.svc = tt.svc
+
+			client := pbv2.NewURLsClient(conn)
+			resp, err := client.Save(ctx, &pbv2.SaveRequest{SrcUrl: url.URL, UserId: tt.userID})
+			require.Equal(t, tt.code, status.Code(err))
+
+			switch tt.code {
+			case codes.OK:
+				require.Equal(t, tt.shortURL, resp.ShortUrl)
+			case codes.AlreadyExists:
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				conflict, ok := details[0].(*pbv2.SaveConflict)
+				require.True(t, ok)
+				require.Equal(t, tt.shortURL, conflict.ShortUrl)
+				require.Equal(t, tt.reason, conflict.Reason)
+			}
+		})
+	}
+}
+
+func TestURLsServerV2_GetList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name    string
+		svc     service.URLShortener
+		request *pbv2.GetListRequest
+		code    codes.Code
+		len     int
+	}{
+		{name: "exist", svc: mockGetListExistURL(ctrl), request: &pbv2.GetListRequest{UserId: userID.String()}, code: codes.OK, len: 2},
+		{name: "wrong user id", svc: mockNoRun(ctrl), request: &pbv2.GetListRequest{UserId: "wrong"}, code: codes.InvalidArgument},
+		{name: "wrong page token", svc: mockNoRun(ctrl), request: &pbv2.GetListRequest{UserId: userID.String(), PageToken: "!"}, code: codes.InvalidArgument},
+		{name: "server error", svc: mockGetListURLServerError(ctrl), request: &pbv2.GetListRequest{UserId: userID.String()}, code: codes.Internal},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServer.svc = tt.svc
+
+			client := pbv2.NewURLsClient(conn)
+			resp, err := client.GetList(ctx, tt.request)
+			require.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.Len(t, resp.List, tt.len)
+			}
+		})
+	}
+}
+
+func TestURLsServerV2_DelList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name    string
+		svc     service.URLShortener
+		request *pbv2.DelListRequest
+		code    codes.Code
+	}{
+		{name: "deleted", svc: mockDeleteListOk(ctrl), request: &pbv2.DelListRequest{UserId: userID.String(), List: []string{url.ShortID, urlDeleted.ShortID}}, code: codes.OK},
+		{name: "empty list", svc: mockNoRun(ctrl), request: &pbv2.DelListRequest{UserId: userID.String()}, code: codes.InvalidArgument},
+		{name: "server error", svc: mockDeleteListServerError(ctrl), request: &pbv2.DelListRequest{UserId: userID.String(), List: []string{url.ShortID, urlDeleted.ShortID}}, code: codes.Internal},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServer.svc = tt.svc
+
+			client := pbv2.NewURLsClient(conn)
+			_, err := client.DelList(ctx, tt.request)
+			require.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
+
+func TestURLsServerV2_GetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	tests := []struct {
+		name string
+		svc  service.URLShortener
+		code codes.Code
+	}{
+		{name: "exist", svc: mockGetStatsOk(ctrl), code: codes.OK},
+		{name: "not found", svc: mockGetStatsAccessDenied(ctrl), code: codes.NotFound},
+		{name: "server error", svc: mockGetStatsServerError(ctrl), code: codes.Internal},
+	}
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServer.svc = tt.svc
+
+			client := pbv2.NewURLsClient(conn)
+			resp, err := client.GetStats(ctx, &pbv2.GetStatsRequest{UserId: userID.String(), ShortId: url.ShortID})
+			require.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				require.Equal(t, int64(3), resp.Clicks)
+			}
+		})
+	}
+}
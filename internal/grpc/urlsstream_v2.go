@@ -0,0 +1,171 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/service"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+//  streamFlushSize is count of saved stream items, after which buffer is flushed.
+const streamFlushSize = 100
+
+//  SaveStream saves urls as they arrive using own url buffer, returns per-item results after stream closed.
+//  Items with errors are not saved, other items are saved.
+func (u *URLsServerV2) SaveStream(stream pbv2.URLs_SaveStreamServer) error {
+	ctx := stream.Context()
+
+	var (
+		buf     service.URLBuffer
+		results []*pbv2.SaveStreamResult
+		//  pending contains results of items buffered after last flush by shortID
+		pending = make(map[string]*pbv2.SaveStreamResult)
+	)
+
+	//  flush writes buffer, marks failed only items not saved
+	flush := func() {
+		for shortID, err := range buf.Flush() {
+			if res, ok := pending[shortID]; ok {
+				setStreamItemError(ctx, res, err, u.baseURL)
+			}
+		}
+		pending = make(map[string]*pbv2.SaveStreamResult)
+	}
+
+	for {
+		item, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		//  user is read from first message
+		if buf == nil {
+			userID, err := requestUserID(ctx, item.UserId)
+			if err != nil {
+				return statusError(ctx, err)
+			}
+			buf = u.svc.NewURLBuffer(ctx, userID)
+		}
+
+		res := &pbv2.SaveStreamResult{CorrelationId: item.CorrelationId}
+		results = append(results, res)
+
+		expiresAt := time.Time{}
+		if item.ExpiresAt != nil {
+			expiresAt = item.ExpiresAt.AsTime()
+		}
+		expiresAt, err = service.ExpirationTime(expiresAt, time.Duration(item.Ttl)*time.Second)
+		if err != nil {
+			setStreamResultError(ctx, res, err)
+			continue
+		}
+
+		shortID, err := buf.Add(model.ShortURL{URL: item.Url, ShortID: item.Alias, ExpiresAt: expiresAt})
+		if err != nil {
+			setStreamItemError(ctx, res, err, u.baseURL)
+			continue
+		}
+
+		res.ShortUrl = u.baseURL + "/" + shortID
+		pending[shortID] = res
+
+		if len(pending) >= streamFlushSize {
+			flush()
+		}
+	}
+
+	if buf != nil {
+		flush()
+	}
+
+	return stream.SendAndClose(&pbv2.SaveStreamResponse{Results: results})
+}
+
+//  ExportStream streams all user urls matching filter, reads storage page by page.
+func (u *URLsServerV2) ExportStream(request *pbv2.ExportStreamRequest, stream pbv2.URLs_ExportStreamServer) error {
+	ctx := stream.Context()
+
+	userID, err := requestUserID(ctx, request.UserId)
+	if err != nil {
+		return statusError(ctx, err)
+	}
+
+	filter := model.URLListFilter{
+		Limit:          int(request.PageSize),
+		IncludeDeleted: request.IncludeDeleted,
+		URLContains:    request.UrlContains,
+	}
+	if request.CreatedAfter != nil {
+		filter.CreatedAfter = request.CreatedAfter.AsTime()
+	}
+	if request.CreatedBefore != nil {
+		filter.CreatedBefore = request.CreatedBefore.AsTime()
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		page, err := u.svc.GetUserURLList(ctx, userID, filter)
+		if err != nil {
+			return statusError(ctx, err)
+		}
+
+		for _, v := range page.URLs {
+			item := &pbv2.ExportStreamItem{
+				ShortUrl:  u.baseURL + "/" + v.ShortID,
+				SrcUrl:    v.URL,
+				CreatedAt: timestamppb.New(v.CreatedAt),
+				IsDeleted: v.IsDeleted,
+			}
+			if !v.ExpiresAt.IsZero() {
+				item.ExpiresAt = timestamppb.New(v.ExpiresAt)
+			}
+
+			if err := stream.Send(item); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == "" {
+			return nil
+		}
+
+		cursor, err := model.DecodeURLCursor(page.NextCursor)
+		if err != nil {
+			return statusError(ctx, err)
+		}
+		filter.After = &cursor
+	}
+}
+
+//  setStreamItemError sets error of failed stream item, conflict item gets existing short url.
+func setStreamItemError(ctx context.Context, res *pbv2.SaveStreamResult, err error, baseURL string) {
+	var conflictErr *shterrors.ErrorConflictSaveURL
+	if errors.As(err, &conflictErr) {
+		res.ShortUrl = baseURL + "/" + conflictErr.ExistShortURL
+	}
+	setStreamResultError(ctx, res, err)
+}
+
+//  setStreamResultError sets status code and message of failed stream item.
+func setStreamResultError(ctx context.Context, res *pbv2.SaveStreamResult, err error) {
+	st := status.Convert(statusError(ctx, err))
+	res.Code = int32(st.Code())
+	res.Message = st.Message()
+	if st.Code() != codes.AlreadyExists {
+		res.ShortUrl = ""
+	}
+}
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	pbv2 "github.com/atrush/pract_01.git/internal/grpc/proto/v2"
+	"github.com/atrush/pract_01.git/internal/model"
+	mk "github.com/atrush/pract_01.git/internal/service/mock"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestURLsServerV2_SaveStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pbv2.NewURLsClient(conn)
+
+	t.Run("saved with item errors", func(t *testing.T) {
+		buf := mk.NewMockURLBuffer(ctrl)
+		buf.EXPECT().Add(model.ShortURL{URL: url.URL}).Return(url.ShortID, nil)
+		buf.EXPECT().Add(model.ShortURL{URL: urlDeleted.URL, ShortID: "taken"}).
+			Return("", &shterrors.ErrorConflictSaveURL{ExistShortURL: "taken", Code: shterrors.ConflictCodeAlias})
+		buf.EXPECT().Flush().Return(nil)
+
+		svc := mk.NewMockURLShortener(ctrl)
+		svc.EXPECT().NewURLBuffer(gomock.Any(), userID).Return(buf)
+		urlServer.svc = svc
+
+		stream, err := client.SaveStream(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pbv2.SaveStreamRequest{UserId: userID.String(), CorrelationId: "1", Url: url.URL}))
+		require.NoError(t, stream.Send(&pbv2.SaveStreamRequest{CorrelationId: "2", Url: urlDeleted.URL, Alias: "taken"}))
+		require.NoError(t, stream.Send(&pbv2.SaveStreamRequest{CorrelationId: "3", Url: url.URL, Ttl: -1}))
+
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 3)
+
+		require.Equal(t, "1", resp.Results[0].CorrelationId)
+		require.Equal(t, int32(codes.OK), resp.Results[0].Code)
+		require.Equal(t, baseURL+"/"+url.ShortID, resp.Results[0].ShortUrl)
+
+		require.Equal(t, int32(codes.AlreadyExists), resp.Results[1].Code)
+		require.Equal(t, baseURL+"/taken", resp.Results[1].ShortUrl)
+
+		require.Equal(t, int32(codes.InvalidArgument), resp.Results[2].Code)
+		require.Empty(t, resp.Results[2].ShortUrl)
+	})
+
+	t.Run("flush fails only not saved items", func(t *testing.T) {
+		buf := mk.NewMockURLBuffer(ctrl)
+		buf.EXPECT().Add(gomock.Any()).Return(url.ShortID, nil)
+		buf.EXPECT().Add(gomock.Any()).Return(urlDeleted.ShortID, nil)
+		buf.EXPECT().Add(gomock.Any()).Return(urlExpired.ShortID, nil)
+		buf.EXPECT().Flush().Return(map[string]error{
+			url.ShortID:        fmt.Errorf("%w: %v", shterrors.ErrBufferFlush, serverErrMessage),
+			urlExpired.ShortID: &shterrors.ErrorConflictSaveURL{ExistShortURL: "exist", Code: shterrors.ConflictCodeURL},
+		})
+
+		svc := mk.NewMockURLShortener(ctrl)
+		svc.EXPECT().NewURLBuffer(gomock.Any(), userID).Return(buf)
+		urlServer.svc = svc
+
+		stream, err := client.SaveStream(ctx)
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, stream.Send(&pbv2.SaveStreamRequest{UserId: userID.String(), CorrelationId: fmt.Sprint(i), Url: url.URL}))
+		}
+
+		resp, err := stream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 3)
+		require.Equal(t, int32(codes.Unavailable), resp.Results[0].Code, "буферизованная ссылка не сохранена")
+		require.Equal(t, shterrors.ErrBufferFlush.Error(), resp.Results[0].Message, "ошибка хранилища не передается клиенту")
+		require.Empty(t, resp.Results[0].ShortUrl)
+		require.Equal(t, int32(codes.OK), resp.Results[1].Code, "ошибка другой ссылки не влияет на сохранение")
+		require.Equal(t, baseURL+"/"+urlDeleted.ShortID, resp.Results[1].ShortUrl)
+		require.Equal(t, int32(codes.AlreadyExists), resp.Results[2].Code)
+		require.Equal(t, baseURL+"/exist", resp.Results[2].ShortUrl)
+	})
+
+	t.Run("wrong user id", func(t *testing.T) {
+		urlServer.svc = mockNoRun(ctrl)
+
+		stream, err := client.SaveStream(ctx)
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pbv2.SaveStreamRequest{UserId: "wrong", Url: url.URL}))
+
+		_, err = stream.CloseAndRecv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestURLsServerV2_ExportStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	urlServer, conn, err := initTestGRPCConnV2(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := pbv2.NewURLsClient(conn)
+
+	t.Run("all pages", func(t *testing.T) {
+		svc := mk.NewMockURLShortener(ctrl)
+		gomock.InOrder(
+			svc.EXPECT().GetUserURLList(gomock.Any(), userID, model.URLListFilter{Limit: 1}).
+				Return(model.URLPage{URLs: []model.ShortURL{url}, NextCursor: pageCursor.Encode()}, nil),
+			svc.EXPECT().GetUserURLList(gomock.Any(), userID, model.URLListFilter{Limit: 1, After: &pageCursor}).
+				Return(model.URLPage{URLs: []model.ShortURL{urlExpired}}, nil),
+		)
+		urlServer.svc = svc
+
+		stream, err := client.ExportStream(ctx, &pbv2.ExportStreamRequest{UserId: userID.String(), PageSize: 1})
+		require.NoError(t, err)
+
+		var items []*pbv2.ExportStreamItem
+		for {
+			item, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+			items = append(items, item)
+		}
+
+		require.Len(t, items, 2)
+		require.Equal(t, baseURL+"/"+url.ShortID, items[0].ShortUrl)
+		require.Nil(t, items[0].ExpiresAt)
+		require.Equal(t, urlExpired.URL, items[1].SrcUrl)
+		require.NotNil(t, items[1].ExpiresAt)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		svc := mk.NewMockURLShortener(ctrl)
+		svc.EXPECT().GetUserURLList(gomock.Any(), userID, gomock.Any()).Return(model.URLPage{}, errors.New(serverErrMessage))
+		urlServer.svc = svc
+
+		stream, err := client.ExportStream(ctx, &pbv2.ExportStreamRequest{UserId: userID.String()})
+		require.NoError(t, err)
+
+		_, err = stream.Recv()
+		require.Equal(t, codes.Internal, status.Code(err))
+	})
+}
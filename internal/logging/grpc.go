@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//  RequestIDMetadata is gRPC metadata key of request ID.
+const RequestIDMetadata = "x-request-id"
+
+//  UnaryServerInterceptor returns interceptor, that passes logger and request ID in call context
+//  and logs calls failed with internal error.
+//  Request ID is taken from x-request-id metadata or generated, and returned in header metadata.
+func UnaryServerInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = callContext(ctx, logger)
+
+		resp, err := handler(ctx, req)
+		logCallError(ctx, info.FullMethod, err)
+
+		return resp, err
+	}
+}
+
+//  StreamServerInterceptor returns interceptor, that passes logger and request ID in stream context
+//  and logs streams failed with internal error.
+func StreamServerInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := callContext(ss.Context(), logger)
+
+		err := handler(srv, &loggingServerStream{ServerStream: ss, ctx: ctx})
+		logCallError(ctx, info.FullMethod, err)
+
+		return err
+	}
+}
+
+//  callContext returns context of call with logger and request ID, sends request ID in header metadata.
+func callContext(ctx context.Context, logger *slog.Logger) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	ctx = context.WithValue(ctx, middleware.RequestIDKey, id)
+	//  error is ignored, request ID in response is optional
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, id))
+
+	return WithLogger(ctx, logger)
+}
+
+//  logCallError logs error of call with internal or unknown status.
+func logCallError(ctx context.Context, method string, err error) {
+	if err == nil {
+		return
+	}
+	if code := status.Code(err); code == codes.Internal || code == codes.Unknown {
+		FromContext(ctx).ErrorContext(ctx, "ошибка обработки gRPC вызова", "method", method, "error", err.Error())
+	}
+}
+
+//  loggingServerStream overrides stream context with logger and request ID.
+type loggingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+//  Context returns stream context with logger and request ID.
+func (s *loggingServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+//  RequestIDHeader is header of request ID in http request and response.
+const RequestIDHeader = "X-Request-Id"
+
+//  Middleware returns middleware, that passes logger in request context and returns request ID in response header.
+//  Must be used after chi middleware.RequestID.
+func Middleware(logger *slog.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := middleware.GetReqID(r.Context()); id != "" {
+				w.Header().Set(RequestIDHeader, id)
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithLogger(r.Context(), logger)))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+//  Package logging provides structured leveled logger of shortener.
+//  Logger is passed to components in context, every log line with context contains request ID and trace ID.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//  Log formats.
+const (
+	FormatText = "text" //  key=value lines
+	FormatJSON = "json" //  json lines
+)
+
+//  Attribute keys added from context.
+const (
+	KeyRequestID = "request_id"
+	KeyTraceID   = "trace_id"
+)
+
+//  loggerKey is context key of logger.
+type loggerKey struct{}
+
+//  New returns logger, that writes to w lines of level and format.
+//  Level is one of debug, info, warn, error, info if empty. Format is text or json, text if empty.
+func New(w io.Writer, level string, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			return nil, fmt.Errorf("неверный уровень логирования: %v", level)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var h slog.Handler
+	switch format {
+	case "", FormatText:
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("неверный формат логирования: %v", format)
+	}
+
+	return slog.New(contextHandler{Handler: h}), nil
+}
+
+//  WithLogger returns context with logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+//  FromContext returns logger from context, default logger if context has no logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+//  contextHandler adds request ID and trace ID from context to records.
+type contextHandler struct {
+	slog.Handler
+}
+
+//  Handle adds attributes from context to record and passes it to wrapped handler.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := middleware.GetReqID(ctx); id != "" {
+			r.AddAttrs(slog.String(KeyRequestID, id))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			r.AddAttrs(slog.String(KeyTraceID, sc.TraceID().String()))
+		}
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+//  WithAttrs returns context handler with attributes.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+//  WithGroup returns context handler with group.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
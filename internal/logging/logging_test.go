@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//  decodeLines decodes json log lines.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		line := make(map[string]interface{})
+		require.NoError(t, dec.Decode(&line))
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		format  string
+		wantErr bool
+		wantOut string
+	}{
+		{name: "defaults", wantOut: "level=INFO msg=info"},
+		{name: "json", level: "warn", format: FormatJSON, wantOut: `"level":"WARN","msg":"warn"`},
+		{name: "debug text", level: "debug", format: FormatText, wantOut: "level=DEBUG msg=debug"},
+		{name: "wrong level", level: "trace", wantErr: true},
+		{name: "wrong format", format: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger, err := New(buf, tt.level, tt.format)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			logger.Debug("debug")
+			logger.Info("info")
+			logger.Warn("warn")
+
+			assert.Contains(t, buf.String(), tt.wantOut)
+			if tt.level == "warn" {
+				assert.NotContains(t, buf.String(), "info")
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, "", FormatJSON)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(Middleware(logger))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).InfoContext(r.Context(), "request")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "test-request")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+
+	assert.Equal(t, "test-request", w.Header().Get(RequestIDHeader))
+
+	lines := decodeLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "request", lines[0]["msg"])
+	assert.Equal(t, "test-request", lines[0][KeyRequestID])
+
+	//  request ID is generated if not sent
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	lines = decodeLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.NotEmpty(t, w.Header().Get(RequestIDHeader))
+	assert.Equal(t, w.Header().Get(RequestIDHeader), lines[0][KeyRequestID])
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := New(buf, "", FormatJSON)
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/shortener.v2.URLs/GetURL"}
+
+	tests := []struct {
+		name      string
+		requestID string
+		handleErr error
+		wantLines int
+	}{
+		{name: "request ID from metadata", requestID: "test-request"},
+		{name: "generated request ID"},
+		{name: "not internal error", requestID: "test-request", handleErr: status.Error(codes.NotFound, "не найдено")},
+		{name: "internal error", requestID: "test-request", handleErr: errors.New("ошибка"), wantLines: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(RequestIDMetadata, tt.requestID))
+			}
+
+			var gotID string
+			_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				gotID = middleware.GetReqID(ctx)
+				assert.Equal(t, logger, FromContext(ctx))
+				return nil, tt.handleErr
+			})
+			assert.Equal(t, tt.handleErr, err)
+
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, gotID)
+			} else {
+				assert.NotEmpty(t, gotID)
+			}
+
+			lines := decodeLines(t, buf)
+			require.Len(t, lines, tt.wantLines)
+			for _, line := range lines {
+				assert.Equal(t, tt.requestID, line[KeyRequestID])
+				assert.Equal(t, info.FullMethod, line["method"])
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.NotNil(t, FromContext(context.Background()))
+
+	logger, err := New(&bytes.Buffer{}, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, logger, FromContext(WithLogger(context.Background(), logger)))
+}
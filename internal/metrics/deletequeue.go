@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ prometheus.Collector = (*deleteQueueCollector)(nil)
+
+//  deleteQueueCollector collects delete queue state from queue stats on scrape.
+type deleteQueueCollector struct {
+	stats        DeleteQueueStatsFunc
+	depth        *prometheus.Desc
+	capacity     *prometheus.Desc
+	deleted      *prometheus.Desc
+	rejected     *prometheus.Desc
+	retries      *prometheus.Desc
+	deadLettered *prometheus.Desc
+}
+
+//  newDeleteQueueCollector inits delete queue collector.
+func newDeleteQueueCollector(stats DeleteQueueStatsFunc) *deleteQueueCollector {
+	name := func(v string) string {
+		return prometheus.BuildFQName(Namespace, "delete_queue", v)
+	}
+
+	return &deleteQueueCollector{
+		stats:        stats,
+		depth:        prometheus.NewDesc(name("depth"), "Count of urls waiting for deletion in queue.", nil, nil),
+		capacity:     prometheus.NewDesc(name("capacity"), "Max count of urls in delete queue.", nil, nil),
+		deleted:      prometheus.NewDesc(name("deleted_total"), "Count of urls deleted by queue.", nil, nil),
+		rejected:     prometheus.NewDesc(name("rejected_total"), "Count of urls rejected because queue is full.", nil, nil),
+		retries:      prometheus.NewDesc(name("retries_total"), "Count of retries of failed delete batches.", nil, nil),
+		deadLettered: prometheus.NewDesc(name("dead_lettered_total"), "Count of urls not deleted after all retries.", nil, nil),
+	}
+}
+
+//  Describe sends descriptors of delete queue metrics.
+func (c *deleteQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+	ch <- c.capacity
+	ch <- c.deleted
+	ch <- c.rejected
+	ch <- c.retries
+	ch <- c.deadLettered
+}
+
+//  Collect sends current delete queue state, nothing is sent if storage has no queue.
+func (c *deleteQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, ok := c.stats()
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(stats.Depth))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(stats.Capacity))
+	ch <- prometheus.MustNewConstMetric(c.deleted, prometheus.CounterValue, float64(stats.Deleted))
+	ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(stats.Rejected))
+	ch <- prometheus.MustNewConstMetric(c.retries, prometheus.CounterValue, float64(stats.Retries))
+	ch <- prometheus.MustNewConstMetric(c.deadLettered, prometheus.CounterValue, float64(stats.DeadLettered))
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+//  UnaryServerInterceptor returns interceptor, that counts unary gRPC calls and observes latency by method.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.observeGRPC(info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+//  StreamServerInterceptor returns interceptor, that counts stream gRPC calls and observes stream duration by method.
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.observeGRPC(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+//  observeGRPC counts gRPC call with status code of error and observes latency.
+func (m *Metrics) observeGRPC(method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+
+	m.grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	m.grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,158 @@
+//  Package metrics provides prometheus metrics of shortener: http and gRPC requests, redirects,
+//  shortID generation collisions, delete queue state and storage operations latency.
+//  All methods of nil *Metrics do nothing, so metrics are optional for services and handlers.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+//  Namespace is prefix of all shortener metrics.
+const Namespace = "shortener"
+
+//  Redirect results.
+const (
+	RedirectHit  = "hit"  //  url found, redirected
+	RedirectMiss = "miss" //  url not found
+	RedirectGone = "gone" //  url deleted or expired
+)
+
+type (
+	//  DeleteQueueStatsFunc returns state of async url delete queue, false if storage deletes urls without queue.
+	DeleteQueueStatsFunc func() (model.DeleteQueueStats, bool)
+
+	//  Metrics stores collectors and registry of shortener metrics.
+	Metrics struct {
+		registry *prometheus.Registry
+
+		httpRequests      *prometheus.CounterVec
+		httpDuration      *prometheus.HistogramVec
+		grpcRequests      *prometheus.CounterVec
+		grpcDuration      *prometheus.HistogramVec
+		redirects         *prometheus.CounterVec
+		shortIDCollisions prometheus.Counter
+		storageDuration   *prometheus.HistogramVec
+	}
+)
+
+//  New inits metrics with own registry, go runtime and process metrics are registered too.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Count of http requests by method, chi route pattern and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of http requests by method and chi route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "Count of gRPC calls by full method name and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of gRPC calls by full method name.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		redirects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "redirects_total",
+			Help:      "Count of short url redirects by result: hit, miss or gone.",
+		}, []string{"result"}),
+		shortIDCollisions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "shortid_collisions_total",
+			Help:      "Count of generated shortIDs, that already exist and were generated again.",
+		}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "storage",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of storage operations by repository and operation.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "operation"}),
+	}
+
+	//  redirect results are known, so they are exported before first redirect
+	for _, result := range []string{RedirectHit, RedirectMiss, RedirectGone} {
+		m.redirects.WithLabelValues(result)
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.grpcRequests,
+		m.grpcDuration,
+		m.redirects,
+		m.shortIDCollisions,
+		m.storageDuration,
+	)
+
+	return m
+}
+
+//  Handler returns http handler of metrics in prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+//  Redirect counts redirect by short url with result RedirectHit, RedirectMiss or RedirectGone.
+func (m *Metrics) Redirect(result string) {
+	if m == nil {
+		return
+	}
+
+	m.redirects.WithLabelValues(result).Inc()
+}
+
+//  ShortIDCollision counts generated shortID, that already exists.
+func (m *Metrics) ShortIDCollision() {
+	if m == nil {
+		return
+	}
+
+	m.shortIDCollisions.Inc()
+}
+
+//  ObserveStorage observes latency of storage operation.
+func (m *Metrics) ObserveStorage(repository string, operation string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.storageDuration.WithLabelValues(repository, operation).Observe(duration.Seconds())
+}
+
+//  RegisterDeleteQueue registers delete queue state metrics, that are read from stats on every scrape.
+//  If storage deletes urls without queue, metrics are not exported.
+func (m *Metrics) RegisterDeleteQueue(stats DeleteQueueStatsFunc) error {
+	if m == nil {
+		return nil
+	}
+
+	return m.registry.Register(newDeleteQueueCollector(stats))
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+func TestMetrics_Middleware(t *testing.T) {
+	m := New()
+
+	r := chi.NewRouter()
+	r.Use(m.Middleware)
+	r.Get("/api/user/urls/{shortID}/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, url := range []string{"/api/user/urls/first/stats", "/api/user/urls/second/stats", "/ping", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, url, nil))
+	}
+
+	//  paths with different shortIDs are counted by one route pattern
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/api/user/urls/{shortID}/stats", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/ping", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, routeNotMatched, "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestMetrics_UnaryServerInterceptor(t *testing.T) {
+	m := New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/shortener.v2.URLs/GetURL"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "не найдено")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.grpcRequests.WithLabelValues(info.FullMethod, codes.OK.String())))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.grpcRequests.WithLabelValues(info.FullMethod, codes.NotFound.String())))
+}
+
+func TestMetrics_DeleteQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		stats   DeleteQueueStatsFunc
+		wantOut string
+	}{
+		{
+			name: "queue",
+			stats: func() (model.DeleteQueueStats, bool) {
+				return model.DeleteQueueStats{Depth: 3, Capacity: 10, Rejected: 2}, true
+			},
+			wantOut: `
+# HELP shortener_delete_queue_depth Count of urls waiting for deletion in queue.
+# TYPE shortener_delete_queue_depth gauge
+shortener_delete_queue_depth 3
+# HELP shortener_delete_queue_rejected_total Count of urls rejected because queue is full.
+# TYPE shortener_delete_queue_rejected_total counter
+shortener_delete_queue_rejected_total 2
+`,
+		},
+		{
+			name: "no queue",
+			stats: func() (model.DeleteQueueStats, bool) {
+				return model.DeleteQueueStats{}, false
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			require.NoError(t, m.RegisterDeleteQueue(tt.stats))
+
+			err := testutil.GatherAndCompare(m.registry, strings.NewReader(tt.wantOut),
+				"shortener_delete_queue_depth", "shortener_delete_queue_rejected_total")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+
+	assert.NotPanics(t, func() {
+		m.Redirect(RedirectHit)
+		m.ShortIDCollision()
+		m.ObserveStorage("url", "get_url", time.Millisecond)
+		require.NoError(t, m.RegisterDeleteQueue(nil))
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		m.Middleware(next).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		_, err := m.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{},
+			func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+		require.NoError(t, err)
+	})
+}
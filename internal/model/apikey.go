@@ -0,0 +1,94 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	//  APIKeyPrefix is prefix of all api keys.
+	APIKeyPrefix = "shk_"
+
+	//  apiKeySize is size of random part of api key in bytes.
+	apiKeySize = 32
+	//  apiKeyVisibleLen is length of key beginning, stored to recognize key in list.
+	apiKeyVisibleLen = 8
+	//  MaxAPIKeyNameLen is max length of api key name.
+	MaxAPIKeyNameLen = 64
+)
+
+//  APIKey represents user api key. Key itself is not stored, only its hash.
+type APIKey struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"userid"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"createdat"`
+	RevokedAt time.Time `json:"revokedat"`
+}
+
+//  NewAPIKey generates new api key for user, returns key object and raw key.
+func NewAPIKey(userID uuid.UUID, name string) (APIKey, string, error) {
+	secret := make([]byte, apiKeySize)
+	if _, err := rand.Read(secret); err != nil {
+		return APIKey{}, "", fmt.Errorf("ошибка генерации ключа API: %w", err)
+	}
+	raw := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	key := APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    raw[:len(APIKeyPrefix)+apiKeyVisibleLen],
+		Hash:      HashAPIKey(raw),
+		CreatedAt: time.Now().Truncate(time.Microsecond),
+	}
+
+	if err := key.Validate(); err != nil {
+		return APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+//  HashAPIKey returns hash of raw api key.
+//  Keys are random with high entropy, so fast hash is enough.
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+//  IsAPIKey checks that string looks like api key.
+func IsAPIKey(raw string) bool {
+	return strings.HasPrefix(raw, APIKeyPrefix)
+}
+
+//  Revoked checks that key is revoked.
+func (k APIKey) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+//  Validate validates APIKey object.
+func (k APIKey) Validate() error {
+	if k.ID == uuid.Nil {
+		return errors.New("ID не может быть nil")
+	}
+	if k.UserID == uuid.Nil {
+		return errors.New("UserID не может быть nil")
+	}
+	if k.Hash == "" {
+		return errors.New("Hash не может быть пустым")
+	}
+	if len(k.Name) > MaxAPIKeyNameLen {
+		return fmt.Errorf("длина имени ключа больше %v", MaxAPIKeyNameLen)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+//  Click represents redirect by short url.
+type Click struct {
+	ShortID   string    `json:"shortid"`
+	ClickedAt time.Time `json:"clickedat"`
+	Referrer  string    `json:"referrer"`
+	UserAgent string    `json:"useragent"`
+	IP        string    `json:"ip"`
+}
+
+//  ClickStats represents aggregated redirects stats of short url.
+type ClickStats struct {
+	ShortID     string         `json:"shortid"`
+	Clicks      int            `json:"clicks"`
+	UniqueIPs   int            `json:"uniqueips"`
+	LastClickAt time.Time      `json:"lastclickat"`
+	Referrers   map[string]int `json:"referrers"`
+}
+
+//  NewClick returns new Click at current time.
+func NewClick(shortID string, referrer string, userAgent string, ip string) Click {
+	return Click{
+		ShortID:   shortID,
+		ClickedAt: time.Now(),
+		Referrer:  referrer,
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+}
+
+//  Validate validates Click object.
+func (c Click) Validate() error {
+	if c.ShortID == "" {
+		return errors.New("ShortID не может быть пустым")
+	}
+
+	if c.ClickedAt.IsZero() {
+		return errors.New("ClickedAt не может быть пустым")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//  Statuses of delete job.
+const (
+	DeleteJobPending DeleteJobStatus = "pending" //  job is accepted and waits processing
+	DeleteJobDone    DeleteJobStatus = "done"    //  urls of job are marked as deleted
+	DeleteJobFailed  DeleteJobStatus = "failed"  //  urls of job are not deleted after all retries
+)
+
+type (
+	//  DeleteJobStatus is status of delete job.
+	DeleteJobStatus string
+
+	//  DeleteJob represents accepted request to delete list of user urls.
+	DeleteJob struct {
+		ID         uuid.UUID
+		UserID     uuid.UUID
+		ShortIDs   []string
+		Status     DeleteJobStatus
+		Error      string
+		CreatedAt  time.Time
+		FinishedAt time.Time
+	}
+
+	//  DeleteQueueStats represents state of async url delete queue.
+	DeleteQueueStats struct {
+		Depth        int   //  count of urls waiting in queue
+		Capacity     int   //  max count of urls in queue
+		Deleted      int64 //  count of urls processed by storage
+		Rejected     int64 //  count of urls rejected because queue was full
+		Retries      int64 //  count of retried batch deletes
+		DeadLettered int64 //  count of urls not deleted after all retries
+	}
+)
+
+//  NewDeleteJob returns pending job to delete list of user urls.
+func NewDeleteJob(userID uuid.UUID, shortIDList []string) DeleteJob {
+	return DeleteJob{
+		ID:        uuid.New(),
+		UserID:    userID,
+		ShortIDs:  shortIDList,
+		Status:    DeleteJobPending,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+//  Finish sets final status of job and finish time.
+func (j *DeleteJob) Finish(status DeleteJobStatus, errText string, finishedAt time.Time) {
+	j.Status = status
+	j.Error = errText
+	j.FinishedAt = finishedAt
+}
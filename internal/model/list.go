@@ -0,0 +1,112 @@
+package model
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//  User urls list limits.
+const (
+	DefURLListLimit = 100
+	MaxURLListLimit = 1000
+)
+
+//  URLListFilter represents params of user urls list selection.
+//  Urls are ordered by creation time and id, After is position of last url of previous page.
+type URLListFilter struct {
+	Limit          int
+	After          *URLCursor
+	IncludeDeleted bool
+	URLContains    string
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+}
+
+//  URLCursor represents position of url in user urls list.
+type URLCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+//  URLPage represents page of user urls list.
+//  NextCursor is empty if page is last.
+type URLPage struct {
+	URLs       []ShortURL
+	NextCursor string
+}
+
+//  NewURLCursor returns cursor pointed to url.
+func NewURLCursor(u ShortURL) URLCursor {
+	return URLCursor{
+		CreatedAt: u.CreatedAt,
+		ID:        u.ID,
+	}
+}
+
+//  Encode encodes cursor to opaque string token.
+func (c URLCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+//  DecodeURLCursor decodes cursor from string token.
+func DecodeURLCursor(token string) (URLCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return URLCursor{}, fmt.Errorf("неверный курсор: %w", err)
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return URLCursor{}, errors.New("неверный курсор")
+	}
+
+	nsec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return URLCursor{}, fmt.Errorf("неверный курсор: %w", err)
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return URLCursor{}, fmt.Errorf("неверный курсор: %w", err)
+	}
+
+	return URLCursor{
+		CreatedAt: time.Unix(0, nsec),
+		ID:        id,
+	}, nil
+}
+
+//  Less checks that cursor position is before url position in list.
+func (c URLCursor) Less(u ShortURL) bool {
+	if !c.CreatedAt.Equal(u.CreatedAt) {
+		return c.CreatedAt.Before(u.CreatedAt)
+	}
+	return c.ID.String() < u.ID.String()
+}
+
+//  Match checks that url matches filter conditions, except position and limit.
+func (f URLListFilter) Match(u ShortURL) bool {
+	if !f.IncludeDeleted && u.IsDeleted {
+		return false
+	}
+
+	if f.URLContains != "" && !strings.Contains(u.URL, f.URLContains) {
+		return false
+	}
+
+	if !f.CreatedAfter.IsZero() && !u.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+
+	if !f.CreatedBefore.IsZero() && !u.CreatedAt.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
@@ -4,31 +4,60 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 //  ShortURL represents stored url.
+//  Zero ExpiresAt means that url never expires.
 type ShortURL struct {
 	ID        uuid.UUID `json:"id"`
 	ShortID   string    `json:"shortid"`
 	URL       string    `json:"url"`
 	UserID    uuid.UUID `json:"userid"`
 	IsDeleted bool      `json:"isdeleted"`
+	ExpiresAt time.Time `json:"expiresat"`
+	IsExpired bool      `json:"isexpired"`
+	CreatedAt time.Time `json:"createdat"`
 }
 
 //  ShortURL rule for short url validation.
 type ShortURLValidator func(u ShortURL) error
 
+//  ShortURLOption sets optional params of new ShortURL.
+type ShortURLOption func(u *ShortURL)
+
+//  WithAlias sets user defined shortID.
+func WithAlias(alias string) ShortURLOption {
+	return func(u *ShortURL) {
+		u.ShortID = alias
+	}
+}
+
+//  WithExpiresAt sets url expiration time.
+func WithExpiresAt(expiresAt time.Time) ShortURLOption {
+	return func(u *ShortURL) {
+		u.ExpiresAt = expiresAt
+	}
+}
+
 //  NewShortURL returns new ShortURL object.
-//  Inits without shortID
-func NewShortURL(srcURL string, userID uuid.UUID) ShortURL {
-	return ShortURL{
+//  Inits without shortID, if alias option not given.
+func NewShortURL(srcURL string, userID uuid.UUID, opts ...ShortURLOption) ShortURL {
+	sht := ShortURL{
 		ID:        uuid.New(),
 		URL:       srcURL,
 		UserID:    userID,
 		IsDeleted: false,
+		//  truncate to storage precision, to keep list cursors equal in all storages
+		CreatedAt: time.Now().Truncate(time.Microsecond),
 	}
+
+	for _, opt := range opts {
+		opt(&sht)
+	}
+	return sht
 }
 
 //  Validate validates ShortURL object.
@@ -58,6 +87,15 @@ func (u ShortURL) Validate(opts ...ShortURLValidator) error {
 	return nil
 }
 
+//  Expired checks that url is marked as expired or expiration time has come.
+func (u ShortURL) Expired(now time.Time) bool {
+	if u.IsExpired {
+		return true
+	}
+
+	return !u.ExpiresAt.IsZero() && !u.ExpiresAt.After(now)
+}
+
 //  ShortURL represents stored user.
 type User struct {
 	ID uuid.UUID `json:"id" validate:"required"`
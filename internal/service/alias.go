@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atrush/pract_01.git/internal/shterrors"
+)
+
+//  Default alias rules.
+const (
+	DefAliasCharset   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	DefAliasMinLength = 3
+	DefAliasMaxLength = 16
+	DefAliasReserved  = "api,ping,debug,metrics"
+)
+
+//  AliasValidator checks user defined aliases by charset, length and reserved words.
+type AliasValidator struct {
+	charset  string
+	minLen   int
+	maxLen   int
+	reserved map[string]struct{}
+}
+
+//  NewAliasValidator inits alias validator.
+//  reserved - comma separated list of words, that can't be used as alias.
+//  Empty charset and zero length params are replaced with defaults.
+func NewAliasValidator(charset string, minLen int, maxLen int, reserved string) *AliasValidator {
+	if charset == "" {
+		charset = DefAliasCharset
+	}
+	if minLen <= 0 {
+		minLen = DefAliasMinLength
+	}
+	if maxLen <= 0 {
+		maxLen = DefAliasMaxLength
+	}
+
+	v := &AliasValidator{
+		charset:  charset,
+		minLen:   minLen,
+		maxLen:   maxLen,
+		reserved: make(map[string]struct{}),
+	}
+
+	for _, w := range strings.Split(reserved, ",") {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			v.reserved[w] = struct{}{}
+		}
+	}
+
+	return v
+}
+
+//  NewDefaultAliasValidator inits alias validator with default rules.
+func NewDefaultAliasValidator() *AliasValidator {
+	return NewAliasValidator(DefAliasCharset, DefAliasMinLength, DefAliasMaxLength, DefAliasReserved)
+}
+
+//  Validate checks alias, returns wrapped shterrors.ErrAliasNotValid if alias not valid.
+func (v *AliasValidator) Validate(alias string) error {
+	if len(alias) < v.minLen || len(alias) > v.maxLen {
+		return fmt.Errorf("%w: длина должна быть от %v до %v символов", shterrors.ErrAliasNotValid, v.minLen, v.maxLen)
+	}
+
+	for _, c := range alias {
+		if !strings.ContainsRune(v.charset, c) {
+			return fmt.Errorf("%w: недопустимый символ %q", shterrors.ErrAliasNotValid, c)
+		}
+	}
+
+	if _, ok := v.reserved[strings.ToLower(alias)]; ok {
+		return fmt.Errorf("%w: %v зарезервировано", shterrors.ErrAliasNotValid, alias)
+	}
+
+	return nil
+}
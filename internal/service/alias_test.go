@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/atrush/pract_01.git/internal/shterrors"
+)
+
+func TestAliasValidator_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		alias   string
+		wantErr bool
+	}{
+		{
+			name:  "valid alias",
+			alias: "spring-sale",
+		},
+		{
+			name:    "too short",
+			alias:   "ab",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			alias:   "spring-sale-spring-sale",
+			wantErr: true,
+		},
+		{
+			name:    "not allowed symbol",
+			alias:   "spring+sale",
+			wantErr: true,
+		},
+		{
+			name:    "reserved word",
+			alias:   "api",
+			wantErr: true,
+		},
+		{
+			name:    "reserved word other case",
+			alias:   "Debug",
+			wantErr: true,
+		},
+	}
+
+	v := NewDefaultAliasValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(tt.alias)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, shterrors.ErrAliasNotValid), "Validate(%v) ожидалась ошибка ErrAliasNotValid, получена %v", tt.alias, err)
+		})
+	}
+}
@@ -11,23 +11,50 @@ type URLShortener interface {
 	//  GetURL returns canonical ShortURL by shortID.
 	GetURL(ctx context.Context, shortID string) (model.ShortURL, error)
 
-	//  GetUserURLList returns array of canonical ShortURL by userID. Or empty array if not founded.
-	GetUserURLList(ctx context.Context, userID uuid.UUID) ([]model.ShortURL, error)
+	//  GetUserURLList returns page of canonical ShortURL by userID and filter. Or empty page if not founded.
+	GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) (model.URLPage, error)
 
 	//  SaveURL saves incoming URL and return shortID.
-	SaveURL(ctx context.Context, srcURL string, userID uuid.UUID) (string, error)
+	//  Options sets optional params of saved url, such as alias and expiration time.
+	SaveURL(ctx context.Context, srcURL string, userID uuid.UUID, opts ...model.ShortURLOption) (string, error)
 
-	//  SaveURLList saves list of urls for user.
-	SaveURLList(srcArr map[string]string, userID uuid.UUID) (map[string]string, error)
+	//  SaveURLList saves list of urls for user, items contain url, optional alias in ShortID and optional ExpiresAt.
+	SaveURLList(ctx context.Context, srcArr map[string]model.ShortURL, userID uuid.UUID) (map[string]string, error)
 
-	//  DeleteURLList marks list of short urls as deleted.
-	DeleteURLList(userID uuid.UUID, shortIDList ...string) error
+	//  NewURLBuffer returns buffer for saving stream of user urls, storage is called with ctx.
+	NewURLBuffer(ctx context.Context, userID uuid.UUID) URLBuffer
+
+	//  DeleteURLList saves job, that async marks list of short urls as deleted, returns accepted job.
+	DeleteURLList(ctx context.Context, userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error)
+
+	//  GetDeleteJob returns delete job of user by id.
+	GetDeleteJob(ctx context.Context, userID uuid.UUID, jobID uuid.UUID) (model.DeleteJob, error)
 
 	//  Ping checks db connection.
 	Ping(ctx context.Context) error
 
 	//  GetCount returns count of stored, not deleted urls.
-	GetCount() (int, error)
+	GetCount(ctx context.Context) (int, error)
+
+	//  GetDeleteQueueStats returns state of async url delete queue, false if storage deletes urls without queue.
+	GetDeleteQueueStats() (model.DeleteQueueStats, bool)
+
+	//  AddClick records redirect by short url without blocking.
+	AddClick(ctx context.Context, click model.Click) error
+
+	//  GetClickStats returns redirect stats of user url by shortID.
+	GetClickStats(ctx context.Context, userID uuid.UUID, shortID string) (model.ClickStats, error)
+}
+
+//  URLBuffer is the interface that wraps methods for saving stream of urls in batches.
+type URLBuffer interface {
+	//  Add checks url, generates shortID if alias is empty, adds url to buffer and returns shortID.
+	//  Returns shterrors.ErrorConflictSaveURL if url is already stored or buffered.
+	Add(src model.ShortURL) (string, error)
+
+	//  Flush writes buffered urls to storage, returns errors of not saved urls by shortID, nil if all urls saved.
+	//  Errors of storage writing are wrapped shterrors.ErrBufferFlush.
+	Flush() map[string]error
 }
 
 // UserManager is the interface that wraps methods for process users.
@@ -40,4 +67,16 @@ type UserManager interface {
 
 	//  GetCount returns count of stored users.
 	GetCount() (int, error)
+
+	//  CreateAPIKey issues new api key for user, returns key object and raw key, raw key is not stored.
+	CreateAPIKey(ctx context.Context, userID uuid.UUID, name string) (model.APIKey, string, error)
+
+	//  GetAPIKeys returns all api keys of user.
+	GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error)
+
+	//  RevokeAPIKey revokes user api key, returns shterrors.ErrAPIKeyNotFound if user key not exist.
+	RevokeAPIKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error
+
+	//  AuthAPIKey returns id of api key owner, returns shterrors.ErrAPIKeyNotFound if key not exist or revoked.
+	AuthAPIKey(ctx context.Context, rawKey string) (uuid.UUID, error)
 }
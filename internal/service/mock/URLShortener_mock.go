@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	model "github.com/atrush/pract_01.git/internal/model"
+	service "github.com/atrush/pract_01.git/internal/service"
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 )
@@ -36,38 +37,98 @@ func (m *MockURLShortener) EXPECT() *MockURLShortenerMockRecorder {
 	return m.recorder
 }
 
+// AddClick mocks base method.
+func (m *MockURLShortener) AddClick(ctx context.Context, click model.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClick", ctx, click)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddClick indicates an expected call of AddClick.
+func (mr *MockURLShortenerMockRecorder) AddClick(ctx, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClick", reflect.TypeOf((*MockURLShortener)(nil).AddClick), ctx, click)
+}
+
 // DeleteURLList mocks base method.
-func (m *MockURLShortener) DeleteURLList(userID uuid.UUID, shortIDList ...string) error {
+func (m *MockURLShortener) DeleteURLList(ctx context.Context, userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{userID}
+	varargs := []interface{}{ctx, userID}
 	for _, a := range shortIDList {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "DeleteURLList", varargs...)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(model.DeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteURLList indicates an expected call of DeleteURLList.
-func (mr *MockURLShortenerMockRecorder) DeleteURLList(userID interface{}, shortIDList ...interface{}) *gomock.Call {
+func (mr *MockURLShortenerMockRecorder) DeleteURLList(ctx, userID interface{}, shortIDList ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{userID}, shortIDList...)
+	varargs := append([]interface{}{ctx, userID}, shortIDList...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteURLList", reflect.TypeOf((*MockURLShortener)(nil).DeleteURLList), varargs...)
 }
 
+// GetClickStats mocks base method.
+func (m *MockURLShortener) GetClickStats(ctx context.Context, userID uuid.UUID, shortID string) (model.ClickStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClickStats", ctx, userID, shortID)
+	ret0, _ := ret[0].(model.ClickStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClickStats indicates an expected call of GetClickStats.
+func (mr *MockURLShortenerMockRecorder) GetClickStats(ctx, userID, shortID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickStats", reflect.TypeOf((*MockURLShortener)(nil).GetClickStats), ctx, userID, shortID)
+}
+
 // GetCount mocks base method.
-func (m *MockURLShortener) GetCount() (int, error) {
+func (m *MockURLShortener) GetCount(ctx context.Context) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCount")
+	ret := m.ctrl.Call(m, "GetCount", ctx)
 	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCount indicates an expected call of GetCount.
-func (mr *MockURLShortenerMockRecorder) GetCount() *gomock.Call {
+func (mr *MockURLShortenerMockRecorder) GetCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockURLShortener)(nil).GetCount), ctx)
+}
+
+// GetDeleteJob mocks base method.
+func (m *MockURLShortener) GetDeleteJob(ctx context.Context, userID, jobID uuid.UUID) (model.DeleteJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleteJob", ctx, userID, jobID)
+	ret0, _ := ret[0].(model.DeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeleteJob indicates an expected call of GetDeleteJob.
+func (mr *MockURLShortenerMockRecorder) GetDeleteJob(ctx, userID, jobID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockURLShortener)(nil).GetCount))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleteJob", reflect.TypeOf((*MockURLShortener)(nil).GetDeleteJob), ctx, userID, jobID)
+}
+
+// GetDeleteQueueStats mocks base method.
+func (m *MockURLShortener) GetDeleteQueueStats() (model.DeleteQueueStats, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeleteQueueStats")
+	ret0, _ := ret[0].(model.DeleteQueueStats)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetDeleteQueueStats indicates an expected call of GetDeleteQueueStats.
+func (mr *MockURLShortenerMockRecorder) GetDeleteQueueStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeleteQueueStats", reflect.TypeOf((*MockURLShortener)(nil).GetDeleteQueueStats))
 }
 
 // GetURL mocks base method.
@@ -86,18 +147,32 @@ func (mr *MockURLShortenerMockRecorder) GetURL(ctx, shortID interface{}) *gomock
 }
 
 // GetUserURLList mocks base method.
-func (m *MockURLShortener) GetUserURLList(ctx context.Context, userID uuid.UUID) ([]model.ShortURL, error) {
+func (m *MockURLShortener) GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) (model.URLPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserURLList", ctx, userID)
-	ret0, _ := ret[0].([]model.ShortURL)
+	ret := m.ctrl.Call(m, "GetUserURLList", ctx, userID, filter)
+	ret0, _ := ret[0].(model.URLPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserURLList indicates an expected call of GetUserURLList.
-func (mr *MockURLShortenerMockRecorder) GetUserURLList(ctx, userID interface{}) *gomock.Call {
+func (mr *MockURLShortenerMockRecorder) GetUserURLList(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLList", reflect.TypeOf((*MockURLShortener)(nil).GetUserURLList), ctx, userID, filter)
+}
+
+// NewURLBuffer mocks base method.
+func (m *MockURLShortener) NewURLBuffer(ctx context.Context, userID uuid.UUID) service.URLBuffer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewURLBuffer", ctx, userID)
+	ret0, _ := ret[0].(service.URLBuffer)
+	return ret0
+}
+
+// NewURLBuffer indicates an expected call of NewURLBuffer.
+func (mr *MockURLShortenerMockRecorder) NewURLBuffer(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLList", reflect.TypeOf((*MockURLShortener)(nil).GetUserURLList), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewURLBuffer", reflect.TypeOf((*MockURLShortener)(nil).NewURLBuffer), ctx, userID)
 }
 
 // Ping mocks base method.
@@ -115,33 +190,90 @@ func (mr *MockURLShortenerMockRecorder) Ping(ctx interface{}) *gomock.Call {
 }
 
 // SaveURL mocks base method.
-func (m *MockURLShortener) SaveURL(ctx context.Context, srcURL string, userID uuid.UUID) (string, error) {
+func (m *MockURLShortener) SaveURL(ctx context.Context, srcURL string, userID uuid.UUID, opts ...model.ShortURLOption) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveURL", ctx, srcURL, userID)
+	varargs := []interface{}{ctx, srcURL, userID}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveURL", varargs...)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveURL indicates an expected call of SaveURL.
-func (mr *MockURLShortenerMockRecorder) SaveURL(ctx, srcURL, userID interface{}) *gomock.Call {
+func (mr *MockURLShortenerMockRecorder) SaveURL(ctx, srcURL, userID interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveURL", reflect.TypeOf((*MockURLShortener)(nil).SaveURL), ctx, srcURL, userID)
+	varargs := append([]interface{}{ctx, srcURL, userID}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveURL", reflect.TypeOf((*MockURLShortener)(nil).SaveURL), varargs...)
 }
 
 // SaveURLList mocks base method.
-func (m *MockURLShortener) SaveURLList(srcArr map[string]string, userID uuid.UUID) (map[string]string, error) {
+func (m *MockURLShortener) SaveURLList(ctx context.Context, srcArr map[string]model.ShortURL, userID uuid.UUID) (map[string]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveURLList", srcArr, userID)
+	ret := m.ctrl.Call(m, "SaveURLList", ctx, srcArr, userID)
 	ret0, _ := ret[0].(map[string]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SaveURLList indicates an expected call of SaveURLList.
-func (mr *MockURLShortenerMockRecorder) SaveURLList(srcArr, userID interface{}) *gomock.Call {
+func (mr *MockURLShortenerMockRecorder) SaveURLList(ctx, srcArr, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveURLList", reflect.TypeOf((*MockURLShortener)(nil).SaveURLList), srcArr, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveURLList", reflect.TypeOf((*MockURLShortener)(nil).SaveURLList), ctx, srcArr, userID)
+}
+
+// MockURLBuffer is a mock of URLBuffer interface.
+type MockURLBuffer struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLBufferMockRecorder
+}
+
+// MockURLBufferMockRecorder is the mock recorder for MockURLBuffer.
+type MockURLBufferMockRecorder struct {
+	mock *MockURLBuffer
+}
+
+// NewMockURLBuffer creates a new mock instance.
+func NewMockURLBuffer(ctrl *gomock.Controller) *MockURLBuffer {
+	mock := &MockURLBuffer{ctrl: ctrl}
+	mock.recorder = &MockURLBufferMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLBuffer) EXPECT() *MockURLBufferMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockURLBuffer) Add(src model.ShortURL) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", src)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockURLBufferMockRecorder) Add(src interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockURLBuffer)(nil).Add), src)
+}
+
+// Flush mocks base method.
+func (m *MockURLBuffer) Flush() map[string]error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(map[string]error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockURLBufferMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockURLBuffer)(nil).Flush))
 }
 
 // MockUserManager is a mock of UserManager interface.
@@ -182,6 +314,37 @@ func (mr *MockUserManagerMockRecorder) AddUser(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockUserManager)(nil).AddUser), ctx)
 }
 
+// AuthAPIKey mocks base method.
+func (m *MockUserManager) AuthAPIKey(ctx context.Context, rawKey string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthAPIKey", ctx, rawKey)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthAPIKey indicates an expected call of AuthAPIKey.
+func (mr *MockUserManagerMockRecorder) AuthAPIKey(ctx, rawKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthAPIKey", reflect.TypeOf((*MockUserManager)(nil).AuthAPIKey), ctx, rawKey)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockUserManager) CreateAPIKey(ctx context.Context, userID uuid.UUID, name string) (model.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, userID, name)
+	ret0, _ := ret[0].(model.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUserManagerMockRecorder) CreateAPIKey(ctx, userID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUserManager)(nil).CreateAPIKey), ctx, userID, name)
+}
+
 // Exist mocks base method.
 func (m *MockUserManager) Exist(ctx context.Context, id uuid.UUID) (bool, error) {
 	m.ctrl.T.Helper()
@@ -197,6 +360,21 @@ func (mr *MockUserManagerMockRecorder) Exist(ctx, id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exist", reflect.TypeOf((*MockUserManager)(nil).Exist), ctx, id)
 }
 
+// GetAPIKeys mocks base method.
+func (m *MockUserManager) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx, userID)
+	ret0, _ := ret[0].([]model.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockUserManagerMockRecorder) GetAPIKeys(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUserManager)(nil).GetAPIKeys), ctx, userID)
+}
+
 // GetCount mocks base method.
 func (m *MockUserManager) GetCount() (int, error) {
 	m.ctrl.T.Helper()
@@ -211,3 +389,17 @@ func (mr *MockUserManagerMockRecorder) GetCount() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCount", reflect.TypeOf((*MockUserManager)(nil).GetCount))
 }
+
+// RevokeAPIKey mocks base method.
+func (m *MockUserManager) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, userID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUserManagerMockRecorder) RevokeAPIKey(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUserManager)(nil).RevokeAPIKey), ctx, userID, keyID)
+}
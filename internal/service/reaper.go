@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/storage"
+)
+
+//  DefReaperInterval is default interval between expired urls checks.
+const DefReaperInterval = time.Minute
+
+//  ExpiredReaper periodically marks expired urls in storage.
+type ExpiredReaper struct {
+	db       storage.Storage
+	interval time.Duration
+}
+
+//  NewExpiredReaper inits new reaper, if interval not positive uses DefReaperInterval.
+func NewExpiredReaper(db storage.Storage, interval time.Duration) (*ExpiredReaper, error) {
+	if db == nil {
+		return nil, errors.New("ошибка инициализации хранилища")
+	}
+
+	if interval <= 0 {
+		interval = DefReaperInterval
+	}
+
+	return &ExpiredReaper{
+		db:       db,
+		interval: interval,
+	}, nil
+}
+
+//  Run marks expired urls every interval until context is done, errors are logged by logger from context.
+func (r *ExpiredReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.MarkExpired(ctx); err != nil {
+				logging.FromContext(ctx).Error("ошибка отметки истекших ссылок", "error", err.Error())
+			}
+		}
+	}
+}
+
+//  MarkExpired marks expired urls at current time, returns list of marked shortIDs.
+func (r *ExpiredReaper) MarkExpired(ctx context.Context) ([]string, error) {
+	return r.db.URL().MarkExpired(ctx, time.Now())
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+)
+
+func TestExpiredReaper_MarkExpired(t *testing.T) {
+	db, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user, err := db.User().AddUser(ctx, model.NewUser())
+	require.NoError(t, err)
+
+	expired := model.NewShortURL("https://practicum.yandex.ru/1", user.ID,
+		model.WithAlias("expired"), model.WithExpiresAt(time.Now().Add(-time.Minute)))
+	active := model.NewShortURL("https://practicum.yandex.ru/2", user.ID,
+		model.WithAlias("active"), model.WithExpiresAt(time.Now().Add(time.Hour)))
+	endless := model.NewShortURL("https://practicum.yandex.ru/3", user.ID, model.WithAlias("endless"))
+
+	for _, v := range []model.ShortURL{expired, active, endless} {
+		_, err := db.URL().SaveURL(ctx, v)
+		require.NoError(t, err)
+	}
+
+	count, err := db.URL().GetCount()
+	require.NoError(t, err)
+	require.Equal(t, 2, count, "истекшие ссылки не должны учитываться в статистике")
+
+	reaper, err := NewExpiredReaper(db, time.Second)
+	require.NoError(t, err)
+
+	marked, err := reaper.MarkExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{expired.ShortID}, marked)
+
+	stored, err := db.URL().GetURL(ctx, expired.ShortID)
+	require.NoError(t, err)
+	require.True(t, stored.IsExpired)
+
+	marked, err = reaper.MarkExpired(ctx)
+	require.NoError(t, err)
+	require.Empty(t, marked, "повторно ссылки не отмечаются")
+}
@@ -1,10 +1,17 @@
 package service
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/atrush/pract_01.git/internal/metrics"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
 )
 
 func TestGenerateShortLink(t *testing.T) {
@@ -44,3 +51,25 @@ func TestGenerateShortLink(t *testing.T) {
 		})
 	}
 }
+
+func TestShortURLService_iterShortURLGeneratorCollision(t *testing.T) {
+	db, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	m := metrics.New()
+	svc, err := NewShortURLService(db, nil, WithMetrics(m))
+	require.NoError(t, err)
+
+	//  first generated shortID is taken, so it is generated again with new salt
+	srcURL, salt := "https://github.com/", "8"
+	taken := GenerateShortLink(srcURL, salt)
+	check := map[string]string{taken: ""}
+
+	shortID, err := svc.iterShortURLGenerator(srcURL, 0, salt, check)
+	require.NoError(t, err)
+	assert.NotEqual(t, taken, shortID)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "shortener_shortid_collisions_total 1")
+}
@@ -4,37 +4,100 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/atrush/pract_01.git/internal/metrics"
 	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
 	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ URLShortener = (*ShortURLService)(nil)
 
-//  ShortURLService implements URLShortener interface, provides operations with urls.
-type ShortURLService struct {
-	db storage.Storage
+var (
+	//  tracer starts spans of service methods.
+	tracer = tracing.Tracer("github.com/atrush/pract_01.git/internal/service")
+
+	//  attrShortID is span attribute of requested shortID.
+	attrShortID = attribute.Key("shortener.short_id")
+)
+
+type (
+	//  ShortURLService implements URLShortener interface, provides operations with urls.
+	ShortURLService struct {
+		db      storage.Storage
+		alias   *AliasValidator
+		metrics *metrics.Metrics
+	}
+
+	//  ShortURLServiceOption sets optional params of URL service.
+	ShortURLServiceOption func(sh *ShortURLService)
+)
+
+//  WithMetrics sets metrics, that count shortID generation collisions.
+func WithMetrics(m *metrics.Metrics) ShortURLServiceOption {
+	return func(sh *ShortURLService) {
+		sh.metrics = m
+	}
 }
 
 //  NewShortURLService inits and returns new URL service.
-func NewShortURLService(db storage.Storage) (*ShortURLService, error) {
+//  If alias validator is nil, uses default alias rules.
+func NewShortURLService(db storage.Storage, alias *AliasValidator, opts ...ShortURLServiceOption) (*ShortURLService, error) {
 	if db == nil {
 		return nil, errors.New("ошибка инициализации хранилища")
 	}
 
-	return &ShortURLService{
-		db: db,
-	}, nil
+	if alias == nil {
+		alias = NewDefaultAliasValidator()
+	}
+
+	sh := &ShortURLService{
+		db:    db,
+		alias: alias,
+	}
+	for _, opt := range opts {
+		opt(sh)
+	}
+
+	return sh, nil
 }
 
-//  DeleteURLList marks list of short urls as deleted.
-func (sh *ShortURLService) DeleteURLList(userID uuid.UUID, shotIDList ...string) error {
+//  DeleteURLList saves job, that async marks list of short urls as deleted, returns accepted job.
+func (sh *ShortURLService) DeleteURLList(ctx context.Context, userID uuid.UUID, shotIDList ...string) (_ model.DeleteJob, err error) {
+	_, span := tracer.Start(ctx, "ShortURLService.DeleteURLList")
+	defer tracing.End(span, &err)
+
 	return sh.db.URL().DeleteURLBatch(userID, shotIDList...)
 }
 
-//  SaveURLList saves map[external_id]URL to storage, updates URL to ShortID in map.
-func (sh *ShortURLService) SaveURLList(src map[string]string, userID uuid.UUID) (map[string]string, error) {
+//  GetDeleteJob returns delete job of user by id.
+//  Returns shterrors.ErrDeleteJobNotFound if job not found or belongs to another user.
+func (sh *ShortURLService) GetDeleteJob(ctx context.Context, userID uuid.UUID, jobID uuid.UUID) (_ model.DeleteJob, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.GetDeleteJob")
+	defer tracing.End(span, &err)
+
+	job, err := sh.db.URL().GetDeleteJob(ctx, jobID)
+	if err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	if job.UserID != userID {
+		return model.DeleteJob{}, shterrors.ErrDeleteJobNotFound
+	}
+
+	return job, nil
+}
+
+//  SaveURLList saves map[external_id]ShortURL to storage, returns map[external_id]ShortID.
+//  Incoming items contain url, optional alias in ShortID and optional ExpiresAt.
+func (sh *ShortURLService) SaveURLList(ctx context.Context, src map[string]model.ShortURL, userID uuid.UUID) (_ map[string]string, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.SaveURLList")
+	defer tracing.End(span, &err)
 
 	//  map of new shortURL with incoming IDs
 	toAdd := make(map[string]model.ShortURL, len(src))
@@ -42,25 +105,46 @@ func (sh *ShortURLService) SaveURLList(src map[string]string, userID uuid.UUID)
 	//  map for cheking new shortID for unique
 	checkShortID := make(map[string]string, len(src))
 
-	//  generate new shortURLs and send to save db
+	//  check expiration and aliases first, generated shortIDs must not take aliases
+	for k, v := range src {
+		if err := checkExpiresAt(v.ExpiresAt); err != nil {
+			return nil, err
+		}
+
+		sht := model.NewShortURL(v.URL, userID, model.WithAlias(v.ShortID), model.WithExpiresAt(v.ExpiresAt))
+		if sht.ShortID == "" {
+			continue
+		}
+
+		if err := sh.checkAlias(sht.ShortID, checkShortID); err != nil {
+			return nil, err
+		}
+		toAdd[k] = sht
+	}
+
+	//  generate new shortURLs
 	for k, v := range src {
+		if _, ok := toAdd[k]; ok {
+			continue
+		}
 
-		sht := model.NewShortURL(v, userID)
+		sht := model.NewShortURL(v.URL, userID, model.WithExpiresAt(v.ExpiresAt))
 
-		shortID, err := sh.genShortURL(v, sht.ID, checkShortID)
+		shortID, err := sh.genShortURL(v.URL, sht.ID, checkShortID)
 		if err != nil {
 			return nil, err
 		}
 
 		sht.ShortID = shortID
-		if err := sh.db.URL().SaveURLBuff(&sht); err != nil {
-			return nil, err
-		}
 		toAdd[k] = sht
 	}
 
-	//  flush buffer
-	if err := sh.db.URL().SaveURLBuffFlush(); err != nil {
+	//  save all urls in one batch, list is saved or not saved entirely
+	list := make([]model.ShortURL, 0, len(toAdd))
+	for _, sht := range toAdd {
+		list = append(list, sht)
+	}
+	if err := sh.db.URL().SaveURLBatch(ctx, list); err != nil {
 		return nil, err
 	}
 
@@ -72,18 +156,42 @@ func (sh *ShortURLService) SaveURLList(src map[string]string, userID uuid.UUID)
 	return resMap, nil
 }
 
-//  GetUserURLList returns array of stored urlss by user id.
-func (sh *ShortURLService) GetUserURLList(ctx context.Context, userID uuid.UUID) ([]model.ShortURL, error) {
-	list, err := sh.db.URL().GetUserURLList(ctx, userID, 100)
+//  GetUserURLList returns page of stored urls by user id and filter.
+//  Zero limit replaced with default, limit more than max is reduced to max.
+//  NextCursor of page is set if more urls exist.
+func (sh *ShortURLService) GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) (_ model.URLPage, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.GetUserURLList")
+	defer tracing.End(span, &err)
+
+	if filter.Limit <= 0 {
+		filter.Limit = model.DefURLListLimit
+	}
+	if filter.Limit > model.MaxURLListLimit {
+		filter.Limit = model.MaxURLListLimit
+	}
+	limit := filter.Limit
+
+	//  select one more url to check next page exist
+	filter.Limit++
+	list, err := sh.db.URL().GetUserURLList(ctx, userID, filter)
 	if err != nil {
-		return nil, err
+		return model.URLPage{}, err
 	}
 
-	return list, nil
+	page := model.URLPage{URLs: list}
+	if len(list) > limit {
+		page.URLs = list[:limit]
+		page.NextCursor = model.NewURLCursor(page.URLs[limit-1]).Encode()
+	}
+
+	return page, nil
 }
 
-//  GetURL returns stored url by shortID.
-func (sh *ShortURLService) GetURL(ctx context.Context, shortID string) (model.ShortURL, error) {
+//  GetURL returns stored url by shortID, returns shterrors.ErrNotFound if url not exist.
+func (sh *ShortURLService) GetURL(ctx context.Context, shortID string) (_ model.ShortURL, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.GetURL", trace.WithAttributes(attrShortID.String(shortID)))
+	defer tracing.End(span, &err)
+
 	longURL, err := sh.db.URL().GetURL(ctx, shortID)
 	if err != nil {
 		return model.ShortURL{}, err
@@ -93,15 +201,27 @@ func (sh *ShortURLService) GetURL(ctx context.Context, shortID string) (model.Sh
 }
 
 //  SaveURL saves url for user, return shortID.
-func (sh *ShortURLService) SaveURL(ctx context.Context, srcURL string, userID uuid.UUID) (string, error) {
+//  If alias option given, uses alias as shortID. If expiration option given, url expires at given time.
+func (sh *ShortURLService) SaveURL(ctx context.Context, srcURL string, userID uuid.UUID, opts ...model.ShortURLOption) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.SaveURL")
+	defer tracing.End(span, &err)
 
-	sht := model.NewShortURL(srcURL, userID)
+	sht := model.NewShortURL(srcURL, userID, opts...)
 
-	var err error
-	if sht.ShortID, err = sh.genShortURL(srcURL, sht.ID, nil); err != nil {
+	if err := checkExpiresAt(sht.ExpiresAt); err != nil {
 		return "", err
 	}
 
+	if sht.ShortID != "" {
+		if err := sh.checkAlias(sht.ShortID, nil); err != nil {
+			return "", err
+		}
+	} else {
+		if sht.ShortID, err = sh.genShortURL(srcURL, sht.ID, nil); err != nil {
+			return "", err
+		}
+	}
+
 	sht, err = sh.db.URL().SaveURL(ctx, sht)
 	if err != nil {
 		return "", err
@@ -111,15 +231,111 @@ func (sh *ShortURLService) SaveURL(ctx context.Context, srcURL string, userID uu
 }
 
 //  Ping checks storage connection.
-func (sh *ShortURLService) Ping(ctx context.Context) error {
+func (sh *ShortURLService) Ping(ctx context.Context) (err error) {
+	_, span := tracer.Start(ctx, "ShortURLService.Ping")
+	defer tracing.End(span, &err)
+
 	return sh.db.Ping()
 }
 
 //  GetCount returns count of stored, not deleted urls.
-func (sh *ShortURLService) GetCount() (int, error) {
+func (sh *ShortURLService) GetCount(ctx context.Context) (_ int, err error) {
+	_, span := tracer.Start(ctx, "ShortURLService.GetCount")
+	defer tracing.End(span, &err)
+
 	return sh.db.URL().GetCount()
 }
 
+//  GetDeleteQueueStats returns state of async url delete queue, false if storage deletes urls without queue.
+func (sh *ShortURLService) GetDeleteQueueStats() (model.DeleteQueueStats, bool) {
+	return storage.DeleteQueueStats(sh.db)
+}
+
+//  AddClick records redirect by short url without blocking.
+func (sh *ShortURLService) AddClick(ctx context.Context, click model.Click) (err error) {
+	_, span := tracer.Start(ctx, "ShortURLService.AddClick", trace.WithAttributes(attrShortID.String(click.ShortID)))
+	defer tracing.End(span, &err)
+
+	return sh.db.Click().AddClick(click)
+}
+
+//  GetClickStats returns redirect stats of user url by shortID.
+//  Returns shterrors.ErrAccessDenied if url not found or belongs to another user.
+func (sh *ShortURLService) GetClickStats(ctx context.Context, userID uuid.UUID, shortID string) (_ model.ClickStats, err error) {
+	ctx, span := tracer.Start(ctx, "ShortURLService.GetClickStats", trace.WithAttributes(attrShortID.String(shortID)))
+	defer tracing.End(span, &err)
+
+	sht, err := sh.db.URL().GetURL(ctx, shortID)
+	if errors.Is(err, shterrors.ErrNotFound) {
+		return model.ClickStats{}, shterrors.ErrAccessDenied
+	}
+	if err != nil {
+		return model.ClickStats{}, err
+	}
+
+	if sht.UserID != userID {
+		return model.ClickStats{}, shterrors.ErrAccessDenied
+	}
+
+	return sh.db.Click().GetStats(ctx, shortID)
+}
+
+//  ExpirationTime returns url expiration time from absolute time or ttl, zero time if both not given.
+//  Returns shterrors.ErrExpirationNotValid if both given or ttl is negative.
+func ExpirationTime(expiresAt time.Time, ttl time.Duration) (time.Time, error) {
+	if !expiresAt.IsZero() && ttl != 0 {
+		return time.Time{}, fmt.Errorf("%w: нельзя одновременно задать время истечения и ttl", shterrors.ErrExpirationNotValid)
+	}
+
+	if ttl < 0 {
+		return time.Time{}, fmt.Errorf("%w: ttl не может быть отрицательным", shterrors.ErrExpirationNotValid)
+	}
+
+	if ttl > 0 {
+		return time.Now().Add(ttl), nil
+	}
+	return expiresAt, nil
+}
+
+//  checkExpiresAt checks that url expiration time is not passed, zero time means url never expires.
+func checkExpiresAt(expiresAt time.Time) error {
+	if !expiresAt.IsZero() && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: время истечения %v уже прошло", shterrors.ErrExpirationNotValid, expiresAt)
+	}
+	return nil
+}
+
+//  checkAlias validates user defined alias and checks that alias is not used.
+//  For checking multiple aliases use generatedCheck.
+func (sh *ShortURLService) checkAlias(alias string, generatedCheck map[string]string) error {
+	if err := sh.alias.Validate(alias); err != nil {
+		return err
+	}
+
+	existInCheck := false
+	if generatedCheck != nil {
+		_, existInCheck = generatedCheck[alias]
+	}
+
+	exist, err := sh.db.URL().Exist(alias)
+	if err != nil {
+		return fmt.Errorf("ошибка проверки alias:%w", err)
+	}
+
+	if exist || existInCheck {
+		return &shterrors.ErrorConflictSaveURL{
+			Err:           fmt.Errorf("alias %v уже используется", alias),
+			ExistShortURL: alias,
+			Code:          shterrors.ConflictCodeAlias,
+		}
+	}
+
+	if generatedCheck != nil {
+		generatedCheck[alias] = ""
+	}
+	return nil
+}
+
 //  genShortURL generate unique ShortID, for generating multiple shortIDs use generatedCheck.
 func (sh *ShortURLService) genShortURL(srcURL string, id uuid.UUID, generatedCheck map[string]string) (string, error) {
 	shortID, err := sh.iterShortURLGenerator(string(srcURL), 0, id.String(), generatedCheck)
@@ -145,6 +361,8 @@ func (sh *ShortURLService) iterShortURLGenerator(srcURL string, iterationCount i
 		return "", fmt.Errorf("ошибка генерации короткой ссылки:%w", err)
 	}
 	if exist || existInCheck {
+		sh.metrics.ShortIDCollision()
+
 		iterationCount++
 		if iterationCount > maxIterate {
 			return "", fmt.Errorf("ошибка генерации короткой ссылки, число попыток:%v", maxIterate)
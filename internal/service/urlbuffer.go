@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/google/uuid"
+)
+
+var _ URLBuffer = (*urlBuffer)(nil)
+
+//  urlBuffer implements URLBuffer, collects checked user urls and saves them with storage SaveURLBatch.
+//  Every stream uses own buffer, so conflict or error of one stream does not fail items of other streams.
+type urlBuffer struct {
+	ctx    context.Context
+	sh     *ShortURLService
+	userID uuid.UUID
+	items  []model.ShortURL
+	//  srcURLs contains source urls of buffered items with their shortIDs
+	srcURLs map[string]string
+	//  checkShortID contains shortIDs generated in buffer, checks them for unique
+	checkShortID map[string]string
+}
+
+//  NewURLBuffer returns buffer for saving stream of user urls.
+func (sh *ShortURLService) NewURLBuffer(ctx context.Context, userID uuid.UUID) URLBuffer {
+	return &urlBuffer{
+		ctx:          ctx,
+		sh:           sh,
+		userID:       userID,
+		srcURLs:      make(map[string]string),
+		checkShortID: make(map[string]string),
+	}
+}
+
+//  Add checks url, generates shortID if alias is empty, adds url to buffer and returns shortID.
+//  Returns shterrors.ErrorConflictSaveURL if url is already stored or buffered.
+func (b *urlBuffer) Add(src model.ShortURL) (string, error) {
+	if err := checkExpiresAt(src.ExpiresAt); err != nil {
+		return "", err
+	}
+
+	if shortID, exist := b.srcURLs[src.URL]; exist {
+		return "", &shterrors.ErrorConflictSaveURL{
+			Err:           fmt.Errorf("URL %v уже добавлен", src.URL),
+			ExistShortURL: shortID,
+			Code:          shterrors.ConflictCodeURL,
+		}
+	}
+
+	stored, err := b.sh.db.URL().GetURLBySrcURL(b.ctx, src.URL)
+	if err == nil {
+		return "", &shterrors.ErrorConflictSaveURL{
+			Err:           fmt.Errorf("URL %v уже существует", src.URL),
+			ExistShortURL: stored.ShortID,
+			Code:          shterrors.ConflictCodeURL,
+		}
+	}
+	if !errors.Is(err, shterrors.ErrNotFound) {
+		return "", fmt.Errorf("ошибка проверки URL:%w", err)
+	}
+
+	sht := model.NewShortURL(src.URL, b.userID, model.WithAlias(src.ShortID), model.WithExpiresAt(src.ExpiresAt))
+	if sht.ShortID != "" {
+		if err := b.sh.checkAlias(sht.ShortID, b.checkShortID); err != nil {
+			return "", err
+		}
+	} else {
+		shortID, err := b.sh.genShortURL(sht.URL, sht.ID, b.checkShortID)
+		if err != nil {
+			return "", err
+		}
+
+		sht.ShortID = shortID
+	}
+
+	b.items = append(b.items, sht)
+	b.srcURLs[sht.URL] = sht.ShortID
+
+	return sht.ShortID, nil
+}
+
+//  Flush writes buffered urls to storage in one batch.
+//  If batch is not saved, urls are saved one by one, so only failed urls are not saved.
+//  Returns errors of not saved urls by shortID, nil if all urls saved.
+func (b *urlBuffer) Flush() map[string]error {
+	items := b.items
+	b.items = nil
+	b.srcURLs = make(map[string]string)
+	b.checkShortID = make(map[string]string)
+
+	if len(items) == 0 {
+		return nil
+	}
+	if err := b.sh.db.URL().SaveURLBatch(b.ctx, items); err == nil {
+		return nil
+	}
+
+	var failed map[string]error
+	for _, sht := range items {
+		if _, err := b.sh.db.URL().SaveURL(b.ctx, sht); err != nil {
+			if !errors.Is(err, &shterrors.ErrorConflictSaveURL{}) {
+				err = fmt.Errorf("%w: %v", shterrors.ErrBufferFlush, err)
+			}
+
+			if failed == nil {
+				failed = make(map[string]error)
+			}
+			failed[sht.ShortID] = err
+		}
+	}
+
+	return failed
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+)
+
+func TestURLBuffer_Add(t *testing.T) {
+	db, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user, err := db.User().AddUser(ctx, model.NewUser())
+	require.NoError(t, err)
+
+	svc, err := NewShortURLService(db, nil)
+	require.NoError(t, err)
+
+	buf := svc.NewURLBuffer(ctx, user.ID)
+
+	generated, err := buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, generated)
+
+	alias, err := buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/2", ShortID: "mylink"})
+	require.NoError(t, err)
+	require.Equal(t, "mylink", alias)
+
+	_, err = buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/3", ShortID: "mylink"})
+	require.True(t, errors.Is(err, &shterrors.ErrorConflictSaveURL{}), "занятый alias должен вернуть конфликт")
+
+	_, err = buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/1"})
+	require.True(t, errors.Is(err, &shterrors.ErrorConflictSaveURL{}), "добавленная ссылка должна вернуть конфликт")
+
+	_, err = buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/4", ExpiresAt: time.Now().Add(-time.Minute)})
+	require.ErrorIs(t, err, shterrors.ErrExpirationNotValid)
+
+	require.Empty(t, buf.Flush())
+
+	for _, shortID := range []string{generated, alias} {
+		sht, err := svc.GetURL(ctx, shortID)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, sht.UserID)
+	}
+
+	//  stored url returns conflict with stored shortID
+	_, err = buf.Add(model.ShortURL{URL: "https://practicum.yandex.ru/1"})
+	var conflictErr *shterrors.ErrorConflictSaveURL
+	require.ErrorAs(t, err, &conflictErr)
+	require.Equal(t, generated, conflictErr.ExistShortURL)
+}
+
+func TestURLBuffer_Flush(t *testing.T) {
+	db, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	user, err := db.User().AddUser(ctx, model.NewUser())
+	require.NoError(t, err)
+
+	svc, err := NewShortURLService(db, nil)
+	require.NoError(t, err)
+
+	//  buffers of two streams with same url
+	first := svc.NewURLBuffer(ctx, user.ID)
+	second := svc.NewURLBuffer(ctx, user.ID)
+
+	duplicate, err := first.Add(model.ShortURL{URL: "https://practicum.yandex.ru/1"})
+	require.NoError(t, err)
+	saved, err := first.Add(model.ShortURL{URL: "https://practicum.yandex.ru/2"})
+	require.NoError(t, err)
+
+	stored, err := second.Add(model.ShortURL{URL: "https://practicum.yandex.ru/1"})
+	require.NoError(t, err)
+	require.Empty(t, second.Flush())
+
+	//  batch fails on duplicate, only duplicate is not saved
+	failed := first.Flush()
+	require.Len(t, failed, 1)
+	var conflictErr *shterrors.ErrorConflictSaveURL
+	require.ErrorAs(t, failed[duplicate], &conflictErr)
+	require.Equal(t, stored, conflictErr.ExistShortURL)
+
+	_, err = svc.GetURL(ctx, saved)
+	require.NoError(t, err)
+	_, err = svc.GetURL(ctx, duplicate)
+	require.ErrorIs(t, err, shterrors.ErrNotFound)
+
+	require.Empty(t, first.Flush(), "буфер очищен после записи")
+}
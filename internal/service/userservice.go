@@ -3,9 +3,13 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
 	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/tracing"
 	"github.com/google/uuid"
 )
 
@@ -28,12 +32,15 @@ func NewUserService(db storage.Storage) (*UserService, error) {
 }
 
 //  Exist checks user is exist, by user id.
-func (u *UserService) Exist(ctx context.Context, id uuid.UUID) (bool, error) {
+func (u *UserService) Exist(ctx context.Context, id uuid.UUID) (_ bool, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.Exist")
+	defer tracing.End(span, &err)
+
 	if id == uuid.Nil {
 		return false, errors.New("ошибка проверки существования user: uuid nil")
 	}
 
-	return u.db.User().Exist(id)
+	return u.db.User().Exist(ctx, id)
 }
 
 //  GetCount returns count of stored users.
@@ -51,3 +58,48 @@ func (u *UserService) AddUser(ctx context.Context) (model.User, error) {
 	}
 	return newUser, nil
 }
+
+//  CreateAPIKey issues new api key for user, stores only key hash.
+func (u *UserService) CreateAPIKey(ctx context.Context, userID uuid.UUID, name string) (model.APIKey, string, error) {
+	if len(name) > model.MaxAPIKeyNameLen {
+		return model.APIKey{}, "", fmt.Errorf("длина имени ключа больше %v", model.MaxAPIKeyNameLen)
+	}
+
+	key, raw, err := model.NewAPIKey(userID, name)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+
+	key, err = u.db.APIKey().AddKey(ctx, key)
+	if err != nil {
+		return model.APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+//  GetAPIKeys returns all api keys of user.
+func (u *UserService) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	return u.db.APIKey().GetUserKeys(ctx, userID)
+}
+
+//  RevokeAPIKey revokes user api key.
+func (u *UserService) RevokeAPIKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID) error {
+	return u.db.APIKey().RevokeKey(ctx, userID, keyID, time.Now())
+}
+
+//  AuthAPIKey finds api key by hash, returns id of key owner.
+func (u *UserService) AuthAPIKey(ctx context.Context, rawKey string) (uuid.UUID, error) {
+	if !model.IsAPIKey(rawKey) {
+		return uuid.Nil, shterrors.ErrAPIKeyNotFound
+	}
+
+	key, err := u.db.APIKey().GetKeyByHash(ctx, model.HashAPIKey(rawKey))
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if key.Revoked() {
+		return uuid.Nil, shterrors.ErrAPIKeyNotFound
+	}
+	return key.UserID, nil
+}
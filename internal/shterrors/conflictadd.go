@@ -2,10 +2,18 @@ package shterrors
 
 var _ error = (*ErrorConflictSaveURL)(nil)
 
+//  Conflict codes of ErrorConflictSaveURL.
+const (
+	ConflictCodeURL   = "url_exists"  //  saved url is exist in storage
+	ConflictCodeAlias = "alias_taken" //  requested alias is used by another url
+)
+
 //  ErrorConflictSaveURL implements error if saved url is exist in storage.
+//  Code contains conflict reason, ExistShortURL - stored shortID.
 type ErrorConflictSaveURL struct {
 	Err           error
 	ExistShortURL string
+	Code          string
 }
 
 func (*ErrorConflictSaveURL) Error() string {
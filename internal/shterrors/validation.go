@@ -0,0 +1,32 @@
+package shterrors
+
+import "errors"
+
+var (
+	//  ErrAliasNotValid returns if user defined alias does not match alias rules.
+	ErrAliasNotValid = errors.New("недопустимое значение alias")
+
+	//  ErrExpirationNotValid returns if url expiration time or ttl not valid.
+	ErrExpirationNotValid = errors.New("недопустимое время жизни ссылки")
+
+	//  ErrNotFound returns by storage if url with shortID not exist.
+	ErrNotFound = errors.New("ссылка не найдена")
+
+	//  ErrAccessDenied returns if url not exist or belongs to another user.
+	ErrAccessDenied = errors.New("ссылка не найдена у пользователя")
+
+	//  ErrAPIKeyNotFound returns if api key not exist, revoked or belongs to another user.
+	ErrAPIKeyNotFound = errors.New("ключ API не найден")
+
+	//  ErrBufferFlush returns if writing of buffered urls to storage failed, urls buffered after last flush are not saved.
+	ErrBufferFlush = errors.New("ошибка записи буфера ссылок")
+
+	//  ErrDeleteQueueFull returns if async delete queue has no space for urls, request must be retried later.
+	ErrDeleteQueueFull = errors.New("очередь удаления переполнена")
+
+	//  ErrDeleteListTooLarge returns if delete list is larger than async delete queue, list can never be accepted.
+	ErrDeleteListTooLarge = errors.New("список удаления больше размера очереди удаления")
+
+	//  ErrDeleteJobNotFound returns if delete job not exist or belongs to another user.
+	ErrDeleteJobNotFound = errors.New("задача удаления не найдена")
+)
@@ -0,0 +1,135 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ st.APIKeyRepository = (*apiKeyRepository)(nil)
+
+//  apiKeyRepository implements APIKeyRepository interface, provides actions with api key records in bolt storage.
+type apiKeyRepository struct {
+	db *bolt.DB
+}
+
+//  newAPIKeyRepository inits new api key repository.
+func newAPIKeyRepository(db *bolt.DB) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+//  AddKey saves api key to database.
+func (r *apiKeyRepository) AddKey(_ context.Context, key model.APIKey) (model.APIKey, error) {
+	dbObj, err := schema.NewAPIKeyFromCanonical(key)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		hashes := tx.Bucket(bucketAPIKeyHashes)
+		if hashes.Get([]byte(dbObj.Hash)) != nil {
+			return errors.New("ключ уже существует")
+		}
+		if tx.Bucket(bucketUsers).Get(dbObj.UserID[:]) == nil {
+			return errors.New("пользователь не найден")
+		}
+
+		id := dbObj.ID[:]
+		if err := putJSON(tx.Bucket(bucketAPIKeys), id, dbObj); err != nil {
+			return err
+		}
+		if err := hashes.Put([]byte(dbObj.Hash), id); err != nil {
+			return err
+		}
+
+		return tx.Bucket(bucketUserAPIKeys).Put(orderedKey(dbObj.UserID, dbObj.CreatedAt, dbObj.ID), id)
+	})
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return key, nil
+}
+
+//  GetKeyByHash selects api key from database by key hash.
+func (r *apiKeyRepository) GetKeyByHash(_ context.Context, hash string) (model.APIKey, error) {
+	var dbObj schema.APIKey
+	err := r.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(bucketAPIKeyHashes).Get([]byte(hash))
+		if id == nil {
+			return shterrors.ErrAPIKeyNotFound
+		}
+
+		ok, err := getJSON(tx.Bucket(bucketAPIKeys), id, &dbObj)
+		if err != nil {
+			return fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		if !ok {
+			return shterrors.ErrAPIKeyNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetUserKeys selects all api keys of user from database, ordered by creation time.
+func (r *apiKeyRepository) GetUserKeys(_ context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	keys := make(schema.APIKeyList, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		apiKeys := tx.Bucket(bucketAPIKeys)
+		c := tx.Bucket(bucketUserAPIKeys).Cursor()
+
+		prefix := userID[:]
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var dbObj schema.APIKey
+			if _, err := getJSON(apiKeys, v, &dbObj); err != nil {
+				return err
+			}
+			keys = append(keys, dbObj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return keys.ToCanonical()
+}
+
+//  RevokeKey marks user api key as revoked.
+func (r *apiKeyRepository) RevokeKey(_ context.Context, userID uuid.UUID, keyID uuid.UUID, revokedAt time.Time) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		apiKeys := tx.Bucket(bucketAPIKeys)
+
+		var dbObj schema.APIKey
+		ok, err := getJSON(apiKeys, keyID[:], &dbObj)
+		if err != nil {
+			return fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		if !ok || dbObj.UserID != userID || !dbObj.RevokedAt.IsZero() {
+			return shterrors.ErrAPIKeyNotFound
+		}
+
+		dbObj.RevokedAt = revokedAt
+		if err := putJSON(apiKeys, keyID[:], dbObj); err != nil {
+			return fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,200 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/model"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ st.ClickRepository = (*clickRepository)(nil)
+
+//  clickRepository implements ClickRepository interface, provides actions with click records in bolt storage.
+//  Clicks of every shortID are stored in nested bucket by sequence number.
+type clickRepository struct {
+	db         *bolt.DB
+	clickChan  chan schema.Click
+	asyncEnded chan struct{}
+	closed     bool
+	logger     *slog.Logger
+	sync.RWMutex
+}
+
+const (
+	clickBuffBatch     = 100         //  size of buffer for batch insert clicks.
+	clickChanSize      = 1000        //  size of incoming clicks queue.
+	clickFlushInterval = time.Second //  interval of flushing not full clicks buffer.
+
+	//  maxReferrers is max count of referrers in stats.
+	maxReferrers = 10
+)
+
+//  newClickRepository inits new click repository.
+func newClickRepository(ctx context.Context, db *bolt.DB, asyncEnded chan struct{}) *clickRepository {
+	repo := clickRepository{
+		db:         db,
+		clickChan:  make(chan schema.Click, clickChanSize),
+		asyncEnded: asyncEnded,
+		logger:     logging.FromContext(ctx),
+	}
+	repo.waitAsync(ctx)
+	repo.initClickBatchWorker()
+
+	return &repo
+}
+
+//  waitAsync closes clicks queue on context done.
+func (r *clickRepository) waitAsync(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+
+		r.Lock()
+		defer r.Unlock()
+		r.closed = true
+		close(r.clickChan)
+	}()
+}
+
+//  AddClick adds click to queue without blocking.
+//  If queue is full, click is dropped.
+func (r *clickRepository) AddClick(click model.Click) error {
+	dbObj, err := schema.NewClickFromCanonical(click)
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.closed {
+		return errors.New("очередь записи переходов закрыта")
+	}
+
+	select {
+	case r.clickChan <- dbObj:
+		return nil
+	default:
+		return errors.New("очередь записи переходов переполнена")
+	}
+}
+
+//  initClickBatchWorker runs single click worker, that takes clicks from clickChan
+//  and inserts when filling the cache, or by flush interval.
+func (r *clickRepository) initClickBatchWorker() {
+	go func() {
+		ticker := time.NewTicker(clickFlushInterval)
+		defer ticker.Stop()
+
+		cache := make([]schema.Click, 0, clickBuffBatch)
+		for {
+			select {
+			// read click from clickChan
+			case v, ok := <-r.clickChan:
+				if !ok { // if chanel closed, write buff and send to asyncEnded
+					if len(cache) > 0 {
+						if err := r.insertTxClickBatch(cache); err != nil {
+							r.logger.Error("ошибка транзакции сохранения переходов", "error", err.Error())
+						}
+					}
+					r.asyncEnded <- struct{}{}
+					return
+				}
+				cache = append(cache, v)
+				if len(cache) < cap(cache) {
+					continue
+				}
+			// flush by interval
+			case <-ticker.C:
+				if len(cache) == 0 {
+					continue
+				}
+			}
+			if err := r.insertTxClickBatch(cache); err != nil {
+				r.logger.Error("ошибка транзакции сохранения переходов", "error", err.Error())
+			}
+			cache = make([]schema.Click, 0, clickBuffBatch)
+		}
+	}()
+}
+
+//  insertTxClickBatch inserts array of clicks in one transaction.
+func (r *clickRepository) insertTxClickBatch(clicks []schema.Click) error {
+	return r.db.Update(func(tx *bolt.Tx) error {
+		for _, c := range clicks {
+			b, err := tx.Bucket(bucketClicks).CreateBucketIfNotExists([]byte(c.ShortID))
+			if err != nil {
+				return err
+			}
+
+			seq, err := b.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			if err := putJSON(b, sequenceKey(seq), c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+//  GetStats returns aggregated redirect stats for shortID.
+func (r *clickRepository) GetStats(_ context.Context, shortID string) (model.ClickStats, error) {
+	stats := model.ClickStats{
+		ShortID:   shortID,
+		Referrers: make(map[string]int),
+	}
+
+	ips := make(map[string]struct{})
+	referrers := make(map[string]int)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketClicks).Bucket([]byte(shortID))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, _ []byte) error {
+			var v schema.Click
+			if _, err := getJSON(b, k, &v); err != nil {
+				return err
+			}
+
+			stats.Clicks++
+			ips[v.IP] = struct{}{}
+			if v.ClickedAt.After(stats.LastClickAt) {
+				stats.LastClickAt = v.ClickedAt
+			}
+			if v.Referrer != "" {
+				referrers[v.Referrer]++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return model.ClickStats{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	stats.UniqueIPs = len(ips)
+
+	//  take top referrers
+	for i := 0; i < maxReferrers && len(referrers) > 0; i++ {
+		top, topCount := "", 0
+		for k, v := range referrers {
+			if v > topCount || (v == topCount && k < top) {
+				top, topCount = k, v
+			}
+		}
+		stats.Referrers[top] = topCount
+		delete(referrers, top)
+	}
+
+	return stats, nil
+}
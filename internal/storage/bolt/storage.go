@@ -0,0 +1,208 @@
+package bolt
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+const (
+	//  DSNPrefix is prefix of bolt database dsn, followed by database file path.
+	DSNPrefix = "bolt://"
+
+	//  openTimeout is waiting time of database file lock, file is locked by one process.
+	openTimeout = time.Second
+)
+
+//  Buckets of database. Urls, users and api keys are stored as json of storage schema objects,
+//  indexes contain id of object.
+var (
+	bucketURLs         = []byte("urls")          //  url id - url
+	bucketURLShortIDs  = []byte("urls_shortid")  //  shortID - url id
+	bucketURLSrcURLs   = []byte("urls_srcurl")   //  source url - url id
+	bucketUserURLs     = []byte("user_urls")     //  user id, created at, url id - url id
+	bucketUsers        = []byte("users")         //  user id - empty
+	bucketClicks       = []byte("clicks")        //  shortID - bucket of clicks by sequence
+	bucketAPIKeys      = []byte("api_keys")      //  key id - api key
+	bucketAPIKeyHashes = []byte("api_keys_hash") //  key hash - key id
+	bucketUserAPIKeys  = []byte("user_api_keys") //  user id, created at, key id - key id
+	bucketDeleteJobs   = []byte("delete_jobs")   //  job id - delete job
+)
+
+//  allBuckets is list of buckets created on open.
+var allBuckets = [][]byte{
+	bucketURLs, bucketURLShortIDs, bucketURLSrcURLs, bucketUserURLs, bucketUsers,
+	bucketClicks, bucketAPIKeys, bucketAPIKeyHashes, bucketUserAPIKeys, bucketDeleteJobs,
+}
+
+//  Storage implements Storage interface, provides storing data in single bolt database file.
+//  Every change is written in transaction, file is consistent after crash.
+type Storage struct {
+	shortURLRepo *shortURLRepository
+	userRepo     *userRepository
+	clickRepo    *clickRepository
+	apiKeyRepo   *apiKeyRepository
+	db           *bolt.DB
+
+	storageAsyncEnded chan struct{}
+	clickAsyncEnded   chan struct{}
+}
+
+//  IsDSN checks that dsn is bolt database dsn.
+func IsDSN(conStringDSN string) bool {
+	return strings.HasPrefix(conStringDSN, DSNPrefix)
+}
+
+//  NewStorage opens bolt database file from dsn "bolt://<path>", creates file and buckets if not exist.
+//  Async errors are logged by logger from context.
+func NewStorage(ctx context.Context, asyncEndedChan chan struct{}, conStringDSN string) (*Storage, error) {
+	if !IsDSN(conStringDSN) {
+		return nil, fmt.Errorf("ошибка инициализации бд:строка соединения с бд должна начинаться с %v", DSNPrefix)
+	}
+
+	path := strings.TrimPrefix(conStringDSN, DSNPrefix)
+	if path == "" {
+		return nil, errors.New("ошибка инициализации бд:путь до файла бд пуст")
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия бд: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка инициализации бд: %w", err)
+	}
+
+	st := &Storage{
+		db:                db,
+		storageAsyncEnded: asyncEndedChan,
+		clickAsyncEnded:   make(chan struct{}),
+	}
+
+	st.shortURLRepo = newShortURLRepository(db)
+	st.userRepo = newUserRepository(db)
+	st.clickRepo = newClickRepository(ctx, db, st.clickAsyncEnded)
+	st.apiKeyRepo = newAPIKeyRepository(db)
+
+	st.runWaiterAsyncEnded()
+	return st, nil
+}
+
+//  WaitAsyncTasksEnded returns true, clicks are written async.
+func (s *Storage) WaitAsyncTasksEnded() bool {
+	return true
+}
+
+func (s *Storage) runWaiterAsyncEnded() {
+	go func() {
+		//  wait click repository async tasks ended
+		<-s.clickAsyncEnded
+
+		s.storageAsyncEnded <- struct{}{}
+	}()
+}
+
+//  URL returns urls repository.
+func (s *Storage) URL() storage.URLRepository {
+	return s.shortURLRepo
+}
+
+//  User returns users repository.
+func (s *Storage) User() storage.UserRepository {
+	return s.userRepo
+}
+
+//  Click returns clicks repository.
+func (s *Storage) Click() storage.ClickRepository {
+	return s.clickRepo
+}
+
+//  APIKey returns api keys repository.
+func (s *Storage) APIKey() storage.APIKeyRepository {
+	return s.apiKeyRepo
+}
+
+//  Ping checks that database file is opened.
+func (s *Storage) Ping() error {
+	if s == nil || s.db == nil {
+		return errors.New("db not initialized")
+	}
+
+	return s.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketURLs) == nil {
+			return errors.New("бд не инициализирована")
+		}
+		return nil
+	})
+}
+
+//  Close closes database file.
+func (s *Storage) Close() {
+	if s.db == nil {
+		return
+	}
+
+	s.db.Close()
+	s.db = nil
+}
+
+//  getJSON reads json value of key from bucket to obj, returns false if key not exist.
+func getJSON(b *bolt.Bucket, key []byte, obj interface{}) (bool, error) {
+	v := b.Get(key)
+	if v == nil {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(v, obj); err != nil {
+		return false, fmt.Errorf("ошибка чтения записи %x: %w", key, err)
+	}
+	return true, nil
+}
+
+//  putJSON writes obj to bucket as json value of key.
+func putJSON(b *bolt.Bucket, key []byte, obj interface{}) error {
+	v, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("ошибка записи %x: %w", key, err)
+	}
+
+	return b.Put(key, v)
+}
+
+//  orderedKey returns key of owner index, ordered by creation time and id: owner id, unix microseconds, id.
+func orderedKey(ownerID uuid.UUID, createdAt time.Time, id uuid.UUID) []byte {
+	key := make([]byte, 2*len(uuid.UUID{})+8)
+	copy(key, ownerID[:])
+	binary.BigEndian.PutUint64(key[len(ownerID):], uint64(createdAt.UnixMicro()))
+	copy(key[len(ownerID)+8:], id[:])
+
+	return key
+}
+
+//  sequenceKey returns big-endian key of bucket sequence number, keys are ordered by sequence.
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+
+	return key
+}
@@ -0,0 +1,73 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/storagetest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return newTestStorage(t, DSNPrefix+filepath.Join(t.TempDir(), "shortener.db"))
+	})
+}
+
+func TestStorage_Reopen(t *testing.T) {
+	dsn := DSNPrefix + filepath.Join(t.TempDir(), "shortener.db")
+	ctx := context.Background()
+
+	user := model.User{ID: uuid.New()}
+	sht := model.ShortURL{
+		ID:        uuid.New(),
+		ShortID:   "reopen",
+		URL:       "https://reopen.test",
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	ctxFirst, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+	st, err := NewStorage(ctxFirst, finished, dsn)
+	require.NoError(t, err)
+
+	_, err = st.User().AddUser(ctx, user)
+	require.NoError(t, err)
+	_, err = st.URL().SaveURL(ctx, sht)
+	require.NoError(t, err)
+
+	cancel()
+	<-finished
+	st.Close()
+
+	reopened := newTestStorage(t, dsn)
+	got, err := reopened.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	require.Equal(t, sht.URL, got.URL)
+	require.Equal(t, sht.UserID, got.UserID)
+
+	exist, err := reopened.User().Exist(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, exist)
+}
+
+func newTestStorage(t *testing.T, dsn string) *Storage {
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan struct{})
+
+	st, err := NewStorage(ctx, finished, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		<-finished
+		st.Close()
+	})
+
+	return st
+}
@@ -0,0 +1,359 @@
+package bolt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ st.URLRepository = (*shortURLRepository)(nil)
+
+//  shortURLRepository implements URLRepository interface, provides actions with url records in bolt storage.
+type shortURLRepository struct {
+	db *bolt.DB
+}
+
+//  newShortURLRepository inits new url repository.
+func newShortURLRepository(db *bolt.DB) *shortURLRepository {
+	return &shortURLRepository{
+		db: db,
+	}
+}
+
+//  SaveURL saves url to database.
+func (r *shortURLRepository) SaveURL(_ context.Context, sht model.ShortURL) (model.ShortURL, error) {
+	dbObj, err := schema.NewURLFromCanonical(sht)
+	if err != nil {
+		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		return insertURL(tx, dbObj)
+	})
+	if err != nil {
+		return model.ShortURL{}, err
+	}
+
+	return sht, nil
+}
+
+//  SaveURLBatch saves list of urls in one transaction.
+//  If any url is not saved, no urls are saved.
+func (r *shortURLRepository) SaveURLBatch(_ context.Context, urls []model.ShortURL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		for _, sht := range urls {
+			dbObj, err := schema.NewURLFromCanonical(sht)
+			if err != nil {
+				return err
+			}
+
+			if err := insertURL(tx, dbObj); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("ошибка транзакции сохранения:%w", err)
+	}
+
+	return nil
+}
+
+//  GetURLBySrcURL selects not expired url from database by source url, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist or expired.
+func (r *shortURLRepository) GetURLBySrcURL(_ context.Context, url string) (model.ShortURL, error) {
+	var dbObj schema.ShortURL
+	err := r.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(bucketURLSrcURLs).Get([]byte(url))
+		if id == nil {
+			return shterrors.ErrNotFound
+		}
+
+		ok, err := getJSON(tx.Bucket(bucketURLs), id, &dbObj)
+		if err != nil {
+			return err
+		}
+		if !ok || dbObj.Expired(time.Now()) {
+			return shterrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.ShortURL{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetURL selects url from database by shortID, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist.
+func (r *shortURLRepository) GetURL(_ context.Context, shortID string) (model.ShortURL, error) {
+	var dbObj schema.ShortURL
+	err := r.db.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(bucketURLShortIDs).Get([]byte(shortID))
+		if id == nil {
+			return shterrors.ErrNotFound
+		}
+
+		ok, err := getJSON(tx.Bucket(bucketURLs), id, &dbObj)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return shterrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.ShortURL{}, err
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetUserURLList selects page of user urls by filter, ordered by creation time and id.
+//  Returns list of canonical ShortURL.
+func (r *shortURLRepository) GetUserURLList(_ context.Context, userID uuid.UUID, filter model.URLListFilter) ([]model.ShortURL, error) {
+	userURLs := make(schema.URLList, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		urls := tx.Bucket(bucketURLs)
+		c := tx.Bucket(bucketUserURLs).Cursor()
+
+		prefix := userID[:]
+		seek := prefix
+		var after []byte
+		if filter.After != nil {
+			after = orderedKey(userID, filter.After.CreatedAt, filter.After.ID)
+			seek = after
+		}
+
+		for k, v := c.Seek(seek); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if bytes.Equal(k, after) {
+				continue
+			}
+			if filter.Limit > 0 && len(userURLs) == filter.Limit {
+				return nil
+			}
+
+			var dbObj schema.ShortURL
+			if _, err := getJSON(urls, v, &dbObj); err != nil {
+				return err
+			}
+
+			sht, err := dbObj.ToCanonical()
+			if err != nil {
+				return err
+			}
+			if filter.Match(sht) {
+				userURLs = append(userURLs, dbObj)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userURLs.ToCanonical()
+}
+
+//  Exist checks that shortID exist in database.
+func (r *shortURLRepository) Exist(shortID string) (bool, error) {
+	exist := false
+	err := r.db.View(func(tx *bolt.Tx) error {
+		exist = tx.Bucket(bucketURLShortIDs).Get([]byte(shortID)) != nil
+		return nil
+	})
+
+	return exist, err
+}
+
+//  DeleteURLBatch marks user urls as deleted and saves done job in one transaction.
+//  Urls of another users are skipped.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job := model.NewDeleteJob(userID, shortIDList)
+	job.Finish(model.DeleteJobDone, "", job.CreatedAt)
+
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket(bucketURLs)
+		shortIDs := tx.Bucket(bucketURLShortIDs)
+
+		for _, shortID := range shortIDList {
+			id := shortIDs.Get([]byte(shortID))
+			if id == nil {
+				continue
+			}
+
+			var dbObj schema.ShortURL
+			if _, err := getJSON(urls, id, &dbObj); err != nil {
+				return err
+			}
+			if dbObj.UserID != userID || dbObj.IsDeleted {
+				continue
+			}
+
+			dbObj.IsDeleted = true
+			if err := putJSON(urls, id, dbObj); err != nil {
+				return err
+			}
+		}
+
+		return putJSON(tx.Bucket(bucketDeleteJobs), job.ID[:], job)
+	})
+	if err != nil {
+		return model.DeleteJob{}, fmt.Errorf("ошибка транзакции удаления:%w", err)
+	}
+
+	return job, nil
+}
+
+//  GetDeleteJob selects delete job by id.
+//  Returns shterrors.ErrDeleteJobNotFound if job not exist.
+func (r *shortURLRepository) GetDeleteJob(_ context.Context, jobID uuid.UUID) (model.DeleteJob, error) {
+	var job model.DeleteJob
+	err := r.db.View(func(tx *bolt.Tx) error {
+		ok, err := getJSON(tx.Bucket(bucketDeleteJobs), jobID[:], &job)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return shterrors.ErrDeleteJobNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	return job, nil
+}
+
+//  MarkExpired marks urls with expiration time before now as expired, returns list of marked shortIDs.
+func (r *shortURLRepository) MarkExpired(_ context.Context, now time.Time) ([]string, error) {
+	expired := make([]string, 0)
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		urls := tx.Bucket(bucketURLs)
+
+		toMark := make(map[string]schema.ShortURL)
+		err := urls.ForEach(func(k, v []byte) error {
+			var dbObj schema.ShortURL
+			if _, err := getJSON(urls, k, &dbObj); err != nil {
+				return err
+			}
+
+			if dbObj.IsExpired || dbObj.ExpiresAt.IsZero() || dbObj.ExpiresAt.After(now) {
+				return nil
+			}
+
+			dbObj.IsExpired = true
+			toMark[string(k)] = dbObj
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		//  bucket must not be changed in ForEach
+		for k, dbObj := range toMark {
+			if err := putJSON(urls, []byte(k), dbObj); err != nil {
+				return err
+			}
+			expired = append(expired, dbObj.ShortID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return expired, nil
+}
+
+//  GetCount returns count of stored, not deleted and not expired urls.
+func (r *shortURLRepository) GetCount() (int, error) {
+	now := time.Now()
+	count := 0
+	err := r.db.View(func(tx *bolt.Tx) error {
+		urls := tx.Bucket(bucketURLs)
+
+		return urls.ForEach(func(k, v []byte) error {
+			var dbObj schema.ShortURL
+			if _, err := getJSON(urls, k, &dbObj); err != nil {
+				return err
+			}
+
+			if !dbObj.IsDeleted && !dbObj.IsExpired && (dbObj.ExpiresAt.IsZero() || dbObj.ExpiresAt.After(now)) {
+				count++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+//  insertURL checks conflicts and writes new url with indexes in transaction.
+//  Source url of expired url is not a conflict, index is replaced by new url.
+func insertURL(tx *bolt.Tx, dbObj schema.ShortURL) error {
+	urls := tx.Bucket(bucketURLs)
+	shortIDs := tx.Bucket(bucketURLShortIDs)
+	srcURLs := tx.Bucket(bucketURLSrcURLs)
+
+	if shortIDs.Get([]byte(dbObj.ShortID)) != nil {
+		return &shterrors.ErrorConflictSaveURL{
+			Err:           errors.New("конфликт добавления записи, shortID уже существует"),
+			ExistShortURL: dbObj.ShortID,
+			Code:          shterrors.ConflictCodeAlias,
+		}
+	}
+
+	if id := srcURLs.Get([]byte(dbObj.URL)); id != nil {
+		var exist schema.ShortURL
+		if _, err := getJSON(urls, id, &exist); err != nil {
+			return err
+		}
+
+		if !exist.Expired(time.Now()) {
+			return &shterrors.ErrorConflictSaveURL{
+				Err:           errors.New("конфликт добавления записи, URL уже существует"),
+				ExistShortURL: exist.ShortID,
+				Code:          shterrors.ConflictCodeURL,
+			}
+		}
+	}
+
+	if tx.Bucket(bucketUsers).Get(dbObj.UserID[:]) == nil {
+		return errors.New("пользователь не найден")
+	}
+
+	id := dbObj.ID[:]
+	if err := putJSON(urls, id, dbObj); err != nil {
+		return err
+	}
+	if err := shortIDs.Put([]byte(dbObj.ShortID), id); err != nil {
+		return err
+	}
+	if err := srcURLs.Put([]byte(dbObj.URL), id); err != nil {
+		return err
+	}
+
+	return tx.Bucket(bucketUserURLs).Put(orderedKey(dbObj.UserID, dbObj.CreatedAt, dbObj.ID), id)
+}
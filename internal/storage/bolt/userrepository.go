@@ -0,0 +1,65 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+var _ storage.UserRepository = (*userRepository)(nil)
+
+//  userRepository implements UserRepository interface, provides actions with user records in bolt storage.
+type userRepository struct {
+	db *bolt.DB
+}
+
+//  newUserRepository inits new user repository.
+func newUserRepository(db *bolt.DB) *userRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+//  AddUser saves user to database.
+func (r *userRepository) AddUser(_ context.Context, user model.User) (model.User, error) {
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		users := tx.Bucket(bucketUsers)
+		if users.Get(user.ID[:]) != nil {
+			return errors.New("пользователь уже существует")
+		}
+
+		return users.Put(user.ID[:], []byte{})
+	})
+	if err != nil {
+		return model.User{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return user, nil
+}
+
+//  Exist checks that user is exist in database.
+func (r *userRepository) Exist(_ context.Context, userID uuid.UUID) (bool, error) {
+	exist := false
+	err := r.db.View(func(tx *bolt.Tx) error {
+		exist = tx.Bucket(bucketUsers).Get(userID[:]) != nil
+		return nil
+	})
+
+	return exist, err
+}
+
+//  GetCount returns count of stored users.
+func (r *userRepository) GetCount() (int, error) {
+	count := 0
+	err := r.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(bucketUsers).Stats().KeyN
+		return nil
+	})
+
+	return count, err
+}
@@ -0,0 +1,23 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+//  Cache is the interface that wraps methods of url cache, used by cached storage.
+type Cache interface {
+	//  Get returns cached url by shortID, false if url not cached.
+	Get(ctx context.Context, shortID string) (model.ShortURL, bool, error)
+
+	//  Set caches url by its shortID for ttl.
+	Set(ctx context.Context, sht model.ShortURL, ttl time.Duration) error
+
+	//  Delete removes urls from cache by shortIDs.
+	Delete(ctx context.Context, shortIDs ...string) error
+
+	//  Close releases cache resources.
+	Close() error
+}
@@ -0,0 +1,115 @@
+package cached
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+var _ Cache = (*LRU)(nil)
+
+//  LRU implements Cache interface, in-process cache with fixed size and least recently used eviction.
+type LRU struct {
+	size  int
+	items map[string]*list.Element
+	order *list.List
+	sync.Mutex
+}
+
+//  lruItem is entry of LRU cache.
+type lruItem struct {
+	sht       model.ShortURL
+	expiresAt time.Time
+}
+
+//  NewLRU inits new LRU cache for size urls.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &LRU{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+//  Get returns cached url by shortID, false if url not cached or cache entry expired.
+func (c *LRU) Get(_ context.Context, shortID string) (model.ShortURL, bool, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	el, ok := c.items[shortID]
+	if !ok {
+		return model.ShortURL{}, false, nil
+	}
+
+	item := el.Value.(*lruItem)
+	if !time.Now().Before(item.expiresAt) {
+		c.removeNoLock(el)
+		return model.ShortURL{}, false, nil
+	}
+
+	c.order.MoveToFront(el)
+	return item.sht, true, nil
+}
+
+//  Set caches url for ttl, evicts least recently used url if cache is full.
+func (c *LRU) Set(_ context.Context, sht model.ShortURL, ttl time.Duration) error {
+	c.Lock()
+	defer c.Unlock()
+
+	item := &lruItem{
+		sht:       sht,
+		expiresAt: time.Now().Add(ttl),
+	}
+
+	if el, ok := c.items[sht.ShortID]; ok {
+		el.Value = item
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[sht.ShortID] = c.order.PushFront(item)
+	if c.order.Len() > c.size {
+		c.removeNoLock(c.order.Back())
+	}
+
+	return nil
+}
+
+//  Delete removes urls from cache.
+func (c *LRU) Delete(_ context.Context, shortIDs ...string) error {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, v := range shortIDs {
+		if el, ok := c.items[v]; ok {
+			c.removeNoLock(el)
+		}
+	}
+	return nil
+}
+
+//  Len returns count of cached urls.
+func (c *LRU) Len() int {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.order.Len()
+}
+
+//  Close does nothing, in-process cache has no resources to release.
+func (c *LRU) Close() error {
+	return nil
+}
+
+//  removeNoLock removes cache element.
+func (c *LRU) removeNoLock(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*lruItem).sht.ShortID)
+}
@@ -0,0 +1,51 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+
+	require.NoError(t, c.Set(ctx, model.ShortURL{ShortID: "url1"}, time.Minute))
+	require.NoError(t, c.Set(ctx, model.ShortURL{ShortID: "url2"}, time.Minute))
+
+	//  url1 becomes recently used, url2 must be evicted
+	_, ok, err := c.Get(ctx, "url1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, model.ShortURL{ShortID: "url3"}, time.Minute))
+	assert.Equal(t, 2, c.Len())
+
+	_, ok, _ = c.Get(ctx, "url2")
+	assert.False(t, ok)
+	_, ok, _ = c.Get(ctx, "url1")
+	assert.True(t, ok)
+	_, ok, _ = c.Get(ctx, "url3")
+	assert.True(t, ok)
+
+	require.NoError(t, c.Delete(ctx, "url1", "url3"))
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_TTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10)
+
+	require.NoError(t, c.Set(ctx, model.ShortURL{ShortID: "url1"}, 10*time.Millisecond))
+	_, ok, _ := c.Get(ctx, "url1")
+	require.True(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+	_, ok, _ = c.Get(ctx, "url1")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,291 @@
+package cached
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+var _ Cache = (*RESPCache)(nil)
+
+const (
+	respKeyPrefix   = "shortener:url:" //  prefix of url keys in RESP server.
+	respPoolSize    = 10               //  max count of idle connections.
+	respDialTimeout = time.Second      //  timeout of connecting to RESP server.
+	respTimeout     = time.Second      //  timeout of command, if context has no deadline.
+)
+
+//  ErrRESPClosed returns if cache client is closed.
+var ErrRESPClosed = errors.New("клиент кэша закрыт")
+
+//  RESPCache implements Cache interface, stores urls in Redis-compatible server using RESP protocol.
+type RESPCache struct {
+	addr   string
+	pool   chan *respConn
+	closed bool
+	sync.RWMutex
+}
+
+//  respConn is connection to RESP server with buffered reader.
+type respConn struct {
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+//  respError is error reply of RESP server.
+type respError string
+
+func (e respError) Error() string {
+	return "ошибка сервера кэша: " + string(e)
+}
+
+//  NewRESPCache inits RESP cache client and checks connection with PING.
+func NewRESPCache(ctx context.Context, addr string) (*RESPCache, error) {
+	c := &RESPCache{
+		addr: addr,
+		pool: make(chan *respConn, respPoolSize),
+	}
+
+	if _, err := c.do(ctx, "PING"); err != nil {
+		return nil, fmt.Errorf("ошибка подключения к серверу кэша %v: %w", addr, err)
+	}
+	return c, nil
+}
+
+//  Get returns url by shortID from RESP server, false if url not cached.
+func (c *RESPCache) Get(ctx context.Context, shortID string) (model.ShortURL, bool, error) {
+	reply, err := c.do(ctx, "GET", respKeyPrefix+shortID)
+	if err != nil {
+		return model.ShortURL{}, false, err
+	}
+	if reply == nil {
+		return model.ShortURL{}, false, nil
+	}
+
+	data, ok := reply.([]byte)
+	if !ok {
+		return model.ShortURL{}, false, fmt.Errorf("неожиданный ответ сервера кэша: %v", reply)
+	}
+
+	sht := model.ShortURL{}
+	if err := json.Unmarshal(data, &sht); err != nil {
+		return model.ShortURL{}, false, fmt.Errorf("ошибка чтения из кэша: %w", err)
+	}
+	return sht, true, nil
+}
+
+//  Set stores url to RESP server with ttl.
+func (c *RESPCache) Set(ctx context.Context, sht model.ShortURL, ttl time.Duration) error {
+	data, err := json.Marshal(sht)
+	if err != nil {
+		return fmt.Errorf("ошибка записи в кэш: %w", err)
+	}
+
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+
+	_, err = c.do(ctx, "SET", respKeyPrefix+sht.ShortID, string(data), "PX", strconv.FormatInt(ms, 10))
+	return err
+}
+
+//  Delete removes urls from RESP server.
+func (c *RESPCache) Delete(ctx context.Context, shortIDs ...string) error {
+	if len(shortIDs) == 0 {
+		return nil
+	}
+
+	args := make([]string, 0, len(shortIDs)+1)
+	args = append(args, "DEL")
+	for _, v := range shortIDs {
+		args = append(args, respKeyPrefix+v)
+	}
+
+	_, err := c.do(ctx, args...)
+	return err
+}
+
+//  Close closes idle connections, connections in use are closed on return.
+func (c *RESPCache) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.closed = true
+	for {
+		select {
+		case cn := <-c.pool:
+			cn.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+//  do sends command to RESP server and returns reply.
+//  Reply is nil, []byte, string, int64 or []interface{}.
+func (c *RESPCache) do(ctx context.Context, args ...string) (interface{}, error) {
+	cn, err := c.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(respTimeout)
+	}
+	if err := cn.conn.SetDeadline(deadline); err != nil {
+		cn.conn.Close()
+		return nil, err
+	}
+
+	if _, err := cn.conn.Write(encodeRESPCommand(args...)); err != nil {
+		cn.conn.Close()
+		return nil, err
+	}
+
+	reply, err := readRESPReply(cn.rd)
+	if err != nil {
+		var rErr respError
+		if !errors.As(err, &rErr) {
+			//  connection state is unknown after io error
+			cn.conn.Close()
+			return nil, err
+		}
+	}
+
+	c.putConn(cn)
+	return reply, err
+}
+
+//  getConn returns idle connection from pool or dials new.
+func (c *RESPCache) getConn(ctx context.Context) (*respConn, error) {
+	c.RLock()
+	closed := c.closed
+	c.RUnlock()
+
+	if closed {
+		return nil, ErrRESPClosed
+	}
+
+	select {
+	case cn := <-c.pool:
+		return cn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: respDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &respConn{conn: conn, rd: bufio.NewReader(conn)}, nil
+}
+
+//  putConn returns connection to pool, closes connection if pool is full or client closed.
+func (c *RESPCache) putConn(cn *respConn) {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.closed {
+		cn.conn.Close()
+		return
+	}
+
+	select {
+	case c.pool <- cn:
+	default:
+		cn.conn.Close()
+	}
+}
+
+//  encodeRESPCommand encodes command as RESP array of bulk strings.
+func encodeRESPCommand(args ...string) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+
+	for _, v := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(v)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, v...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+//  readRESPReply reads one RESP reply.
+func readRESPReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := readRESPLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("пустой ответ сервера кэша")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, respError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+
+		arr := make([]interface{}, n)
+		for i := range arr {
+			if arr[i], err = readRESPReply(rd); err != nil {
+				return nil, err
+			}
+		}
+		return arr, nil
+	}
+
+	return nil, fmt.Errorf("неизвестный тип ответа сервера кэша: %q", line[0])
+}
+
+//  readRESPLine reads line without CRLF.
+func readRESPLine(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("неверный формат ответа сервера кэша")
+	}
+	return line[:len(line)-2], nil
+}
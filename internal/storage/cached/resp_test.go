@@ -0,0 +1,72 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESPCache(t *testing.T) {
+	ctx := context.Background()
+	srv := startTestRESPServer(t)
+
+	c, err := NewRESPCache(ctx, srv.Addr())
+	require.NoError(t, err)
+	defer c.Close()
+
+	sht := model.ShortURL{
+		ID:        uuid.New(),
+		ShortID:   "1xQ6p+JI",
+		URL:       "https://practicum.yandex.ru/",
+		UserID:    uuid.New(),
+		CreatedAt: time.Now().Truncate(time.Microsecond).UTC(),
+	}
+
+	_, ok, err := c.Get(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Set(ctx, sht, time.Minute))
+	cached, ok, err := c.Get(ctx, sht.ShortID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, sht, cached)
+
+	require.NoError(t, c.Delete(ctx, sht.ShortID))
+	_, ok, err = c.Get(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	//  ttl
+	require.NoError(t, c.Set(ctx, sht, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+	_, ok, err = c.Get(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestRESPCache_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewRESPCache(ctx, "127.0.0.1:1")
+	require.Error(t, err)
+
+	srv := startTestRESPServer(t)
+	c, err := NewRESPCache(ctx, srv.Addr())
+	require.NoError(t, err)
+
+	//  server error reply keeps connection usable
+	_, err = c.do(ctx, "UNKNOWN")
+	require.Error(t, err)
+	_, err = c.do(ctx, "PING")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Close())
+	_, _, err = c.Get(ctx, "url")
+	require.ErrorIs(t, err, ErrRESPClosed)
+}
@@ -0,0 +1,112 @@
+package cached
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+//  testRESPServer is in-memory RESP server, supports PING, GET, SET with PX and DEL commands.
+type testRESPServer struct {
+	listener net.Listener
+	data     map[string]testRESPValue
+	sync.Mutex
+}
+
+type testRESPValue struct {
+	val       string
+	expiresAt time.Time
+}
+
+//  startTestRESPServer runs in-memory RESP server on random port, stops it on test cleanup.
+func startTestRESPServer(t *testing.T) *testRESPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testRESPServer{
+		listener: listener,
+		data:     make(map[string]testRESPValue),
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *testRESPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *testRESPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+
+	for {
+		reply, err := readRESPReply(rd)
+		if err != nil {
+			return
+		}
+
+		cmd := make([]string, 0)
+		for _, v := range reply.([]interface{}) {
+			cmd = append(cmd, string(v.([]byte)))
+		}
+
+		if _, err := conn.Write([]byte(s.exec(cmd))); err != nil {
+			return
+		}
+	}
+}
+
+func (s *testRESPServer) exec(cmd []string) string {
+	s.Lock()
+	defer s.Unlock()
+
+	switch strings.ToUpper(cmd[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		v, ok := s.data[cmd[1]]
+		if !ok || (!v.expiresAt.IsZero() && time.Now().After(v.expiresAt)) {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(v.val)) + "\r\n" + v.val + "\r\n"
+	case "SET":
+		v := testRESPValue{val: cmd[2]}
+		if len(cmd) == 5 && strings.ToUpper(cmd[3]) == "PX" {
+			ms, err := strconv.Atoi(cmd[4])
+			if err != nil {
+				return "-ERR value is not an integer\r\n"
+			}
+			v.expiresAt = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		s.data[cmd[1]] = v
+		return "+OK\r\n"
+	case "DEL":
+		count := 0
+		for _, k := range cmd[1:] {
+			if _, ok := s.data[k]; ok {
+				delete(s.data, k)
+				count++
+			}
+		}
+		return ":" + strconv.Itoa(count) + "\r\n"
+	}
+
+	return "-ERR unknown command '" + cmd[0] + "'\r\n"
+}
@@ -0,0 +1,56 @@
+package cached
+
+import (
+	"errors"
+	"time"
+
+	st "github.com/atrush/pract_01.git/internal/storage"
+)
+
+var _ st.Storage = (*Storage)(nil)
+
+//  DefCacheTTL is default time to live of cached url.
+const DefCacheTTL = 5 * time.Minute
+
+//  Storage implements Storage interface, read-through cache decorator of any storage.
+//  Urls are cached by shortID on GetURL and invalidated on delete and expiration.
+type Storage struct {
+	st.Storage
+	cache   Cache
+	urlRepo *shortURLRepository
+}
+
+//  NewStorage wraps storage with cache, zero ttl is replaced with default.
+func NewStorage(db st.Storage, cache Cache, ttl time.Duration) (*Storage, error) {
+	if db == nil {
+		return nil, errors.New("нельзя использовать nil хранилище")
+	}
+	if cache == nil {
+		return nil, errors.New("нельзя использовать nil кэш")
+	}
+	if ttl <= 0 {
+		ttl = DefCacheTTL
+	}
+
+	return &Storage{
+		Storage: db,
+		cache:   cache,
+		urlRepo: newShortURLRepository(db.URL(), cache, ttl),
+	}, nil
+}
+
+//  URL returns cached urls repository.
+func (s *Storage) URL() st.URLRepository {
+	return s.urlRepo
+}
+
+//  Unwrap returns wrapped storage.
+func (s *Storage) Unwrap() st.Storage {
+	return s.Storage
+}
+
+//  Close closes cache and wrapped storage.
+func (s *Storage) Close() {
+	s.cache.Close()
+	s.Storage.Close()
+}
@@ -0,0 +1,160 @@
+package cached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.Background()
+	srv := startTestRESPServer(t)
+
+	respCache, err := NewRESPCache(ctx, srv.Addr())
+	require.NoError(t, err)
+
+	caches := map[string]Cache{
+		"lru":  NewLRU(100),
+		"resp": respCache,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			db, err := infile.NewFileStorage(context.Background(), "")
+			require.NoError(t, err)
+
+			s, err := NewStorage(db, cache, time.Minute)
+			require.NoError(t, err)
+			defer s.Close()
+
+			userID := uuid.New()
+			_, err = s.User().AddUser(ctx, model.User{ID: userID})
+			require.NoError(t, err)
+
+			sht, err := s.URL().SaveURL(ctx, model.NewShortURL("https://practicum.yandex.ru/", userID, model.WithAlias("url"+name)))
+			require.NoError(t, err)
+			expiring, err := s.URL().SaveURL(ctx, model.NewShortURL("https://practicum.yandex.ru/exp", userID,
+				model.WithAlias("exp"+name), model.WithExpiresAt(time.Now().Add(time.Hour))))
+			require.NoError(t, err)
+
+			//  not found urls are not cached
+			_, err = s.URL().GetURL(ctx, "notfound")
+			require.ErrorIs(t, err, shterrors.ErrNotFound)
+			_, ok, err := cache.Get(ctx, "notfound")
+			require.NoError(t, err)
+			assert.False(t, ok)
+
+			//  read-through
+			got, err := s.URL().GetURL(ctx, sht.ShortID)
+			require.NoError(t, err)
+			assert.Equal(t, sht.URL, got.URL)
+			_, ok, err = cache.Get(ctx, sht.ShortID)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			//  delete by another user does not delete url
+			_, err = s.URL().DeleteURLBatch(uuid.New(), sht.ShortID)
+			require.NoError(t, err)
+			got, err = s.URL().GetURL(ctx, sht.ShortID)
+			require.NoError(t, err)
+			assert.False(t, got.IsDeleted)
+
+			//  delete by owner marks cached url
+			_, err = s.URL().DeleteURLBatch(userID, sht.ShortID)
+			require.NoError(t, err)
+			got, err = s.URL().GetURL(ctx, sht.ShortID)
+			require.NoError(t, err)
+			assert.True(t, got.IsDeleted)
+
+			//  expired urls are removed from cache
+			_, err = s.URL().GetURL(ctx, expiring.ShortID)
+			require.NoError(t, err)
+			expired, err := s.URL().MarkExpired(ctx, time.Now().Add(2*time.Hour))
+			require.NoError(t, err)
+			require.Equal(t, []string{expiring.ShortID}, expired)
+			_, ok, err = cache.Get(ctx, expiring.ShortID)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+//  asyncDeleteStorage deletes urls only on finish, as storages with async delete queue.
+type asyncDeleteStorage struct {
+	st.Storage
+	urlRepo *asyncDeleteURLRepository
+}
+
+//  URL returns url repository with async deleting.
+func (s *asyncDeleteStorage) URL() st.URLRepository {
+	return s.urlRepo
+}
+
+//  asyncDeleteURLRepository saves delete jobs and deletes urls on finish.
+type asyncDeleteURLRepository struct {
+	st.URLRepository
+	jobs []model.DeleteJob
+}
+
+//  DeleteURLBatch saves pending job without deleting urls.
+func (r *asyncDeleteURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job := model.NewDeleteJob(userID, shortIDList)
+	r.jobs = append(r.jobs, job)
+
+	return job, nil
+}
+
+//  finish deletes urls of saved jobs.
+func (r *asyncDeleteURLRepository) finish(t *testing.T) {
+	for _, job := range r.jobs {
+		_, err := r.URLRepository.DeleteURLBatch(job.UserID, job.ShortIDs...)
+		require.NoError(t, err)
+	}
+	r.jobs = nil
+}
+
+func TestStorage_AsyncDelete(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := infile.NewFileStorage(ctx, "")
+	require.NoError(t, err)
+	asyncDB := &asyncDeleteStorage{Storage: db, urlRepo: &asyncDeleteURLRepository{URLRepository: db.URL()}}
+
+	s, err := NewStorage(asyncDB, NewLRU(100), time.Minute)
+	require.NoError(t, err)
+	defer s.Close()
+
+	userID := uuid.New()
+	_, err = s.User().AddUser(ctx, model.User{ID: userID})
+	require.NoError(t, err)
+
+	cachedURL, err := s.URL().SaveURL(ctx, model.NewShortURL("https://practicum.yandex.ru/cached", userID, model.WithAlias("cached")))
+	require.NoError(t, err)
+	notCachedURL, err := s.URL().SaveURL(ctx, model.NewShortURL("https://practicum.yandex.ru/notcached", userID, model.WithAlias("notcached")))
+	require.NoError(t, err)
+
+	_, err = s.URL().GetURL(ctx, cachedURL.ShortID)
+	require.NoError(t, err)
+
+	//  urls are read as deleted before job is finished
+	_, err = s.URL().DeleteURLBatch(userID, cachedURL.ShortID, notCachedURL.ShortID)
+	require.NoError(t, err)
+	for _, shortID := range []string{cachedURL.ShortID, notCachedURL.ShortID} {
+		got, err := s.URL().GetURL(ctx, shortID)
+		require.NoError(t, err)
+		assert.True(t, got.IsDeleted, shortID)
+	}
+
+	asyncDB.urlRepo.finish(t)
+	got, err := s.URL().GetURL(ctx, notCachedURL.ShortID)
+	require.NoError(t, err)
+	assert.True(t, got.IsDeleted)
+}
@@ -0,0 +1,123 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/model"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+)
+
+var _ st.URLRepository = (*shortURLRepository)(nil)
+
+//  shortURLRepository implements URLRepository interface, caches urls of wrapped repository.
+//  Cache errors are logged and do not break requests, wrapped repository is used instead.
+type shortURLRepository struct {
+	st.URLRepository
+	cache Cache
+	ttl   time.Duration
+}
+
+//  newShortURLRepository inits new cached url repository.
+func newShortURLRepository(repo st.URLRepository, cache Cache, ttl time.Duration) *shortURLRepository {
+	return &shortURLRepository{
+		URLRepository: repo,
+		cache:         cache,
+		ttl:           ttl,
+	}
+}
+
+//  GetURL returns url from cache, or reads from wrapped repository and caches it.
+//  Url cache time is limited by url expiration time.
+func (r *shortURLRepository) GetURL(ctx context.Context, shortID string) (model.ShortURL, error) {
+	sht, ok, err := r.cache.Get(ctx, shortID)
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "ошибка чтения из кэша", "error", err.Error())
+	}
+	if ok {
+		return sht, nil
+	}
+
+	sht, err = r.URLRepository.GetURL(ctx, shortID)
+	if err != nil {
+		return sht, err
+	}
+
+	r.set(ctx, sht)
+	return sht, nil
+}
+
+//  DeleteURLBatch marks urls as deleted in wrapped repository,
+//  urls of user are cached as deleted at once, without waiting async deleting.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job, err := r.URLRepository.DeleteURLBatch(userID, shortIDList...)
+	if err != nil {
+		return job, err
+	}
+
+	ctx := context.Background()
+	for _, v := range shortIDList {
+		r.markDeleted(ctx, userID, v)
+	}
+
+	return job, nil
+}
+
+//  markDeleted caches url of user as deleted. Not cached url is read from wrapped repository,
+//  so url is not cached as not deleted by GetURL until async deleting ends.
+func (r *shortURLRepository) markDeleted(ctx context.Context, userID uuid.UUID, shortID string) {
+	sht, ok, err := r.cache.Get(ctx, shortID)
+	if err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "ошибка чтения из кэша", "error", err.Error())
+	}
+	if !ok {
+		if sht, err = r.URLRepository.GetURL(ctx, shortID); err != nil {
+			r.delete(ctx, shortID)
+			return
+		}
+	}
+
+	if sht.UserID != userID {
+		return
+	}
+
+	sht.IsDeleted = true
+	r.set(ctx, sht)
+}
+
+//  MarkExpired marks expired urls in wrapped repository and removes them from cache.
+func (r *shortURLRepository) MarkExpired(ctx context.Context, now time.Time) ([]string, error) {
+	expired, err := r.URLRepository.MarkExpired(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	r.delete(ctx, expired...)
+	return expired, nil
+}
+
+//  set caches url, time to live is limited by url expiration time.
+func (r *shortURLRepository) set(ctx context.Context, sht model.ShortURL) {
+	ttl := r.ttl
+	if !sht.ExpiresAt.IsZero() {
+		if untilExpired := time.Until(sht.ExpiresAt); untilExpired < ttl {
+			ttl = untilExpired
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	if err := r.cache.Set(ctx, sht, ttl); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "ошибка записи в кэш", "error", err.Error())
+	}
+}
+
+//  delete removes urls from cache.
+func (r *shortURLRepository) delete(ctx context.Context, shortIDs ...string) {
+	if err := r.cache.Delete(ctx, shortIDs...); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "ошибка удаления из кэша", "error", err.Error())
+	}
+}
@@ -0,0 +1,380 @@
+//  Package deletequeue provides bounded async queue of url delete jobs with batching,
+//  retries with exponential backoff and dead-letter file for urls that could not be deleted.
+//  Jobs are persisted by storage, so pending jobs of previous run are passed to queue on start.
+package deletequeue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/google/uuid"
+)
+
+//  Default queue params.
+const (
+	DefSize       = 10000                  //  max count of urls in queue
+	DefBatch      = 100                    //  max count of urls deleted in one batch
+	DefMaxRetries = 5                      //  count of retries of failed batch
+	DefRetryBase  = 100 * time.Millisecond //  delay before first retry, doubles on every retry
+	DefRetryMax   = 5 * time.Second        //  max delay between retries
+)
+
+type (
+	//  Processor processes delete jobs in storage.
+	Processor interface {
+		//  DeleteJobs marks urls of jobs as deleted and jobs as done in one transaction.
+		DeleteJobs(jobs []model.DeleteJob) error
+
+		//  FailJobs marks jobs as failed, urls of jobs are not deleted after all retries.
+		FailJobs(jobs []model.DeleteJob, deleteErr error) error
+	}
+
+	//  Option sets optional queue param.
+	Option func(q *Queue)
+
+	//  Queue is bounded queue of delete jobs, processed by single worker.
+	Queue struct {
+		jobs       chan model.DeleteJob
+		pending    []model.DeleteJob
+		processor  Processor
+		asyncEnded chan struct{}
+		done       chan struct{}
+		closed     bool
+		logger     *slog.Logger
+		sync.Mutex
+
+		size           int
+		batch          int
+		maxRetries     int
+		retryBase      time.Duration
+		retryMax       time.Duration
+		deadLetterPath string
+
+		depth        int64
+		deleted      int64
+		rejected     int64
+		retries      int64
+		deadLettered int64
+	}
+
+	//  deadLetter is dead-letter file record.
+	deadLetter struct {
+		JobID    uuid.UUID `json:"job_id"`
+		UserID   uuid.UUID `json:"user_id"`
+		ShortID  string    `json:"short_id"`
+		Error    string    `json:"error"`
+		FailedAt time.Time `json:"failed_at"`
+	}
+)
+
+//  WithSize sets max count of urls in queue, not positive size is ignored.
+func WithSize(size int) Option {
+	return func(q *Queue) {
+		if size > 0 {
+			q.size = size
+		}
+	}
+}
+
+//  WithRetry sets count of retries and backoff delays of failed batch.
+func WithRetry(maxRetries int, base time.Duration, max time.Duration) Option {
+	return func(q *Queue) {
+		q.maxRetries = maxRetries
+		q.retryBase = base
+		q.retryMax = max
+	}
+}
+
+//  WithDeadLetterFile sets file for urls not deleted after all retries, if empty urls are logged.
+func WithDeadLetterFile(path string) Option {
+	return func(q *Queue) {
+		q.deadLetterPath = path
+	}
+}
+
+//  WithPending sets jobs not processed in previous run, they are processed before new jobs.
+func WithPending(jobs []model.DeleteJob) Option {
+	return func(q *Queue) {
+		q.pending = append(q.pending, jobs...)
+	}
+}
+
+//  WithLogger sets logger of delete errors.
+func WithLogger(logger *slog.Logger) Option {
+	return func(q *Queue) {
+		q.logger = logger
+	}
+}
+
+//  New inits new queue and runs worker. On context done queue is closed, worker processes
+//  remaining jobs and sends signal to asyncEnded. Delete errors are logged by logger from context.
+func New(ctx context.Context, processor Processor, asyncEnded chan struct{}, opts ...Option) *Queue {
+	q := &Queue{
+		processor:  processor,
+		asyncEnded: asyncEnded,
+		done:       make(chan struct{}),
+		logger:     logging.FromContext(ctx),
+		size:       DefSize,
+		batch:      DefBatch,
+		maxRetries: DefMaxRetries,
+		retryBase:  DefRetryBase,
+		retryMax:   DefRetryMax,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	//  every job contains at least one url, so queue of size jobs never blocks
+	q.jobs = make(chan model.DeleteJob, q.size)
+	for _, job := range q.pending {
+		q.depth += int64(len(job.ShortIDs))
+	}
+
+	q.waitAsync(ctx)
+	q.initWorker()
+
+	return q
+}
+
+//  Add adds job to queue without blocking.
+//  Space for urls of job is reserved under queue lock, persist is called without lock,
+//  reservation is released if persist failed. Job is added only if persisted.
+//  If queue is closed while persisting, persisted job stays pending and is processed on next start.
+//  Returns shterrors.ErrDeleteQueueFull if queue has no space for urls of job,
+//  shterrors.ErrDeleteListTooLarge if job has more urls than queue size.
+func (q *Queue) Add(job model.DeleteJob, persist func() error) error {
+	count := int64(len(job.ShortIDs))
+	if count == 0 {
+		return nil
+	}
+	if count > int64(q.size) {
+		return fmt.Errorf("%w: ссылок %v, размер очереди %v", shterrors.ErrDeleteListTooLarge, count, q.size)
+	}
+
+	if err := q.reserve(count); err != nil {
+		return err
+	}
+
+	if persist != nil {
+		if err := persist(); err != nil {
+			atomic.AddInt64(&q.depth, -count)
+			return err
+		}
+	}
+
+	q.Lock()
+	defer q.Unlock()
+
+	if q.closed {
+		atomic.AddInt64(&q.depth, -count)
+		return nil
+	}
+
+	//  reserved depth never exceeds size, so queue of size jobs never blocks
+	q.jobs <- job
+	return nil
+}
+
+//  reserve reserves space for count of urls in queue.
+func (q *Queue) reserve(count int64) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.closed {
+		return fmt.Errorf("%w: очередь закрыта", shterrors.ErrDeleteQueueFull)
+	}
+
+	if atomic.LoadInt64(&q.depth)+count > int64(q.size) {
+		atomic.AddInt64(&q.rejected, count)
+		return shterrors.ErrDeleteQueueFull
+	}
+
+	atomic.AddInt64(&q.depth, count)
+	return nil
+}
+
+//  Stats returns queue depth and counters.
+func (q *Queue) Stats() model.DeleteQueueStats {
+	return model.DeleteQueueStats{
+		Depth:        int(atomic.LoadInt64(&q.depth)),
+		Capacity:     q.size,
+		Deleted:      atomic.LoadInt64(&q.deleted),
+		Rejected:     atomic.LoadInt64(&q.rejected),
+		Retries:      atomic.LoadInt64(&q.retries),
+		DeadLettered: atomic.LoadInt64(&q.deadLettered),
+	}
+}
+
+//  waitAsync closes queue on context done.
+func (q *Queue) waitAsync(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+
+		q.Lock()
+		defer q.Unlock()
+		q.closed = true
+		close(q.done)
+		close(q.jobs)
+	}()
+}
+
+//  initWorker runs single worker, that processes pending jobs, then takes available jobs
+//  from queue up to batch size of urls and processes them.
+func (q *Queue) initWorker() {
+	go func() {
+		batch := make([]model.DeleteJob, 0)
+		urls := 0
+		for _, job := range q.pending {
+			if urls > 0 && urls+len(job.ShortIDs) > q.batch {
+				q.process(batch)
+				batch, urls = batch[:0], 0
+			}
+			batch = append(batch, job)
+			urls += len(job.ShortIDs)
+		}
+		if len(batch) > 0 {
+			q.process(batch)
+		}
+		q.pending = nil
+
+		for job := range q.jobs {
+			batch = append(batch[:0], job)
+			urls = len(job.ShortIDs)
+
+		fill:
+			for urls < q.batch {
+				select {
+				case job, ok := <-q.jobs:
+					if !ok {
+						break fill
+					}
+					batch = append(batch, job)
+					urls += len(job.ShortIDs)
+				default:
+					break fill
+				}
+			}
+
+			q.process(batch)
+		}
+
+		q.asyncEnded <- struct{}{}
+	}()
+}
+
+//  process deletes batch of jobs, retries with exponential backoff on error.
+//  If all retries failed, jobs are deleted one by one, failed jobs are marked as failed and urls written to dead-letter file.
+//  If queue is closed while waiting retry, jobs stay pending and are processed on next start.
+func (q *Queue) process(batch []model.DeleteJob) {
+	urls := 0
+	for _, job := range batch {
+		urls += len(job.ShortIDs)
+	}
+	defer atomic.AddInt64(&q.depth, -int64(urls))
+
+	delay := q.retryBase
+	for attempt := 0; ; attempt++ {
+		err := q.processor.DeleteJobs(batch)
+		if err == nil {
+			atomic.AddInt64(&q.deleted, int64(urls))
+			return
+		}
+
+		if attempt >= q.maxRetries {
+			q.isolate(batch, err)
+			return
+		}
+		q.logger.Warn("ошибка удаления URL", "attempt", attempt+1, "max_attempts", q.maxRetries+1, "error", err.Error())
+
+		select {
+		case <-time.After(delay):
+		case <-q.done:
+			q.logger.Info("очередь удаления закрыта до повтора, задачи будут обработаны при следующем запуске", "jobs", len(batch))
+			return
+		}
+		atomic.AddInt64(&q.retries, 1)
+
+		delay *= 2
+		if delay > q.retryMax {
+			delay = q.retryMax
+		}
+	}
+}
+
+//  isolate deletes jobs of failed batch one by one, so one failing job does not fail jobs of other users.
+func (q *Queue) isolate(batch []model.DeleteJob, batchErr error) {
+	if len(batch) == 1 {
+		q.fail(batch, len(batch[0].ShortIDs), batchErr)
+		return
+	}
+
+	for _, job := range batch {
+		jobs := []model.DeleteJob{job}
+		if err := q.processor.DeleteJobs(jobs); err != nil {
+			q.fail(jobs, len(job.ShortIDs), err)
+			continue
+		}
+		atomic.AddInt64(&q.deleted, int64(len(job.ShortIDs)))
+	}
+}
+
+//  fail marks jobs as failed and writes urls to dead-letter file, logs urls if file not set or not written.
+func (q *Queue) fail(batch []model.DeleteJob, urls int, deleteErr error) {
+	atomic.AddInt64(&q.deadLettered, int64(urls))
+	q.logger.Error("URL не удалены", "urls", urls, "error", deleteErr.Error())
+
+	if err := q.processor.FailJobs(batch, deleteErr); err != nil {
+		q.logger.Error("ошибка сохранения статуса задач удаления", "error", err.Error())
+	}
+
+	if q.deadLetterPath != "" {
+		err := appendDeadLetters(q.deadLetterPath, batch, deleteErr)
+		if err == nil {
+			return
+		}
+		q.logger.Error("ошибка записи в файл неудаленных URL", "error", err.Error())
+	}
+
+	for _, job := range batch {
+		for _, v := range job.ShortIDs {
+			q.logger.Error("не удален URL", "short_id", v, "user_id", job.UserID, "job_id", job.ID)
+		}
+	}
+}
+
+//  appendDeadLetters appends urls of jobs to dead-letter file.
+func appendDeadLetters(path string, batch []model.DeleteJob, deleteErr error) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := time.Now().UTC()
+	enc := json.NewEncoder(file)
+	for _, job := range batch {
+		for _, v := range job.ShortIDs {
+			record := deadLetter{
+				JobID:    job.ID,
+				UserID:   job.UserID,
+				ShortID:  v,
+				Error:    deleteErr.Error(),
+				FailedAt: now,
+			}
+			if err := enc.Encode(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return file.Sync()
+}
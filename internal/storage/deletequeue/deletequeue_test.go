@@ -0,0 +1,255 @@
+package deletequeue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//  recorder is Processor, that fails first failures calls and records deleted and failed jobs.
+type recorder struct {
+	sync.Mutex
+	failures int
+	calls    int
+	deleted  []model.DeleteJob
+	failed   []model.DeleteJob
+	block    chan struct{}
+	failJob  uuid.UUID //  id of job, that fails every batch containing it
+}
+
+func (r *recorder) DeleteJobs(jobs []model.DeleteJob) error {
+	if r.block != nil {
+		<-r.block
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.calls++
+	if r.calls <= r.failures {
+		return errors.New("бд недоступна")
+	}
+	for _, job := range jobs {
+		if job.ID == r.failJob {
+			return errors.New("ошибка удаления задачи")
+		}
+	}
+	r.deleted = append(r.deleted, jobs...)
+	return nil
+}
+
+func (r *recorder) FailJobs(jobs []model.DeleteJob, _ error) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.failed = append(r.failed, jobs...)
+	return nil
+}
+
+func (r *recorder) deletedCount() int {
+	r.Lock()
+	defer r.Unlock()
+
+	count := 0
+	for _, job := range r.deleted {
+		count += len(job.ShortIDs)
+	}
+	return count
+}
+
+func TestQueue_Retry(t *testing.T) {
+	rec := &recorder{failures: 2}
+	q, stop := newTestQueue(t, rec, WithRetry(3, time.Millisecond, 5*time.Millisecond))
+
+	require.NoError(t, q.Add(model.NewDeleteJob(uuid.New(), []string{"a", "b", "c"}), nil))
+
+	require.Eventually(t, func() bool { return rec.deletedCount() == 3 }, time.Second, time.Millisecond)
+	stop()
+
+	stats := q.Stats()
+	assert.Equal(t, int64(3), stats.Deleted)
+	assert.Equal(t, int64(2), stats.Retries)
+	assert.Equal(t, int64(0), stats.DeadLettered)
+	assert.Equal(t, 0, stats.Depth)
+}
+
+func TestQueue_DeadLetter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	rec := &recorder{failures: 100}
+	q, stop := newTestQueue(t, rec, WithRetry(2, time.Millisecond, time.Millisecond), WithDeadLetterFile(path))
+
+	job := model.NewDeleteJob(uuid.New(), []string{"a", "b"})
+	require.NoError(t, q.Add(job, nil))
+
+	require.Eventually(t, func() bool { return q.Stats().DeadLettered == 2 }, time.Second, time.Millisecond)
+	stop()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var record deadLetter
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, job.ID, record.JobID)
+	assert.Equal(t, job.UserID, record.UserID)
+	assert.Equal(t, "a", record.ShortID)
+	assert.NotEmpty(t, record.Error)
+	assert.Equal(t, int64(2), q.Stats().Retries)
+
+	require.Len(t, rec.failed, 1)
+	assert.Equal(t, job.ID, rec.failed[0].ID)
+}
+
+func TestQueue_IsolateFailedJob(t *testing.T) {
+	bad := model.NewDeleteJob(uuid.New(), []string{"a"})
+	good := model.NewDeleteJob(uuid.New(), []string{"b", "c"})
+
+	rec := &recorder{failJob: bad.ID}
+	//  pending jobs are processed in one batch
+	q, stop := newTestQueue(t, rec, WithRetry(1, time.Millisecond, time.Millisecond), WithPending([]model.DeleteJob{bad, good}))
+	require.Eventually(t, func() bool { return q.Stats().Depth == 0 }, time.Second, time.Millisecond)
+	stop()
+
+	require.Len(t, rec.deleted, 1)
+	assert.Equal(t, good.ID, rec.deleted[0].ID, "задача другого пользователя удалена")
+	require.Len(t, rec.failed, 1)
+	assert.Equal(t, bad.ID, rec.failed[0].ID)
+
+	stats := q.Stats()
+	assert.Equal(t, int64(2), stats.Deleted)
+	assert.Equal(t, int64(1), stats.DeadLettered)
+}
+
+func TestQueue_Full(t *testing.T) {
+	rec := &recorder{block: make(chan struct{})}
+	q, stop := newTestQueue(t, rec, WithSize(3))
+
+	userID := uuid.New()
+	require.NoError(t, q.Add(model.NewDeleteJob(userID, []string{"a"}), nil))
+	//  wait worker takes first job and blocks on delete
+	require.Eventually(t, func() bool { return len(q.jobs) == 0 }, time.Second, time.Millisecond)
+
+	require.NoError(t, q.Add(model.NewDeleteJob(userID, []string{"b", "c"}), nil))
+
+	persisted := false
+	err := q.Add(model.NewDeleteJob(userID, []string{"d"}), func() error {
+		persisted = true
+		return nil
+	})
+	require.ErrorIs(t, err, shterrors.ErrDeleteQueueFull)
+	assert.False(t, persisted, "отклоненная задача сохранена")
+
+	stats := q.Stats()
+	assert.Equal(t, 3, stats.Depth)
+	assert.Equal(t, 3, stats.Capacity)
+	assert.Equal(t, int64(1), stats.Rejected)
+
+	close(rec.block)
+	stop()
+
+	assert.Equal(t, 3, rec.deletedCount())
+	require.ErrorIs(t, q.Add(model.NewDeleteJob(userID, []string{"f"}), nil), shterrors.ErrDeleteQueueFull)
+}
+
+func TestQueue_TooLarge(t *testing.T) {
+	rec := &recorder{}
+	q, stop := newTestQueue(t, rec, WithSize(2))
+
+	//  job larger than queue is never accepted, so it is not full queue error
+	persisted := false
+	err := q.Add(model.NewDeleteJob(uuid.New(), []string{"a", "b", "c"}), func() error {
+		persisted = true
+		return nil
+	})
+	require.ErrorIs(t, err, shterrors.ErrDeleteListTooLarge)
+	assert.NotErrorIs(t, err, shterrors.ErrDeleteQueueFull)
+	assert.False(t, persisted, "отклоненная задача сохранена")
+	assert.Equal(t, 0, q.Stats().Depth)
+
+	require.NoError(t, q.Add(model.NewDeleteJob(uuid.New(), []string{"a", "b"}), nil))
+	stop()
+	assert.Equal(t, 2, rec.deletedCount())
+}
+
+func TestQueue_PersistError(t *testing.T) {
+	rec := &recorder{}
+	q, stop := newTestQueue(t, rec)
+
+	persistErr := errors.New("бд недоступна")
+	err := q.Add(model.NewDeleteJob(uuid.New(), []string{"a"}), func() error { return persistErr })
+	require.ErrorIs(t, err, persistErr)
+	stop()
+
+	assert.Equal(t, 0, rec.deletedCount())
+	assert.Equal(t, 0, q.Stats().Depth)
+}
+
+func TestQueue_PersistWithoutLock(t *testing.T) {
+	rec := &recorder{block: make(chan struct{})}
+	q, stop := newTestQueue(t, rec, WithSize(2))
+
+	//  persist of one job does not block adding of other jobs, space of persisting job is reserved
+	userID := uuid.New()
+	err := q.Add(model.NewDeleteJob(userID, []string{"a"}), func() error {
+		require.NoError(t, q.Add(model.NewDeleteJob(userID, []string{"b"}), nil))
+		require.ErrorIs(t, q.Add(model.NewDeleteJob(userID, []string{"c"}), nil), shterrors.ErrDeleteQueueFull)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, q.Stats().Depth)
+
+	close(rec.block)
+	stop()
+	assert.Equal(t, 2, rec.deletedCount())
+}
+
+func TestQueue_Pending(t *testing.T) {
+	rec := &recorder{}
+	pending := []model.DeleteJob{
+		model.NewDeleteJob(uuid.New(), []string{"a", "b"}),
+		model.NewDeleteJob(uuid.New(), []string{"c"}),
+	}
+	q, stop := newTestQueue(t, rec, WithPending(pending))
+
+	require.NoError(t, q.Add(model.NewDeleteJob(uuid.New(), []string{"d"}), nil))
+	require.Eventually(t, func() bool { return rec.deletedCount() == 4 }, time.Second, time.Millisecond)
+	stop()
+
+	//  pending jobs are processed before new jobs
+	require.Len(t, rec.deleted, 3)
+	assert.Equal(t, pending[0].ID, rec.deleted[0].ID)
+	assert.Equal(t, pending[1].ID, rec.deleted[1].ID)
+	assert.Equal(t, int64(4), q.Stats().Deleted)
+}
+
+//  newTestQueue returns queue and func, that closes queue and waits remaining jobs processed.
+func newTestQueue(t *testing.T, processor Processor, opts ...Option) (*Queue, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ended := make(chan struct{})
+	q := New(ctx, processor, ended, opts...)
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-ended
+		})
+	}
+	t.Cleanup(stop)
+
+	return q, stop
+}
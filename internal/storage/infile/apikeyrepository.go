@@ -0,0 +1,102 @@
+package infile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+	"github.com/google/uuid"
+)
+
+var _ storage.APIKeyRepository = (*apiKeyRepository)(nil)
+
+//  apiKeyRepository implements APIKeyRepository interface, provides actions with api key records in inmemory storage.
+//  Only key hashes are stored, keys are not written to file.
+type apiKeyRepository struct {
+	cache *cache
+}
+
+//  newAPIKeyRepository inits new api key repository.
+func newAPIKeyRepository(c *cache) (*apiKeyRepository, error) {
+	if c == nil {
+		return nil, errors.New("cant init repository cache not init")
+	}
+
+	return &apiKeyRepository{
+		cache: c,
+	}, nil
+}
+
+//  AddKey saves api key to inmemory storage.
+func (r *apiKeyRepository) AddKey(_ context.Context, key model.APIKey) (model.APIKey, error) {
+	dbObj, err := schema.NewAPIKeyFromCanonical(key)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
+	if _, ok := r.cache.apiKeyHashIdx[dbObj.Hash]; ok {
+		return model.APIKey{}, errors.New("ошибка хранилица: ключ API уже существует")
+	}
+
+	r.cache.apiKeyCache[dbObj.ID] = dbObj
+	r.cache.apiKeyHashIdx[dbObj.Hash] = dbObj.ID
+
+	return key, nil
+}
+
+//  GetKeyByHash returns api key by key hash.
+func (r *apiKeyRepository) GetKeyByHash(_ context.Context, hash string) (model.APIKey, error) {
+	r.cache.RLock()
+	defer r.cache.RUnlock()
+
+	id, ok := r.cache.apiKeyHashIdx[hash]
+	if !ok {
+		return model.APIKey{}, shterrors.ErrAPIKeyNotFound
+	}
+
+	return r.cache.apiKeyCache[id].ToCanonical()
+}
+
+//  GetUserKeys returns all api keys of user, ordered by creation time.
+func (r *apiKeyRepository) GetUserKeys(_ context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	r.cache.RLock()
+	defer r.cache.RUnlock()
+
+	keys := make(schema.APIKeyList, 0)
+	for _, v := range r.cache.apiKeyCache {
+		if v.UserID == userID {
+			keys = append(keys, v)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys.ToCanonical()
+}
+
+//  RevokeKey marks user api key as revoked.
+func (r *apiKeyRepository) RevokeKey(_ context.Context, userID uuid.UUID, keyID uuid.UUID, revokedAt time.Time) error {
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
+	key, ok := r.cache.apiKeyCache[keyID]
+	if !ok || key.UserID != userID || !key.RevokedAt.IsZero() {
+		return shterrors.ErrAPIKeyNotFound
+	}
+
+	key.RevokedAt = revokedAt
+	r.cache.apiKeyCache[keyID] = key
+
+	return nil
+}
@@ -3,6 +3,7 @@ package infile
 import (
 	"sync"
 
+	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/storage/schema"
 	"github.com/google/uuid"
 )
@@ -14,6 +15,12 @@ type cache struct {
 	shortURLidx map[string]uuid.UUID
 	srcURLidx   map[string]uuid.UUID
 	userCache   map[uuid.UUID]uuid.UUID
+	clickCache  map[string][]schema.Click
+
+	apiKeyCache   map[uuid.UUID]schema.APIKey
+	apiKeyHashIdx map[string]uuid.UUID
+
+	deleteJobCache map[uuid.UUID]model.DeleteJob
 }
 
 //  newCache inits new cache.
@@ -23,5 +30,11 @@ func newCache() *cache {
 		userCache:   make(map[uuid.UUID]uuid.UUID),
 		shortURLidx: make(map[string]uuid.UUID),
 		srcURLidx:   make(map[string]uuid.UUID),
+		clickCache:  make(map[string][]schema.Click),
+
+		apiKeyCache:   make(map[uuid.UUID]schema.APIKey),
+		apiKeyHashIdx: make(map[string]uuid.UUID),
+
+		deleteJobCache: make(map[uuid.UUID]model.DeleteJob),
 	}
 }
@@ -0,0 +1,188 @@
+package infile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ storage.ClickRepository = (*clickRepository)(nil)
+
+const (
+	clickBuffBatch     = 100         //  size of buffer for batch writing clicks.
+	clickChanSize      = 1000        //  size of incoming clicks queue.
+	clickFlushInterval = time.Second //  interval of flushing not full clicks buffer.
+
+	//  maxReferrers is max count of referrers in stats.
+	maxReferrers = 10
+)
+
+//  clickRepository implements ClickRepository interface, provides actions with click records in inmemory storage.
+//  Clicks are added to queue without blocking, worker adds them to memory and appends to clicks file by batches.
+type clickRepository struct {
+	cache       *cache
+	fileName    string
+	clickChan   chan schema.Click
+	workerEnded chan struct{}
+	closed      bool
+	logger      *slog.Logger
+	sync.RWMutex
+}
+
+//  newClickRepository inits new click repository, clicks are written to file if file name not empty.
+func newClickRepository(c *cache, fileName string, logger *slog.Logger) (*clickRepository, error) {
+	if c == nil {
+		return nil, errors.New("cant init repository cache not init")
+	}
+
+	return &clickRepository{
+		cache:       c,
+		fileName:    fileName,
+		clickChan:   make(chan schema.Click, clickChanSize),
+		workerEnded: make(chan struct{}),
+		logger:      logger,
+	}, nil
+}
+
+//  AddClick adds click to queue without blocking.
+//  If queue is full, click is dropped.
+func (r *clickRepository) AddClick(click model.Click) error {
+	dbObj, err := schema.NewClickFromCanonical(click)
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.closed {
+		return errors.New("очередь записи переходов закрыта")
+	}
+
+	select {
+	case r.clickChan <- dbObj:
+		return nil
+	default:
+		return errors.New("очередь записи переходов переполнена")
+	}
+}
+
+//  close closes clicks queue and waits until worker writes queued clicks.
+func (r *clickRepository) close() {
+	r.Lock()
+	if r.closed {
+		r.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.clickChan)
+	r.Unlock()
+
+	<-r.workerEnded
+}
+
+//  initClickBatchWorker runs single click worker, that takes clicks from clickChan
+//  and writes when filling the cache, or by flush interval.
+func (r *clickRepository) initClickBatchWorker() {
+	go func() {
+		ticker := time.NewTicker(clickFlushInterval)
+		defer ticker.Stop()
+
+		cache := make([]schema.Click, 0, clickBuffBatch)
+		for {
+			select {
+			// read click from clickChan
+			case v, ok := <-r.clickChan:
+				if !ok { // if chanel closed, write buff and send to workerEnded
+					if len(cache) > 0 {
+						r.writeClickBatch(cache)
+					}
+					close(r.workerEnded)
+					return
+				}
+				cache = append(cache, v)
+				if len(cache) < cap(cache) {
+					continue
+				}
+			// flush by interval
+			case <-ticker.C:
+				if len(cache) == 0 {
+					continue
+				}
+			}
+			r.writeClickBatch(cache)
+			cache = make([]schema.Click, 0, clickBuffBatch)
+		}
+	}()
+}
+
+//  writeClickBatch adds clicks to memory and appends to clicks file.
+//  Clicks are best effort, file writing error is logged.
+func (r *clickRepository) writeClickBatch(clicks []schema.Click) {
+	r.cache.Lock()
+	for _, v := range clicks {
+		r.cache.clickCache[v.ShortID] = append(r.cache.clickCache[v.ShortID], v)
+	}
+	r.cache.Unlock()
+
+	if r.fileName == "" {
+		return
+	}
+
+	fileWriter, err := newFileWriter(r.fileName)
+	if err != nil {
+		r.logger.Error("ошибка записи переходов в файл", "file", r.fileName, "error", err.Error())
+		return
+	}
+	defer fileWriter.Close()
+
+	if err := fileWriter.WriteClicks(clicks); err != nil {
+		r.logger.Error("ошибка записи переходов в файл", "file", r.fileName, "error", err.Error())
+	}
+}
+
+//  GetStats returns aggregated redirect stats for shortID.
+func (r *clickRepository) GetStats(_ context.Context, shortID string) (model.ClickStats, error) {
+	r.cache.RLock()
+	defer r.cache.RUnlock()
+
+	stats := model.ClickStats{
+		ShortID:   shortID,
+		Referrers: make(map[string]int),
+	}
+
+	ips := make(map[string]struct{})
+	referrers := make(map[string]int)
+	for _, v := range r.cache.clickCache[shortID] {
+		stats.Clicks++
+		ips[v.IP] = struct{}{}
+		if v.ClickedAt.After(stats.LastClickAt) {
+			stats.LastClickAt = v.ClickedAt
+		}
+		if v.Referrer != "" {
+			referrers[v.Referrer]++
+		}
+	}
+	stats.UniqueIPs = len(ips)
+
+	//  take top referrers
+	for i := 0; i < maxReferrers && len(referrers) > 0; i++ {
+		top, topCount := "", 0
+		for k, v := range referrers {
+			if v > topCount || (v == topCount && k < top) {
+				top, topCount = k, v
+			}
+		}
+		stats.Referrers[top] = topCount
+		delete(referrers, top)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,84 @@
+package infile
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+	"github.com/google/uuid"
+)
+
+//  compactFile rewrites file with one record of every url, ordered by creation time.
+//  Records are written to temp file in same directory, synced and renamed over file,
+//  so on crash file contains old or new records.
+func compactFile(fileName string, data map[uuid.UUID]schema.ShortURL) (err error) {
+	urls := make([]schema.ShortURL, 0, len(data))
+	for _, v := range data {
+		urls = append(urls, v)
+	}
+	sort.Slice(urls, func(i, j int) bool {
+		if !urls[i].CreatedAt.Equal(urls[j].CreatedAt) {
+			return urls[i].CreatedAt.Before(urls[j].CreatedAt)
+		}
+		return urls[i].ID.String() < urls[j].ID.String()
+	})
+
+	dir := filepath.Dir(fileName)
+	tmp, err := os.CreateTemp(dir, filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+
+	//  remove temp file if not renamed
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	writer := bufio.NewWriter(tmp)
+	for _, v := range urls {
+		record, err := encodeRecord(v)
+		if err != nil {
+			return fmt.Errorf("ошибка сжатия файла: %w", err)
+		}
+		if _, err := writer.Write(record); err != nil {
+			return fmt.Errorf("ошибка сжатия файла: %w", err)
+		}
+	}
+
+	if err = writer.Flush(); err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+	if err = tmp.Chmod(0777); err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), fileName); err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+//  syncDir syncs directory, persists rename of file.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("ошибка сжатия файла: %w", err)
+	}
+	defer d.Close()
+
+	//  some platforms not support directory sync, rename is already done
+	_ = d.Sync()
+	return nil
+}
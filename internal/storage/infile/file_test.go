@@ -0,0 +1,206 @@
+package infile
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage_Recovery(t *testing.T) {
+	first, second := testURL("first"), testURL("second")
+	legacy, err := json.Marshal(testURL("legacy"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tail     string
+		wantURLs int
+		wantErr  bool
+	}{
+		{name: "valid records", wantURLs: 2},
+		{name: "legacy record without checksum", tail: string(legacy) + "\n", wantURLs: 3},
+		{name: "torn record without newline", tail: `0a1b2c3d {"ID":"`, wantURLs: 2},
+		{name: "corrupted last record", tail: "00000000 " + string(legacy) + "\n", wantURLs: 2},
+		{name: "corrupted record inside file", tail: "00000000 " + string(legacy) + "\n" + string(legacy) + "\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "storage.log")
+			valid := writeRecords(t, fileName, first, second)
+			appendToFile(t, fileName, tt.tail)
+
+			st, err := NewFileStorage(context.Background(), fileName)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			count, err := st.URL().GetCount()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantURLs, count)
+
+			if tt.wantURLs == 2 {
+				info, err := os.Stat(fileName)
+				require.NoError(t, err)
+				assert.Equal(t, valid, info.Size())
+			}
+		})
+	}
+}
+
+func TestFileStorage_Compact(t *testing.T) {
+	ctx := context.Background()
+	fileName := filepath.Join(t.TempDir(), "storage.log")
+
+	first, second := testURL("first"), testURL("second")
+	writeRecords(t, fileName, first, second)
+
+	st, err := NewFileStorage(context.Background(), fileName)
+	require.NoError(t, err)
+
+	_, err = st.URL().DeleteURLBatch(first.UserID, first.ShortID)
+	require.NoError(t, err)
+	assert.True(t, st.hasOutdatedRecords())
+
+	removed, err := st.Compact()
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.False(t, st.hasOutdatedRecords())
+	st.Close()
+
+	matches, err := filepath.Glob(fileName + ".*.tmp")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	reopened, err := NewFileStorage(context.Background(), fileName)
+	require.NoError(t, err)
+	assert.Equal(t, 2, reopened.shortURLRepo.fileRecords)
+
+	deleted, err := reopened.URL().GetURL(ctx, first.ShortID)
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted)
+
+	saved, err := reopened.URL().GetURL(ctx, second.ShortID)
+	require.NoError(t, err)
+	assert.Equal(t, second.URL, saved.URL)
+}
+
+func TestFileStorage_ExpiredSrcURL(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "storage.log")
+
+	expired, active := testURL("expired"), testURL("active")
+	expired.IsExpired = true
+	active.URL = expired.URL
+	writeRecords(t, fileName, expired, active, expired)
+
+	//  source url index points to not expired url, whatever record order is
+	st, err := NewFileStorage(context.Background(), fileName)
+	require.NoError(t, err)
+
+	got, err := st.URL().GetURLBySrcURL(context.Background(), expired.URL)
+	require.NoError(t, err)
+	assert.Equal(t, active.ShortID, got.ShortID)
+}
+
+func TestFileStorage_Lock(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "storage.log")
+
+	st, err := NewFileStorage(context.Background(), fileName)
+	require.NoError(t, err)
+
+	//  file is used by open storage
+	_, err = NewFileStorage(context.Background(), fileName)
+	require.ErrorIs(t, err, ErrFileLocked)
+
+	st.Close()
+	reopened, err := NewFileStorage(context.Background(), fileName)
+	require.NoError(t, err)
+	reopened.Close()
+}
+
+func TestFileStorage_Clicks(t *testing.T) {
+	ctx := context.Background()
+	fileName := filepath.Join(t.TempDir(), "storage.log")
+
+	st, err := NewFileStorage(ctx, fileName)
+	require.NoError(t, err)
+
+	for _, ip := range []string{"192.168.1.1", "192.168.1.2", "192.168.1.1"} {
+		require.NoError(t, st.Click().AddClick(model.NewClick("first", "https://ya.ru/", "test", ip)))
+	}
+
+	//  queued clicks are written on close, closed queue rejects clicks
+	st.Close()
+	require.Error(t, st.Click().AddClick(model.NewClick("first", "", "test", "192.168.1.1")))
+	st.Close()
+
+	stats, err := st.Click().GetStats(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Clicks)
+
+	//  clicks are read from file after restart
+	reopened, err := NewFileStorage(ctx, fileName)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	stats, err = reopened.Click().GetStats(ctx, "first")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Clicks)
+	assert.Equal(t, 2, stats.UniqueIPs)
+	assert.Equal(t, map[string]int{"https://ya.ru/": 3}, stats.Referrers)
+}
+
+func TestClickRepository_AddClickQueueFull(t *testing.T) {
+	repo, err := newClickRepository(newCache(), "", slog.Default())
+	require.NoError(t, err)
+
+	//  worker is not started, queue is not read
+	for i := 0; i < clickChanSize; i++ {
+		require.NoError(t, repo.AddClick(model.NewClick("first", "", "test", "192.168.1.1")))
+	}
+	require.Error(t, repo.AddClick(model.NewClick("first", "", "test", "192.168.1.1")), "клик при полной очереди отбрасывается")
+}
+
+func testURL(shortID string) schema.ShortURL {
+	return schema.ShortURL{
+		ID:        uuid.New(),
+		ShortID:   shortID,
+		URL:       "https://" + shortID + ".test",
+		UserID:    uuid.New(),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+}
+
+//  writeRecords writes checksummed records to new file, returns file size.
+func writeRecords(t *testing.T, fileName string, urls ...schema.ShortURL) int64 {
+	size := 0
+	for _, v := range urls {
+		record, err := encodeRecord(v)
+		require.NoError(t, err)
+		appendToFile(t, fileName, string(record))
+		size += len(record)
+	}
+
+	return int64(size)
+}
+
+func appendToFile(t *testing.T, fileName string, data string) {
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+}
@@ -0,0 +1,10 @@
+package infile
+
+import "errors"
+
+//  LockFileSuffix is suffix of lock file name, lock file is next to urls file.
+//  Lock is held while storage is open, so server and compact command can't use file at once.
+const LockFileSuffix = ".lock"
+
+//  ErrFileLocked returns if file storage is used by another storage, e.g. running server.
+var ErrFileLocked = errors.New("файл хранилища используется другим процессом")
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package infile
+
+import (
+	"fmt"
+	"os"
+)
+
+//  lockFile opens lock file of storage file, file is not locked on platforms without flock.
+func lockFile(fileName string) (*os.File, error) {
+	f, err := os.OpenFile(fileName+LockFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка блокировки файла хранилища: %w", err)
+	}
+
+	return f, nil
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package infile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+//  lockFile takes exclusive lock of lock file of storage file, lock is released by closing returned file.
+//  Returns ErrFileLocked at once if lock is held.
+func lockFile(fileName string) (*os.File, error) {
+	f, err := os.OpenFile(fileName+LockFileSuffix, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка блокировки файла хранилища: %w", err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %v", ErrFileLocked, fileName)
+		}
+		return nil, fmt.Errorf("ошибка блокировки файла хранилища: %w", err)
+	}
+
+	return f, nil
+}
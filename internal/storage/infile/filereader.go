@@ -2,8 +2,10 @@ package infile
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
 	"github.com/atrush/pract_01.git/internal/storage/schema"
@@ -12,20 +14,23 @@ import (
 
 //  fileReader provides data reading from file.
 type fileReader struct {
-	file    *os.File
-	scanner *bufio.Scanner
+	file   *os.File
+	reader *bufio.Reader
+	logger *slog.Logger
 }
 
-//  newFileReader inits new file reader.
-func newFileReader(fileName string) (*fileReader, error) {
-	file, err := os.OpenFile(fileName, os.O_RDONLY|os.O_CREATE, 0777)
+//  newFileReader inits new file reader, truncating of file is logged by logger.
+//  File is opened for writing too, to truncate torn trailing record.
+func newFileReader(fileName string, logger *slog.Logger) (*fileReader, error) {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE, 0777)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации чтения файла: %w", err)
 	}
 
 	return &fileReader{
-		file:    file,
-		scanner: bufio.NewScanner(file),
+		file:   file,
+		reader: bufio.NewReader(file),
+		logger: logger,
 	}, nil
 }
 
@@ -34,20 +39,88 @@ func (f *fileReader) Close() error {
 	return f.file.Close()
 }
 
-//  ReadAll reads all items from file.
-func (f *fileReader) ReadAll() (map[uuid.UUID]schema.ShortURL, error) {
+//  ReadAll reads all items from file, last record of item wins. Returns items and count of read records.
+//  Partial or corrupted trailing record, left by crash during write, is truncated.
+//  Corrupted record inside file returns error.
+func (f *fileReader) ReadAll() (map[uuid.UUID]schema.ShortURL, int, error) {
 	data := make(map[uuid.UUID]schema.ShortURL)
-	for f.scanner.Scan() {
-		lineURL := schema.ShortURL{}
-		if err := json.Unmarshal(f.scanner.Bytes(), &lineURL); err != nil {
-			return nil, fmt.Errorf("ошибка обработки данных из файла: %w", err)
+
+	records, err := f.readRecords(func(line []byte) error {
+		sht := schema.ShortURL{}
+		if err := decodeRecord(line, &sht); err != nil {
+			return err
 		}
-		data[lineURL.ID] = lineURL
+
+		data[sht.ID] = sht
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if err := f.scanner.Err(); err != nil {
-		return nil, fmt.Errorf("ошибка чтения файла: %w", err)
+	return data, records, nil
+}
+
+//  ReadClicks reads all clicks from file by shortID.
+//  Partial or corrupted trailing record is truncated, corrupted record inside file returns error.
+func (f *fileReader) ReadClicks() (map[string][]schema.Click, error) {
+	data := make(map[string][]schema.Click)
+
+	_, err := f.readRecords(func(line []byte) error {
+		click := schema.Click{}
+		if err := decodeRecord(line, &click); err != nil {
+			return err
+		}
+
+		data[click.ShortID] = append(data[click.ShortID], click)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return data, nil
 }
+
+//  readRecords passes every record line without newline to decode, returns count of read records.
+func (f *fileReader) readRecords(decode func(line []byte) error) (int, error) {
+	records := 0
+
+	var offset int64
+	for {
+		line, err := f.reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				//  record without newline is not fully written
+				return records, f.truncate(offset)
+			}
+			return records, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("ошибка чтения файла: %w", err)
+		}
+
+		if len(line) > 1 {
+			if err := decode(line[:len(line)-1]); err != nil {
+				if _, errPeek := f.reader.Peek(1); errors.Is(errPeek, io.EOF) {
+					return records, f.truncate(offset)
+				}
+				return 0, fmt.Errorf("ошибка обработки данных из файла, запись со смещением %v повреждена: %w", offset, err)
+			}
+
+			records++
+		}
+		offset += int64(len(line))
+	}
+}
+
+//  truncate cuts file to size of valid records.
+func (f *fileReader) truncate(size int64) error {
+	f.logger.Warn("последняя запись файла хранилища повреждена, файл обрезан", "file", f.file.Name(), "size", size)
+
+	if err := f.file.Truncate(size); err != nil {
+		return fmt.Errorf("ошибка восстановления файла: %w", err)
+	}
+
+	return f.file.Sync()
+}
@@ -2,7 +2,6 @@ package infile
 
 import (
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"os"
 
@@ -33,19 +32,31 @@ func (f *fileWriter) Close() error {
 	return f.file.Close()
 }
 
-//  WriteURL writes url item to file.
+//  WriteURL writes url item to file as checksummed record.
 func (f *fileWriter) WriteURL(sht schema.ShortURL) error {
-
-	jsURL, err := json.Marshal(sht)
+	record, err := encodeRecord(sht)
 	if err != nil {
 		return fmt.Errorf("ошибка обработки данных для записи в файл: %w", err)
 	}
 
-	if _, err := f.writer.Write(jsURL); err != nil {
+	if _, err := f.writer.Write(record); err != nil {
 		return fmt.Errorf("ошибка записи в файл: %w", err)
 	}
-	if err := f.writer.WriteByte('\n'); err != nil {
-		return fmt.Errorf("ошибка записи в файл: %w", err)
+
+	return f.writer.Flush()
+}
+
+//  WriteClicks writes clicks to file as checksummed records.
+func (f *fileWriter) WriteClicks(clicks []schema.Click) error {
+	for _, v := range clicks {
+		record, err := encodeRecord(v)
+		if err != nil {
+			return fmt.Errorf("ошибка обработки данных для записи в файл: %w", err)
+		}
+
+		if _, err := f.writer.Write(record); err != nil {
+			return fmt.Errorf("ошибка записи в файл: %w", err)
+		}
 	}
 
 	return f.writer.Flush()
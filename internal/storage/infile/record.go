@@ -0,0 +1,54 @@
+package infile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+//  crcTable is checksum table of file records.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+//  crcLen is length of hex encoded record checksum.
+const crcLen = 2 * crc32.Size
+
+//  encodeRecord returns file record of url or click: hex crc32 of json, space, json, newline.
+func encodeRecord(v interface{}) ([]byte, error) {
+	jsURL, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]byte, 0, crcLen+len(jsURL)+2)
+	record = append(record, fmt.Sprintf("%08x ", crc32.Checksum(jsURL, crcTable))...)
+	record = append(record, jsURL...)
+
+	return append(record, '\n'), nil
+}
+
+//  decodeRecord parses record line without newline to v, checks checksum.
+//  Lines without checksum, written by previous versions, are read as json.
+func decodeRecord(line []byte, v interface{}) error {
+	jsURL := line
+	if !bytes.HasPrefix(line, []byte("{")) {
+		if len(line) < crcLen+1 || line[crcLen] != ' ' {
+			return errors.New("неверный формат записи")
+		}
+
+		sum, err := hex.DecodeString(string(line[:crcLen]))
+		if err != nil {
+			return fmt.Errorf("неверная контрольная сумма записи: %w", err)
+		}
+
+		jsURL = line[crcLen+1:]
+		if crc32.Checksum(jsURL, crcTable) != binary.BigEndian.Uint32(sum) {
+			return errors.New("контрольная сумма записи не совпадает")
+		}
+	}
+
+	return json.Unmarshal(jsURL, v)
+}
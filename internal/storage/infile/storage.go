@@ -1,31 +1,60 @@
 package infile
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
+	"github.com/atrush/pract_01.git/internal/logging"
 	"github.com/atrush/pract_01.git/internal/storage"
 	"github.com/google/uuid"
 )
 
 var _ storage.Storage = (*Storage)(nil)
 
+//  DefCompactInterval is default interval of file compaction.
+const DefCompactInterval = 10 * time.Minute
+
+//  ClicksFileSuffix is suffix of clicks file name, clicks are stored in separate file next to urls file.
+const ClicksFileSuffix = ".clicks"
+
 //  Storage implements Storage interface, provides storing data in memory and duplicates it to file.
 type Storage struct {
 	shortURLRepo *shortURLRepository
 	userRepo     *userRepository
+	clickRepo    *clickRepository
+	apiKeyRepo   *apiKeyRepository
 	fileName     string
 	cache        *cache
+	logger       *slog.Logger
+
+	//  lock is lock file, held while storage is open
+	lock *os.File
 }
 
-//  NewFileStorage inits new file storage, reads all records from file to memory.
-func NewFileStorage(fileName string) (*Storage, error) {
+//  NewFileStorage inits new file storage, reads all records of urls and clicks from files to memory.
+//  Clicks are written async, Close writes queued clicks. Async errors are logged by logger from context.
+//  File is locked until Close, returns ErrFileLocked if file is used by another storage.
+func NewFileStorage(ctx context.Context, fileName string) (_ *Storage, err error) {
 	st := Storage{
 		fileName: fileName,
 		cache:    newCache(),
+		logger:   logging.FromContext(ctx),
 	}
 
-	var err error
+	if st.fileName != "" {
+		if st.lock, err = lockFile(st.fileName); err != nil {
+			return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				st.lock.Close()
+			}
+		}()
+	}
 
 	st.shortURLRepo, err = newShortURLRepository(st.cache, st.fileName)
 	if err != nil {
@@ -37,11 +66,29 @@ func NewFileStorage(fileName string) (*Storage, error) {
 		return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
 	}
 
+	clicksFileName := ""
+	if st.fileName != "" {
+		clicksFileName = st.fileName + ClicksFileSuffix
+	}
+	st.clickRepo, err = newClickRepository(st.cache, clicksFileName, st.logger)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
+	}
+
+	st.apiKeyRepo, err = newAPIKeyRepository(st.cache)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
+	}
+
 	if st.fileName != "" {
 		if err := st.initFromFile(); err != nil {
 			return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
 		}
+		if err := st.initClicksFromFile(clicksFileName); err != nil {
+			return nil, fmt.Errorf("ошибка инициализации хранилища: %w", err)
+		}
 	}
+	st.clickRepo.initClickBatchWorker()
 
 	return &st, nil
 }
@@ -67,23 +114,108 @@ func (s *Storage) User() storage.UserRepository {
 	return s.userRepo
 }
 
+//  Click returns clicks repository.
+func (s *Storage) Click() storage.ClickRepository {
+	return s.clickRepo
+}
+
+//  APIKey returns api keys repository.
+func (s *Storage) APIKey() storage.APIKeyRepository {
+	return s.apiKeyRepo
+}
+
 //  Ping checks storage connection.
 //  Always return error, becous storage database not initialised.
 func (s *Storage) Ping() error {
 	return errors.New("db not initialized")
 }
 
-//  Close is empty, imitates close function
-func (s *Storage) Close() {}
+//  Close stops clicks queue, waits until queued clicks are written, and releases file lock.
+func (s *Storage) Close() {
+	s.clickRepo.close()
+
+	if s.lock != nil {
+		s.lock.Close()
+		s.lock = nil
+	}
+}
+
+//  Compact rewrites file with last record of every url, returns count of removed records.
+//  Writes are blocked while compaction runs.
+func (s *Storage) Compact() (int, error) {
+	if s.fileName == "" {
+		return 0, nil
+	}
+
+	s.cache.Lock()
+	defer s.cache.Unlock()
+
+	removed := s.shortURLRepo.fileRecords - len(s.cache.urlCache)
+	if err := compactFile(s.fileName, s.cache.urlCache); err != nil {
+		return 0, err
+	}
+	s.shortURLRepo.fileRecords = len(s.cache.urlCache)
+
+	return removed, nil
+}
+
+//  RunCompactor compacts file by interval, if file contains outdated records, until context done.
+func (s *Storage) RunCompactor(ctx context.Context, interval time.Duration) {
+	if s.fileName == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.hasOutdatedRecords() {
+				continue
+			}
+			if _, err := s.Compact(); err != nil {
+				logging.FromContext(ctx).Error("ошибка сжатия файла хранилища", "file", s.fileName, "error", err.Error())
+			}
+		}
+	}
+}
+
+//  hasOutdatedRecords checks that file contains replaced records of urls.
+func (s *Storage) hasOutdatedRecords() bool {
+	s.cache.RLock()
+	defer s.cache.RUnlock()
+
+	return s.shortURLRepo.fileRecords > len(s.cache.urlCache)
+}
+
+//  initClicksFromFile reads all clicks from clicks file to memory.
+func (s *Storage) initClicksFromFile(fileName string) error {
+	fileReader, err := newFileReader(fileName, s.logger)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения переходов: %w", err)
+	}
+	defer fileReader.Close()
+
+	clicks, err := fileReader.ReadClicks()
+	if err != nil {
+		return fmt.Errorf("ошибка чтения переходов: %w", err)
+	}
+	s.cache.clickCache = clicks
+
+	return nil
+}
 
 //  initFromFile read all items from file to memory.
 func (s *Storage) initFromFile() error {
-	fileReader, err := newFileReader(s.fileName)
+	fileReader, err := newFileReader(s.fileName, s.logger)
 	if err != nil {
 		return fmt.Errorf("ошибка чтения из хранилища: %w", err)
 	}
 
-	data, err := fileReader.ReadAll()
+	data, records, err := fileReader.ReadAll()
 	defer fileReader.Close()
 	if err != nil {
 		return fmt.Errorf("ошибка чтения из хранилища: %w", err)
@@ -91,8 +223,10 @@ func (s *Storage) initFromFile() error {
 
 	//  set URL cache
 	s.cache.urlCache = data
+	s.shortURLRepo.fileRecords = records
 
 	if len(data) > 0 {
+		now := time.Now()
 		for _, v := range data {
 			//  set URL index
 			existShortID, _ := s.shortURLRepo.Exist(v.ShortID)
@@ -101,14 +235,13 @@ func (s *Storage) initFromFile() error {
 			}
 
 			//  set Users cahe
-			existUser, _ := s.userRepo.Exist(v.UserID)
+			existUser, _ := s.userRepo.Exist(context.Background(), v.UserID)
 			if v.UserID != uuid.Nil && !existUser {
 				s.cache.userCache[v.UserID] = v.UserID
 			}
 
-			//  set srcURL cache
-			existURL, _ := s.shortURLRepo.Exist(v.URL)
-			if !existURL {
+			//  set srcURL cache, not expired url of source url replaces expired
+			if id, existURL := s.cache.srcURLidx[v.URL]; !existURL || s.cache.urlCache[id].Expired(now) {
 				s.cache.srcURLidx[v.URL] = v.ID
 			}
 		}
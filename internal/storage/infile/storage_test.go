@@ -0,0 +1,20 @@
+package infile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		st, err := NewFileStorage(context.Background(), "")
+		require.NoError(t, err)
+		t.Cleanup(st.Close)
+
+		return st
+	})
+}
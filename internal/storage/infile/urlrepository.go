@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/shterrors"
@@ -18,6 +20,9 @@ var _ st.URLRepository = (*shortURLRepository)(nil)
 type shortURLRepository struct {
 	cache    *cache
 	fileName string
+
+	//  fileRecords is count of records in file, changed under cache lock
+	fileRecords int
 }
 
 // newShortURLRepository inits new url repository.
@@ -32,47 +37,101 @@ func newShortURLRepository(c *cache, fileName string) (*shortURLRepository, erro
 	}, nil
 }
 
-//  DeleteURLBatch marks list of urls as deleted.
-func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) error {
-	if len(shortIDList) == 0 {
-		return nil
-	}
+//  DeleteURLBatch marks list of user urls as deleted, deleted urls are written to file.
+//  Urls are deleted synchronously, returns done job. Jobs are stored in memory only.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job := model.NewDeleteJob(userID, shortIDList)
+
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
 	for _, v := range shortIDList {
-		sht, _ := r.GetURL(context.TODO(), v)
-		if sht != (model.ShortURL{}) {
-			if sht.UserID == userID && !sht.IsDeleted {
-				sht.IsDeleted = true
-				toAdd, err := schema.NewURLFromCanonical(sht)
-				if err != nil {
-					return fmt.Errorf("ошибка обновления запси: %w", err)
-				}
-
-				r.cache.Lock()
-				r.cache.urlCache[sht.ID] = toAdd
-				r.cache.Unlock()
-			}
+		id, ok := r.cache.shortURLidx[v]
+		if !ok {
+			continue
+		}
+
+		sht, ok := r.cache.urlCache[id]
+		if !ok || sht.UserID != userID || sht.IsDeleted {
+			continue
+		}
 
+		sht.IsDeleted = true
+		if r.fileName != "" {
+			if err := r.writeToFile(sht); err != nil {
+				return model.DeleteJob{}, fmt.Errorf("ошибка обновления запси: %w", err)
+			}
 		}
+
+		r.cache.urlCache[id] = sht
 	}
-	return nil
+
+	job.Finish(model.DeleteJobDone, "", time.Now().UTC())
+	r.cache.deleteJobCache[job.ID] = job
+
+	return job, nil
 }
 
-//  SaveURLBuff saves list of urls to storage, without buffering.
-func (r *shortURLRepository) SaveURLBuff(sht *model.ShortURL) error {
-	if sht == nil {
-		return errors.New("short URL is nil")
-	}
+//  GetDeleteJob returns delete job by id.
+//  Returns shterrors.ErrDeleteJobNotFound if job not exist.
+func (r *shortURLRepository) GetDeleteJob(_ context.Context, jobID uuid.UUID) (model.DeleteJob, error) {
+	r.cache.RLock()
+	defer r.cache.RUnlock()
 
-	dbObj, err := r.SaveURL(context.TODO(), *sht)
-	if err != nil {
-		return err
+	job, ok := r.cache.deleteJobCache[jobID]
+	if !ok {
+		return model.DeleteJob{}, shterrors.ErrDeleteJobNotFound
 	}
-	sht = &dbObj
-	return nil
+
+	return job, nil
 }
 
-//  SaveURLBuffFlush is empty imitates buffer flush.
-func (r *shortURLRepository) SaveURLBuffFlush() error {
+//  SaveURLBatch saves list of urls to storage.
+//  All urls are checked before writing, if any url conflicts, no urls are saved.
+func (r *shortURLRepository) SaveURLBatch(_ context.Context, urls []model.ShortURL) error {
+	list := make([]schema.ShortURL, 0, len(urls))
+	shortIDs := make(map[string]struct{}, len(urls))
+	srcURLs := make(map[string]string, len(urls))
+	for _, sht := range urls {
+		dbObj, err := schema.NewURLFromCanonical(sht)
+		if err != nil {
+			return fmt.Errorf("ошибка хранилица:%w", err)
+		}
+
+		if _, exist := shortIDs[dbObj.ShortID]; exist {
+			return &shterrors.ErrorConflictSaveURL{
+				Err:           errors.New("конфликт добавления записи, shortID повторяется в списке"),
+				ExistShortURL: dbObj.ShortID,
+				Code:          shterrors.ConflictCodeAlias,
+			}
+		}
+		if shortID, exist := srcURLs[dbObj.URL]; exist {
+			return &shterrors.ErrorConflictSaveURL{
+				Err:           errors.New("конфликт добавления записи, URL повторяется в списке"),
+				ExistShortURL: shortID,
+				Code:          shterrors.ConflictCodeURL,
+			}
+		}
+		shortIDs[dbObj.ShortID] = struct{}{}
+		srcURLs[dbObj.URL] = dbObj.ShortID
+
+		list = append(list, dbObj)
+	}
+
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
+	for _, dbObj := range list {
+		if err := r.checkNoLock(dbObj); err != nil {
+			return err
+		}
+	}
+	for _, dbObj := range list {
+		if err := r.insertNoLock(dbObj); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -84,30 +143,50 @@ func (r *shortURLRepository) SaveURL(_ context.Context, sht model.ShortURL) (mod
 		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
 	}
 
-	exist, _ := r.Exist(dbObj.ShortID)
-	if exist {
-		return model.ShortURL{}, errors.New("shortID уже существует")
+	//  checks and writes under one lock, so concurrent saves of same url or shortID conflict
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
+	if err := r.checkNoLock(dbObj); err != nil {
+		return model.ShortURL{}, err
+	}
+	if err := r.insertNoLock(dbObj); err != nil {
+		return model.ShortURL{}, err
 	}
 
-	existSrcURL, _ := r.ExistSrcURL(dbObj.URL)
-	if existSrcURL {
+	return sht, nil
+}
+
+//  checkNoLock checks that url can be saved: shortID and not expired source url are not stored, user exists.
+func (r *shortURLRepository) checkNoLock(dbObj schema.ShortURL) error {
+	if _, exist := r.cache.shortURLidx[dbObj.ShortID]; exist {
+		return &shterrors.ErrorConflictSaveURL{
+			Err:           errors.New("конфликт добавления записи, shortID уже существует"),
+			ExistShortURL: dbObj.ShortID,
+			Code:          shterrors.ConflictCodeAlias,
+		}
+	}
 
-		return model.ShortURL{}, &shterrors.ErrorConflictSaveURL{
+	if id, existSrcURL := r.cache.srcURLidx[dbObj.URL]; existSrcURL && !r.cache.urlCache[id].Expired(time.Now()) {
+		return &shterrors.ErrorConflictSaveURL{
 			Err:           errors.New("конфликт добавления записи, URL уже существует"),
-			ExistShortURL: r.GetShortURLBySrcURL(dbObj.URL),
+			ExistShortURL: r.cache.urlCache[id].ShortID,
+			Code:          shterrors.ConflictCodeURL,
 		}
 	}
 
-	if userExist := r.userExist(sht.UserID); !userExist {
-		return model.ShortURL{}, errors.New("пользователь не найден")
+	if _, userExist := r.cache.userCache[dbObj.UserID]; !userExist {
+		return errors.New("пользователь не найден")
 	}
 
-	r.cache.Lock()
-	defer r.cache.Unlock()
-	if r.fileName != "" {
+	return nil
+}
 
+//  insertNoLock writes url to file and memory, source url index of expired url is replaced.
+func (r *shortURLRepository) insertNoLock(dbObj schema.ShortURL) error {
+	if r.fileName != "" {
 		if err := r.writeToFile(dbObj); err != nil {
-			return model.ShortURL{}, err
+			return err
 		}
 	}
 
@@ -115,10 +194,11 @@ func (r *shortURLRepository) SaveURL(_ context.Context, sht model.ShortURL) (mod
 	r.cache.shortURLidx[dbObj.ShortID] = dbObj.ID
 	r.cache.srcURLidx[dbObj.URL] = dbObj.ID
 
-	return sht, nil
+	return nil
 }
 
 //  GetURL selects url from inmemory storage, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist.
 func (r *shortURLRepository) GetURL(_ context.Context, shortID string) (model.ShortURL, error) {
 	if shortID == "" {
 		return model.ShortURL{}, errors.New("нельзя использовать пустой id")
@@ -135,49 +215,64 @@ func (r *shortURLRepository) GetURL(_ context.Context, shortID string) (model.Sh
 		}
 	}
 
-	return model.ShortURL{}, nil
+	return model.ShortURL{}, shterrors.ErrNotFound
 }
 
-//  GetShortURLBySrcURL returns stored shortID by url
-func (r *shortURLRepository) GetShortURLBySrcURL(url string) string {
+//  GetURLBySrcURL selects not expired url from inmemory storage by source url, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist or expired.
+func (r *shortURLRepository) GetURLBySrcURL(_ context.Context, url string) (model.ShortURL, error) {
 	r.cache.RLock()
-	id, ok := r.cache.srcURLidx[url]
 	defer r.cache.RUnlock()
 
-	if ok {
-		sht, okSht := r.cache.urlCache[id]
-		if okSht {
-			return sht.ShortID
+	if id, ok := r.cache.srcURLidx[url]; ok {
+		if item, ok := r.cache.urlCache[id]; ok && !item.Expired(time.Now()) {
+			return item.ToCanonical()
 		}
 	}
 
-	return ""
+	return model.ShortURL{}, shterrors.ErrNotFound
 }
 
-//  GetUserURLList selects array of urls for user, returns as array of canonical ShortURL.
-func (r *shortURLRepository) GetUserURLList(_ context.Context, userID uuid.UUID, limit int) ([]model.ShortURL, error) {
+//  GetUserURLList selects page of user urls by filter, ordered by creation time and id.
+//  Returns as array of canonical ShortURL.
+func (r *shortURLRepository) GetUserURLList(_ context.Context, userID uuid.UUID, filter model.URLListFilter) ([]model.ShortURL, error) {
 	r.cache.RLock()
 	defer r.cache.RUnlock()
 
 	if len(r.cache.urlCache) == 0 {
 		return nil, nil
 	}
-	var dbURLs schema.URLList
-	dbURLs = make([]schema.ShortURL, 0, limit)
+
+	userURLs := make([]model.ShortURL, 0)
 	for _, v := range r.cache.urlCache {
-		if v.UserID != uuid.Nil && v.UserID == userID {
-			dbURLs = append(dbURLs, v)
-			if len(dbURLs) == limit {
-				break
-			}
+		if v.UserID == uuid.Nil || v.UserID != userID {
+			continue
 		}
+
+		sht, err := v.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+
+		if !filter.Match(sht) || (filter.After != nil && !filter.After.Less(sht)) {
+			continue
+		}
+		userURLs = append(userURLs, sht)
 	}
 
-	if len(dbURLs) == 0 {
+	if len(userURLs) == 0 {
 		return nil, nil
 	}
 
-	return dbURLs.ToCanonical()
+	sort.Slice(userURLs, func(i, j int) bool {
+		return model.NewURLCursor(userURLs[i]).Less(userURLs[j])
+	})
+
+	if filter.Limit > 0 && len(userURLs) > filter.Limit {
+		userURLs = userURLs[:filter.Limit]
+	}
+
+	return userURLs, nil
 }
 
 //  Exist checks that shortID not exist in storage.
@@ -198,29 +293,48 @@ func (r *shortURLRepository) ExistSrcURL(url string) (bool, error) {
 	return ok, nil
 }
 
-//  GetCount returns count of stored, not deleted urls.
+//  GetCount returns count of stored, not deleted and not expired urls.
 func (r *shortURLRepository) GetCount() (int, error) {
 	r.cache.RLock()
 	defer r.cache.RUnlock()
 
+	now := time.Now()
 	count := 0
 	for _, v := range r.cache.urlCache {
-		if !v.IsDeleted {
+		if !v.IsDeleted && !v.IsExpired && (v.ExpiresAt.IsZero() || v.ExpiresAt.After(now)) {
 			count++
 		}
 	}
 	return count, nil
 }
 
-//  userExist checks that user is exist in storage.
-func (r *shortURLRepository) userExist(userID uuid.UUID) bool {
-	r.cache.RLock()
-	_, userExist := r.cache.userCache[userID]
-	defer r.cache.RUnlock()
-	return userExist
+//  MarkExpired marks urls with expiration time before now as expired, returns list of marked shortIDs.
+//  Marked urls are written to file.
+func (r *shortURLRepository) MarkExpired(_ context.Context, now time.Time) ([]string, error) {
+	r.cache.Lock()
+	defer r.cache.Unlock()
+
+	expired := make([]string, 0)
+	for id, v := range r.cache.urlCache {
+		if v.IsExpired || v.ExpiresAt.IsZero() || v.ExpiresAt.After(now) {
+			continue
+		}
+
+		v.IsExpired = true
+		if r.fileName != "" {
+			if err := r.writeToFile(v); err != nil {
+				return expired, err
+			}
+		}
+
+		r.cache.urlCache[id] = v
+		expired = append(expired, v.ShortID)
+	}
+
+	return expired, nil
 }
 
-//  writeToFile writes url to file.
+//  writeToFile writes url to file, must be called under cache lock.
 func (r *shortURLRepository) writeToFile(sht schema.ShortURL) error {
 	fileWriter, err := newFileWriter(r.fileName)
 	if err != nil {
@@ -231,6 +345,7 @@ func (r *shortURLRepository) writeToFile(sht schema.ShortURL) error {
 	if err := fileWriter.WriteURL(sht); err != nil {
 		return fmt.Errorf("ошибка записи в хранилище: %w", err)
 	}
+	r.fileRecords++
 
 	return nil
 }
@@ -42,7 +42,7 @@ func (r *userRepository) AddUser(_ context.Context, user model.User) (model.User
 }
 
 //  Exist checks that user is exist in storage.
-func (r *userRepository) Exist(userID uuid.UUID) (bool, error) {
+func (r *userRepository) Exist(_ context.Context, userID uuid.UUID) (bool, error) {
 	r.cache.RLock()
 	_, ok := r.cache.userCache[userID]
 	defer r.cache.RUnlock()
@@ -0,0 +1,201 @@
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/tracing"
+)
+
+var (
+	_ st.URLRepository    = (*shortURLRepository)(nil)
+	_ st.UserRepository   = (*userRepository)(nil)
+	_ st.ClickRepository  = (*clickRepository)(nil)
+	_ st.APIKeyRepository = (*apiKeyRepository)(nil)
+)
+
+type (
+	//  shortURLRepository implements URLRepository interface, observes latency of wrapped repository.
+	shortURLRepository struct {
+		st.URLRepository
+		observe observer
+	}
+
+	//  userRepository implements UserRepository interface, observes latency of wrapped repository.
+	userRepository struct {
+		st.UserRepository
+		observe observer
+	}
+
+	//  clickRepository implements ClickRepository interface, observes latency of wrapped repository.
+	clickRepository struct {
+		st.ClickRepository
+		observe observer
+	}
+
+	//  apiKeyRepository implements APIKeyRepository interface, observes latency of wrapped repository.
+	apiKeyRepository struct {
+		st.APIKeyRepository
+		observe observer
+	}
+)
+
+//  GetURL observes latency of GetURL of wrapped repository.
+func (r *shortURLRepository) GetURL(ctx context.Context, shortID string) (_ model.ShortURL, err error) {
+	defer r.observe.since(RepositoryURL, "get_url", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "get_url")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.GetURL(ctx, shortID)
+}
+
+//  GetUserURLList observes latency of GetUserURLList of wrapped repository.
+func (r *shortURLRepository) GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) (_ []model.ShortURL, err error) {
+	defer r.observe.since(RepositoryURL, "get_user_url_list", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "get_user_url_list")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.GetUserURLList(ctx, userID, filter)
+}
+
+//  SaveURL observes latency of SaveURL of wrapped repository.
+func (r *shortURLRepository) SaveURL(ctx context.Context, shURL model.ShortURL) (_ model.ShortURL, err error) {
+	defer r.observe.since(RepositoryURL, "save_url", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "save_url")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.SaveURL(ctx, shURL)
+}
+
+//  Exist observes latency of Exist of wrapped repository.
+func (r *shortURLRepository) Exist(shortID string) (bool, error) {
+	defer r.observe.since(RepositoryURL, "exist", time.Now())
+	return r.URLRepository.Exist(shortID)
+}
+
+//  SaveURLBatch observes latency of SaveURLBatch of wrapped repository.
+func (r *shortURLRepository) SaveURLBatch(ctx context.Context, urls []model.ShortURL) (err error) {
+	defer r.observe.since(RepositoryURL, "save_url_batch", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "save_url_batch")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.SaveURLBatch(ctx, urls)
+}
+
+//  GetURLBySrcURL observes latency of GetURLBySrcURL of wrapped repository.
+func (r *shortURLRepository) GetURLBySrcURL(ctx context.Context, srcURL string) (_ model.ShortURL, err error) {
+	defer r.observe.since(RepositoryURL, "get_url_by_src_url", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "get_url_by_src_url")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.GetURLBySrcURL(ctx, srcURL)
+}
+
+//  DeleteURLBatch observes saving of delete job, async deleting is not observed.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	defer r.observe.since(RepositoryURL, "delete_url_batch", time.Now())
+	return r.URLRepository.DeleteURLBatch(userID, shortIDList...)
+}
+
+//  GetDeleteJob observes latency of GetDeleteJob of wrapped repository.
+func (r *shortURLRepository) GetDeleteJob(ctx context.Context, jobID uuid.UUID) (_ model.DeleteJob, err error) {
+	defer r.observe.since(RepositoryURL, "get_delete_job", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "get_delete_job")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.GetDeleteJob(ctx, jobID)
+}
+
+//  MarkExpired observes latency of MarkExpired of wrapped repository.
+func (r *shortURLRepository) MarkExpired(ctx context.Context, now time.Time) (_ []string, err error) {
+	defer r.observe.since(RepositoryURL, "mark_expired", time.Now())
+	ctx, span := startSpan(ctx, RepositoryURL, "mark_expired")
+	defer tracing.End(span, &err)
+
+	return r.URLRepository.MarkExpired(ctx, now)
+}
+
+//  GetCount observes latency of GetCount of wrapped repository.
+func (r *shortURLRepository) GetCount() (int, error) {
+	defer r.observe.since(RepositoryURL, "get_count", time.Now())
+	return r.URLRepository.GetCount()
+}
+
+//  AddUser observes latency of AddUser of wrapped repository.
+func (r *userRepository) AddUser(ctx context.Context, user model.User) (_ model.User, err error) {
+	defer r.observe.since(RepositoryUser, "add_user", time.Now())
+	ctx, span := startSpan(ctx, RepositoryUser, "add_user")
+	defer tracing.End(span, &err)
+
+	return r.UserRepository.AddUser(ctx, user)
+}
+
+//  Exist observes latency of Exist of wrapped repository.
+func (r *userRepository) Exist(ctx context.Context, userID uuid.UUID) (_ bool, err error) {
+	defer r.observe.since(RepositoryUser, "exist", time.Now())
+	ctx, span := startSpan(ctx, RepositoryUser, "exist")
+	defer tracing.End(span, &err)
+
+	return r.UserRepository.Exist(ctx, userID)
+}
+
+//  GetCount observes latency of GetCount of wrapped repository.
+func (r *userRepository) GetCount() (int, error) {
+	defer r.observe.since(RepositoryUser, "get_count", time.Now())
+	return r.UserRepository.GetCount()
+}
+
+//  AddClick observes adding click to async writer, writing is not observed.
+func (r *clickRepository) AddClick(click model.Click) error {
+	defer r.observe.since(RepositoryClick, "add_click", time.Now())
+	return r.ClickRepository.AddClick(click)
+}
+
+//  GetStats observes latency of GetStats of wrapped repository.
+func (r *clickRepository) GetStats(ctx context.Context, shortID string) (_ model.ClickStats, err error) {
+	defer r.observe.since(RepositoryClick, "get_stats", time.Now())
+	ctx, span := startSpan(ctx, RepositoryClick, "get_stats")
+	defer tracing.End(span, &err)
+
+	return r.ClickRepository.GetStats(ctx, shortID)
+}
+
+//  AddKey observes latency of AddKey of wrapped repository.
+func (r *apiKeyRepository) AddKey(ctx context.Context, key model.APIKey) (_ model.APIKey, err error) {
+	defer r.observe.since(RepositoryAPIKey, "add_key", time.Now())
+	ctx, span := startSpan(ctx, RepositoryAPIKey, "add_key")
+	defer tracing.End(span, &err)
+
+	return r.APIKeyRepository.AddKey(ctx, key)
+}
+
+//  GetKeyByHash observes latency of GetKeyByHash of wrapped repository.
+func (r *apiKeyRepository) GetKeyByHash(ctx context.Context, hash string) (_ model.APIKey, err error) {
+	defer r.observe.since(RepositoryAPIKey, "get_key_by_hash", time.Now())
+	ctx, span := startSpan(ctx, RepositoryAPIKey, "get_key_by_hash")
+	defer tracing.End(span, &err)
+
+	return r.APIKeyRepository.GetKeyByHash(ctx, hash)
+}
+
+//  GetUserKeys observes latency of GetUserKeys of wrapped repository.
+func (r *apiKeyRepository) GetUserKeys(ctx context.Context, userID uuid.UUID) (_ []model.APIKey, err error) {
+	defer r.observe.since(RepositoryAPIKey, "get_user_keys", time.Now())
+	ctx, span := startSpan(ctx, RepositoryAPIKey, "get_user_keys")
+	defer tracing.End(span, &err)
+
+	return r.APIKeyRepository.GetUserKeys(ctx, userID)
+}
+
+//  RevokeKey observes latency of RevokeKey of wrapped repository.
+func (r *apiKeyRepository) RevokeKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID, revokedAt time.Time) (err error) {
+	defer r.observe.since(RepositoryAPIKey, "revoke_key", time.Now())
+	ctx, span := startSpan(ctx, RepositoryAPIKey, "revoke_key")
+	defer tracing.End(span, &err)
+
+	return r.APIKeyRepository.RevokeKey(ctx, userID, keyID, revokedAt)
+}
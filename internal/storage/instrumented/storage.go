@@ -0,0 +1,106 @@
+//  Package instrumented provides storage decorator, that observes latency of every repository operation
+//  and traces repository operations with context.
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/tracing"
+)
+
+var _ st.Storage = (*Storage)(nil)
+
+var (
+	//  tracer starts spans of repository operations.
+	tracer = tracing.Tracer("github.com/atrush/pract_01.git/internal/storage/instrumented")
+
+	//  attrRepository is span attribute of repository name.
+	attrRepository = attribute.Key("shortener.repository")
+)
+
+//  Repository names of observed operations.
+const (
+	RepositoryURL    = "url"
+	RepositoryUser   = "user"
+	RepositoryClick  = "click"
+	RepositoryAPIKey = "api_key"
+)
+
+//  ObserveFunc observes latency of repository operation.
+type ObserveFunc func(repository string, operation string, duration time.Duration)
+
+//  Storage implements Storage interface, decorator of any storage, that observes latency of repository operations.
+type Storage struct {
+	st.Storage
+	urlRepo    *shortURLRepository
+	userRepo   *userRepository
+	clickRepo  *clickRepository
+	apiKeyRepo *apiKeyRepository
+}
+
+//  NewStorage wraps storage with observing of operations latency.
+func NewStorage(db st.Storage, observe ObserveFunc) (*Storage, error) {
+	if db == nil {
+		return nil, errors.New("нельзя использовать nil хранилище")
+	}
+	if observe == nil {
+		return nil, errors.New("нельзя использовать nil функцию наблюдения")
+	}
+
+	o := observer(observe)
+	return &Storage{
+		Storage:    db,
+		urlRepo:    &shortURLRepository{URLRepository: db.URL(), observe: o},
+		userRepo:   &userRepository{UserRepository: db.User(), observe: o},
+		clickRepo:  &clickRepository{ClickRepository: db.Click(), observe: o},
+		apiKeyRepo: &apiKeyRepository{APIKeyRepository: db.APIKey(), observe: o},
+	}, nil
+}
+
+//  URL returns instrumented urls repository.
+func (s *Storage) URL() st.URLRepository {
+	return s.urlRepo
+}
+
+//  User returns instrumented users repository.
+func (s *Storage) User() st.UserRepository {
+	return s.userRepo
+}
+
+//  Click returns instrumented clicks repository.
+func (s *Storage) Click() st.ClickRepository {
+	return s.clickRepo
+}
+
+//  APIKey returns instrumented api keys repository.
+func (s *Storage) APIKey() st.APIKeyRepository {
+	return s.apiKeyRepo
+}
+
+//  Unwrap returns wrapped storage.
+func (s *Storage) Unwrap() st.Storage {
+	return s.Storage
+}
+
+//  observer observes latency of operations from start time.
+type observer ObserveFunc
+
+//  since observes latency of repository operation started at start, used with defer.
+func (o observer) since(repository string, operation string, start time.Time) {
+	o(repository, operation, time.Since(start))
+}
+
+//  startSpan starts client span of repository operation named "storage.<repository>.<operation>".
+func startSpan(ctx context.Context, repository string, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "storage."+repository+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBOperationKey.String(operation), attrRepository.String(repository)),
+	)
+}
@@ -0,0 +1,65 @@
+package instrumented
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/infile"
+	"github.com/atrush/pract_01.git/internal/storage/storagetest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		db, err := infile.NewFileStorage(context.Background(), "")
+		require.NoError(t, err)
+
+		s, err := NewStorage(db, func(string, string, time.Duration) {})
+		require.NoError(t, err)
+
+		return s
+	})
+}
+
+func TestStorage_Observe(t *testing.T) {
+	var mu sync.Mutex
+	observed := make(map[string]int)
+
+	db, err := infile.NewFileStorage(context.Background(), "")
+	require.NoError(t, err)
+
+	s, err := NewStorage(db, func(repository string, operation string, _ time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed[repository+"/"+operation]++
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	_, err = s.User().AddUser(ctx, model.User{ID: userID})
+	require.NoError(t, err)
+
+	sht, err := s.URL().SaveURL(ctx, model.NewShortURL("https://practicum.yandex.ru", userID, model.WithAlias("observe")))
+	require.NoError(t, err)
+	_, err = s.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	_, err = s.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		"user/add_user": 1,
+		"url/save_url":  1,
+		"url/get_url":   2,
+	}, observed)
+
+	_, ok := storage.DeleteQueueStats(s)
+	assert.False(t, ok)
+	assert.Equal(t, db, s.Unwrap())
+}
@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"time"
+
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/google/uuid"
 )
@@ -14,6 +16,12 @@ type Storage interface {
 	//  User returns repository for working with users.
 	User() UserRepository
 
+	//  Click returns repository for working with redirect clicks.
+	Click() ClickRepository
+
+	//  APIKey returns repository for working with user api keys.
+	APIKey() APIKeyRepository
+
 	//  Close closes storage connection.
 	Close()
 
@@ -24,13 +32,43 @@ type Storage interface {
 	WaitAsyncTasksEnded() bool
 }
 
+//  DeleteQueueStater is implemented by storages, that delete urls with async queue.
+type DeleteQueueStater interface {
+	//  DeleteQueueStats returns state of async url delete queue.
+	DeleteQueueStats() model.DeleteQueueStats
+}
+
+//  DeleteQueueStats returns state of async url delete queue of storage, or of storage wrapped by decorators
+//  with Unwrap method. Returns false if storage deletes urls without queue.
+func DeleteQueueStats(s Storage) (model.DeleteQueueStats, bool) {
+	for s != nil {
+		if stater, ok := s.(DeleteQueueStater); ok {
+			return stater.DeleteQueueStats(), true
+		}
+
+		wrapper, ok := s.(interface{ Unwrap() Storage })
+		if !ok {
+			break
+		}
+		s = wrapper.Unwrap()
+	}
+
+	return model.DeleteQueueStats{}, false
+}
+
 //  URLRepository is the interface that wraps methods for working with url records in database.
 type URLRepository interface {
 	//  GetURL selects url record from database by shortID and returns as canonical ShortURL.
+	//  Returns shterrors.ErrNotFound if url not exist.
 	GetURL(ctx context.Context, shortID string) (model.ShortURL, error)
 
-	//  GetUserURLList selects list of url records by user id and returns as array of canonical ShortURL.
-	GetUserURLList(ctx context.Context, userID uuid.UUID, limit int) ([]model.ShortURL, error)
+	//  GetURLBySrcURL selects url record from database by source url and returns as canonical ShortURL.
+	//  Returns shterrors.ErrNotFound if url not exist.
+	GetURLBySrcURL(ctx context.Context, srcURL string) (model.ShortURL, error)
+
+	//  GetUserURLList selects page of url records by user id and filter, ordered by creation time and id.
+	//  Returns as array of canonical ShortURL.
+	GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) ([]model.ShortURL, error)
 
 	//  SaveURL saves canonical ShortURL to database and returns saved instance.
 	SaveURL(ctx context.Context, shURL model.ShortURL) (model.ShortURL, error)
@@ -38,16 +76,24 @@ type URLRepository interface {
 	//  Exist checks than record with shot id is exist.
 	Exist(shortID string) (bool, error)
 
-	//  SaveURLBuff writes ShortURL elements to buffer. If buffer full runs SaveURLBuffFlush.
-	SaveURLBuff(shURL *model.ShortURL) error
+	//  SaveURLBatch saves list of canonical ShortURL to database in one transaction.
+	//  If any url is not saved, no urls are saved.
+	SaveURLBatch(ctx context.Context, urls []model.ShortURL) error
+
+	//  DeleteURLBatch saves delete job and async updates list of urls of user as deleted, returns saved job.
+	//  Storages, that delete urls synchronously, return done job.
+	//  Returns shterrors.ErrDeleteQueueFull if async delete queue has no space for list,
+	//  shterrors.ErrDeleteListTooLarge if list is larger than async delete queue.
+	DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error)
 
-	//  SaveURLBuffFlush writes ShortURL elements from buffer to database, updates objects, cleans buffer.
-	SaveURLBuffFlush() error
+	//  GetDeleteJob selects delete job by id.
+	//  Returns shterrors.ErrDeleteJobNotFound if job not exist.
+	GetDeleteJob(ctx context.Context, jobID uuid.UUID) (model.DeleteJob, error)
 
-	//  DeleteURLBatch async updates list of urls as deleted.
-	DeleteURLBatch(userID uuid.UUID, shortIDList ...string) error
+	//  MarkExpired marks urls with expiration time before now as expired, returns list of marked shortIDs.
+	MarkExpired(ctx context.Context, now time.Time) ([]string, error)
 
-	//  GetCount returns count of stored, not deleted urls.
+	//  GetCount returns count of stored, not deleted and not expired urls.
 	GetCount() (int, error)
 }
 
@@ -57,8 +103,32 @@ type UserRepository interface {
 	AddUser(ctx context.Context, user model.User) (model.User, error)
 
 	//  Exist checks than record with id is exist in storage.
-	Exist(userID uuid.UUID) (bool, error)
+	Exist(ctx context.Context, userID uuid.UUID) (bool, error)
 
 	//  GetCount returns count of stored users.
 	GetCount() (int, error)
 }
+
+//  ClickRepository is the interface that wraps methods for working with redirect click records in database.
+type ClickRepository interface {
+	//  AddClick records redirect click, must not block caller.
+	AddClick(click model.Click) error
+
+	//  GetStats returns aggregated redirect stats for shortID.
+	GetStats(ctx context.Context, shortID string) (model.ClickStats, error)
+}
+
+//  APIKeyRepository is the interface that wraps methods for working with user api key records in database.
+type APIKeyRepository interface {
+	//  AddKey saves canonical APIKey to database and returns saved instance.
+	AddKey(ctx context.Context, key model.APIKey) (model.APIKey, error)
+
+	//  GetKeyByHash selects api key by key hash, returns shterrors.ErrAPIKeyNotFound if key not exist.
+	GetKeyByHash(ctx context.Context, hash string) (model.APIKey, error)
+
+	//  GetUserKeys selects all api keys of user, including revoked.
+	GetUserKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error)
+
+	//  RevokeKey marks user api key as revoked, returns shterrors.ErrAPIKeyNotFound if user key not exist or already revoked.
+	RevokeKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID, revokedAt time.Time) error
+}
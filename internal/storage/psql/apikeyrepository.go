@@ -0,0 +1,137 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ st.APIKeyRepository = (*apiKeyRepository)(nil)
+
+//  apiKeyColumns is list of api_keys table columns in scanAPIKey order.
+const apiKeyColumns = "id, user_id, name, prefix, key_hash, created_at, revoked_at"
+
+//  apiKeyRepository implements APIKeyRepository interface, provides actions with api key records in psql storage.
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+//  newAPIKeyRepository inits new api key repository.
+func newAPIKeyRepository(db *sql.DB) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+//  AddKey saves api key to database.
+func (r *apiKeyRepository) AddKey(ctx context.Context, key model.APIKey) (model.APIKey, error) {
+	dbObj, err := schema.NewAPIKeyFromCanonical(key)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO api_keys ("+apiKeyColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		dbObj.ID,
+		dbObj.UserID,
+		dbObj.Name,
+		dbObj.Prefix,
+		dbObj.Hash,
+		dbObj.CreatedAt,
+		nullTime(dbObj.RevokedAt),
+	)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return key, nil
+}
+
+//  GetKeyByHash selects api key from database by key hash.
+func (r *apiKeyRepository) GetKeyByHash(ctx context.Context, hash string) (model.APIKey, error) {
+	dbObj, err := scanAPIKey(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1", hash,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.APIKey{}, shterrors.ErrAPIKeyNotFound
+		}
+		return model.APIKey{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetUserKeys selects all api keys of user from database, ordered by creation time.
+func (r *apiKeyRepository) GetUserKeys(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = $1 ORDER BY created_at", userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	defer rows.Close()
+
+	keys := make(schema.APIKeyList, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		keys = append(keys, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	return keys.ToCanonical()
+}
+
+//  RevokeKey marks user api key as revoked.
+func (r *apiKeyRepository) RevokeKey(ctx context.Context, userID uuid.UUID, keyID uuid.UUID, revokedAt time.Time) error {
+	res, err := r.db.ExecContext(
+		ctx,
+		"UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL",
+		revokedAt, keyID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	if count == 0 {
+		return shterrors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+//  scanAPIKey scans api key record with apiKeyColumns.
+func scanAPIKey(row rowScanner) (schema.APIKey, error) {
+	dbObj := schema.APIKey{}
+	revokedAt := sql.NullTime{}
+
+	err := row.Scan(&dbObj.ID, &dbObj.UserID, &dbObj.Name, &dbObj.Prefix, &dbObj.Hash, &dbObj.CreatedAt, &revokedAt)
+	if err != nil {
+		return schema.APIKey{}, err
+	}
+
+	if revokedAt.Valid {
+		dbObj.RevokedAt = revokedAt.Time
+	}
+	return dbObj, nil
+}
@@ -0,0 +1,163 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
+)
+
+var _ deletequeue.Processor = (*shortURLRepository)(nil)
+
+//  deleteJobColumns is list of delete_jobs table columns in scanDeleteJob order.
+const deleteJobColumns = "id, user_id, shorturls, status, error, created_at, finished_at"
+
+//  GetDeleteJob selects delete job from database by id.
+//  Returns shterrors.ErrDeleteJobNotFound if job not exist.
+func (r *shortURLRepository) GetDeleteJob(ctx context.Context, jobID uuid.UUID) (model.DeleteJob, error) {
+	job, err := scanDeleteJob(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+deleteJobColumns+" FROM delete_jobs WHERE id = $1", jobID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DeleteJob{}, shterrors.ErrDeleteJobNotFound
+	}
+	if err != nil {
+		return model.DeleteJob{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return job, nil
+}
+
+//  DeleteJobs marks urls of jobs as deleted and jobs as done with transaction.
+//  Urls of all jobs are updated by one set-based query of user and shortID pairs.
+func (r *shortURLRepository) DeleteJobs(jobs []model.DeleteJob) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+
+	// defer make rollback
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(); rollErr != nil {
+				err = fmt.Errorf("ошибка транзакции удаления:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
+			}
+		}
+	}()
+
+	ids := make([]string, 0, len(jobs))
+	userIDs := make([]string, 0, len(jobs))
+	shortIDs := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		for _, v := range job.ShortIDs {
+			userIDs = append(userIDs, job.UserID.String())
+			shortIDs = append(shortIDs, v)
+		}
+		ids = append(ids, job.ID.String())
+	}
+
+	_, err = tx.Exec(
+		"UPDATE urls u SET isdeleted = TRUE FROM unnest($1::uuid[], $2::text[]) AS d(user_id, shorturl) "+
+			"WHERE u.user_id = d.user_id AND u.shorturl = d.shorturl",
+		pq.Array(userIDs), pq.Array(shortIDs))
+	if err != nil {
+		return
+	}
+
+	_, err = tx.Exec(
+		"UPDATE delete_jobs SET status = $1, finished_at = $2 WHERE id = ANY($3::uuid[])",
+		model.DeleteJobDone, time.Now().UTC(), pq.Array(ids))
+	if err != nil {
+		return
+	}
+
+	return tx.Commit()
+}
+
+//  FailJobs marks jobs as failed with delete error.
+func (r *shortURLRepository) FailJobs(jobs []model.DeleteJob, deleteErr error) error {
+	ids := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.ID.String())
+	}
+
+	_, err := r.db.Exec(
+		"UPDATE delete_jobs SET status = $1, error = $2, finished_at = $3 WHERE id = ANY($4::uuid[])",
+		model.DeleteJobFailed, deleteErr.Error(), time.Now().UTC(), pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return nil
+}
+
+//  insertDeleteJob saves delete job to database.
+func insertDeleteJob(db *sql.DB, job model.DeleteJob) error {
+	_, err := db.Exec(
+		"INSERT INTO delete_jobs ("+deleteJobColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		job.ID,
+		job.UserID,
+		pq.Array(job.ShortIDs),
+		job.Status,
+		job.Error,
+		job.CreatedAt,
+		nullTime(job.FinishedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения задачи удаления:%w", err)
+	}
+
+	return nil
+}
+
+//  loadPendingDeleteJobs selects not processed delete jobs, ordered by creation time.
+func loadPendingDeleteJobs(db *sql.DB) ([]model.DeleteJob, error) {
+	rows, err := db.Query(
+		"SELECT "+deleteJobColumns+" FROM delete_jobs WHERE status = $1 ORDER BY created_at", model.DeleteJobPending)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+	}
+
+	defer rows.Close()
+
+	jobs := make([]model.DeleteJob, 0)
+	for rows.Next() {
+		job, err := scanDeleteJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+	}
+	return jobs, nil
+}
+
+//  scanDeleteJob scans delete job record with deleteJobColumns.
+func scanDeleteJob(row rowScanner) (model.DeleteJob, error) {
+	job := model.DeleteJob{}
+	status := ""
+	finishedAt := sql.NullTime{}
+
+	err := row.Scan(&job.ID, &job.UserID, pq.Array(&job.ShortIDs), &status, &job.Error, &job.CreatedAt, &finishedAt)
+	if err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	job.Status = model.DeleteJobStatus(status)
+	if finishedAt.Valid {
+		job.FinishedAt = finishedAt.Time
+	}
+	return job, nil
+}
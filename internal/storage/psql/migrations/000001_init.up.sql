@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS users (
+    id uuid not null,
+    primary key (id)
+);
+
+CREATE TABLE IF NOT EXISTS urls (
+    id uuid not null,
+    user_id uuid not null,
+    srcurl varchar(2050) not null,
+    shorturl varchar(16) not null,
+    isdeleted boolean not null,
+    unique (shorturl),
+    unique (srcurl),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS urls_expires_at_idx;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS isexpired;
+ALTER TABLE urls DROP COLUMN IF EXISTS expires_at;
@@ -0,0 +1,4 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS expires_at timestamptz;
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS isexpired boolean not null default false;
+
+CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls (expires_at) WHERE isexpired = false;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS clicks (
+    id bigserial not null,
+    shorturl varchar(16) not null,
+    clicked_at timestamptz not null,
+    referrer varchar(2048) not null default '',
+    user_agent varchar(512) not null default '',
+    ip varchar(45) not null default '',
+    primary key (id)
+);
+
+CREATE INDEX IF NOT EXISTS clicks_shorturl_idx ON clicks (shorturl);
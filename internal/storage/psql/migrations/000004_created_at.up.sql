@@ -0,0 +1,3 @@
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS created_at timestamptz not null default now();
+
+CREATE INDEX IF NOT EXISTS urls_user_created_idx ON urls (user_id, created_at, id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid not null,
+    user_id uuid not null,
+    name varchar(64) not null default '',
+    prefix varchar(16) not null,
+    key_hash char(64) not null,
+    created_at timestamptz not null,
+    revoked_at timestamptz,
+    unique (key_hash),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
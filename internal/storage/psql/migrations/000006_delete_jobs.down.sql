@@ -0,0 +1 @@
+DROP TABLE IF EXISTS delete_jobs;
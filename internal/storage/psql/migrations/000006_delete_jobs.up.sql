@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS delete_jobs (
+    id uuid not null,
+    user_id uuid not null,
+    shorturls text[] not null,
+    status varchar(16) not null,
+    error text not null default '',
+    created_at timestamptz not null,
+    finished_at timestamptz,
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+CREATE INDEX IF NOT EXISTS delete_jobs_pending_idx ON delete_jobs (created_at) WHERE status = 'pending';
@@ -0,0 +1,4 @@
+DELETE FROM urls a USING urls b WHERE a.srcurl = b.srcurl AND (a.created_at, a.id) < (b.created_at, b.id);
+
+DROP INDEX IF EXISTS urls_srcurl_key;
+ALTER TABLE urls ADD CONSTRAINT urls_srcurl_key UNIQUE (srcurl);
@@ -0,0 +1,3 @@
+ALTER TABLE urls DROP CONSTRAINT IF EXISTS urls_srcurl_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS urls_srcurl_key ON urls (srcurl) WHERE isexpired = false;
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
 
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/storage"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
 	_ "github.com/lib/pq"
 )
 
@@ -18,18 +21,23 @@ var _ storage.Storage = (*Storage)(nil)
 type Storage struct {
 	shortURLRepo *shortURLRepository
 	userRepo     *userRepository
+	clickRepo    *clickRepository
+	apiKeyRepo   *apiKeyRepository
 	db           *sql.DB
 	conStringDSN string
 
 	wg                *sync.WaitGroup // wait group for async tasks
 	storageAsyncEnded chan struct{}
 	urlAsyncEnded     chan struct{}
+	clickAsyncEnded   chan struct{}
 	waitAsyncEnd      bool
+	logger            *slog.Logger
 }
 
 //  NewStorage inits new connection to psql storage.
-//  !!!! On init drop all and init tables.
-func NewStorage(ctx context.Context, asyncEndedChan chan struct{}, conStringDSN string) (*Storage, error) {
+//  On init applies not applied migrations, fails if database schema is ahead of binary.
+//  Options set params of async url delete queue. Async errors are logged by logger from context.
+func NewStorage(ctx context.Context, asyncEndedChan chan struct{}, conStringDSN string, opts ...deletequeue.Option) (*Storage, error) {
 	if conStringDSN == "" {
 		return nil, fmt.Errorf("ошибка инициализации бд:%v", "строка соединения с бд пуста")
 	}
@@ -42,24 +50,35 @@ func NewStorage(ctx context.Context, asyncEndedChan chan struct{}, conStringDSN
 	if err := db.Ping(); err != nil {
 		return nil, err
 	}
-	if err := initBase(db); err != nil {
+	if err := MigrateUp(conStringDSN); err != nil {
 		return nil, err
 	}
 
 	st := &Storage{
 		db:           db,
 		conStringDSN: conStringDSN,
+		logger:       logging.FromContext(ctx),
 	}
 
 	// chan for signal that tasks ended
 	st.storageAsyncEnded = asyncEndedChan
 	st.urlAsyncEnded = make(chan struct{})
+	st.clickAsyncEnded = make(chan struct{})
 
 	// init waiter
 	st.waitAsyncEnd = true
 
-	st.shortURLRepo = newShortURLRepository(ctx, db, st.urlAsyncEnded)
+	//  jobs accepted before restart are processed first
+	pending, err := loadPendingDeleteJobs(db)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, deletequeue.WithPending(pending))
+
+	st.shortURLRepo = newShortURLRepository(ctx, db, st.urlAsyncEnded, opts...)
 	st.userRepo = newUserRepository(db)
+	st.clickRepo = newClickRepository(ctx, db, st.clickAsyncEnded)
+	st.apiKeyRepo = newAPIKeyRepository(db)
 
 	st.runWaiterAsyncEnded()
 	return st, nil
@@ -72,10 +91,11 @@ func (s *Storage) WaitAsyncTasksEnded() bool {
 
 func (s *Storage) runWaiterAsyncEnded() {
 	go func() {
-		//  wait url repository async tasks ended
+		//  wait url and click repositories async tasks ended
 		<-s.urlAsyncEnded
+		<-s.clickAsyncEnded
 
-		fmt.Println("storage async tasks ended")
+		s.logger.Info("асинхронные задачи хранилища завершены")
 
 		s.storageAsyncEnded <- struct{}{}
 	}()
@@ -89,11 +109,26 @@ func (s *Storage) URL() storage.URLRepository {
 	return s.shortURLRepo
 }
 
+//  DeleteQueueStats returns state of async url delete queue.
+func (s *Storage) DeleteQueueStats() model.DeleteQueueStats {
+	return s.shortURLRepo.DeleteQueueStats()
+}
+
 //  User returns users repository.
 func (s *Storage) User() storage.UserRepository {
 	return s.userRepo
 }
 
+//  Click returns clicks repository.
+func (s *Storage) Click() storage.ClickRepository {
+	return s.clickRepo
+}
+
+//  APIKey returns api keys repository.
+func (s *Storage) APIKey() storage.APIKeyRepository {
+	return s.apiKeyRepo
+}
+
 //  Ping checks database connection.
 func (s *Storage) Ping() error {
 	if s == nil || s.db == nil {
@@ -116,30 +151,3 @@ func (s Storage) Close() {
 	s.db.Close()
 	s.db = nil
 }
-
-//  initBase drops all and inits database tables.
-func initBase(db *sql.DB) error {
-	row := db.QueryRow("DROP SCHEMA public CASCADE;CREATE SCHEMA public;")
-	if row.Err() != nil {
-		return row.Err()
-	}
-	_, err := db.Exec("CREATE TABLE IF NOT EXISTS users (" +
-		"		id uuid not null,						" +
-		"		primary key (id));" +
-		"	CREATE TABLE IF NOT EXISTS urls(" +
-		"		id uuid not null," +
-		"		user_id uuid not null," +
-		"		srcurl varchar(2050) not null," +
-		"		shorturl varchar (16) not null," +
-		"		isdeleted boolean not null," +
-		"		unique (shorturl)," +
-		"		unique (srcurl)," +
-		"		primary key (id)," +
-		"		foreign key (user_id) references users (id)" +
-		"	);")
-	if row.Err() != nil {
-		return err
-	}
-	return nil
-
-}
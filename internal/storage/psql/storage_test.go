@@ -0,0 +1,103 @@
+package psql
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+//  testDSNEnv is environment variable with dsn of test database.
+//  If not set, tests start local Postgres from installed binaries, or are skipped.
+const testDSNEnv = "TEST_DATABASE_DSN"
+
+func TestStorage_Conformance(t *testing.T) {
+	dsn := testDSN(t)
+
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		ctx, cancel := context.WithCancel(context.Background())
+		finished := make(chan struct{})
+
+		st, err := NewStorage(ctx, finished, dsn)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			cancel()
+			<-finished
+			st.Close()
+		})
+
+		return st
+	})
+}
+
+//  testDSN returns dsn of test database from environment, or starts local Postgres for test.
+func testDSN(t *testing.T) string {
+	if dsn := os.Getenv(testDSNEnv); dsn != "" {
+		return dsn
+	}
+
+	return startLocalPostgres(t)
+}
+
+//  startLocalPostgres inits and starts temporary Postgres cluster, stops it on test cleanup.
+//  Skips test if Postgres binaries not found or cluster can not be started.
+func startLocalPostgres(t *testing.T) string {
+	initdb, pgctl := postgresBinary("initdb"), postgresBinary("pg_ctl")
+	if initdb == "" || pgctl == "" {
+		t.Skipf("%v не задан, Postgres не установлен", testDSNEnv)
+	}
+	if os.Geteuid() == 0 {
+		t.Skipf("%v не задан, Postgres не запускается от root", testDSNEnv)
+	}
+
+	dir := t.TempDir()
+	dataDir := filepath.Join(dir, "data")
+	if out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "-A", "trust").CombinedOutput(); err != nil {
+		t.Skipf("ошибка инициализации Postgres: %v: %s", err, out)
+	}
+
+	port, err := freePort()
+	require.NoError(t, err)
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=''", port, dir)
+	if out, err := exec.Command(pgctl, "-D", dataDir, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start").CombinedOutput(); err != nil {
+		t.Skipf("ошибка запуска Postgres: %v: %s", err, out)
+	}
+	t.Cleanup(func() {
+		exec.Command(pgctl, "-D", dataDir, "-m", "fast", "-w", "stop").Run()
+	})
+
+	return fmt.Sprintf("host=%s port=%d user=postgres dbname=postgres sslmode=disable", dir, port)
+}
+
+//  postgresBinary returns path of Postgres binary from PATH or default install directory, empty if not found.
+func postgresBinary(name string) string {
+	if path, err := exec.LookPath(name); err == nil {
+		return path
+	}
+
+	paths, _ := filepath.Glob(filepath.Join("/usr/lib/postgresql/*/bin", name))
+	if len(paths) == 0 {
+		return ""
+	}
+
+	return paths[len(paths)-1]
+}
+
+//  freePort returns free tcp port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
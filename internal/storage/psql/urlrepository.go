@@ -6,8 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/lib/pq"
-	"log"
-	"sync"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgerrcode"
@@ -15,6 +16,7 @@ import (
 	"github.com/atrush/pract_01.git/internal/model"
 	"github.com/atrush/pract_01.git/internal/shterrors"
 	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
 	"github.com/atrush/pract_01.git/internal/storage/schema"
 )
 
@@ -22,169 +24,56 @@ var _ st.URLRepository = (*shortURLRepository)(nil)
 
 //  shortURLRepository implements URLRepository interface, provides actions with url records in psql storage.
 type shortURLRepository struct {
-	db              *sql.DB
-	insertBuffer    URLBuffer
-	deleteChan      chan schema.ShortURL
-	flushDeleteChan chan struct{}
-	asyncEnded      chan struct{}
-	wg              *sync.WaitGroup
-}
-
-//  URLBuffer is buffeer for batch inserting.
-type URLBuffer struct {
-	buf []model.ShortURL
-	sync.Mutex
+	db          *sql.DB
+	deleteQueue *deletequeue.Queue
 }
 
 const (
-	delBuffBatch = 10 //  size of buffer for batch delete.
+	//  urlColumns is list of urls table columns in scanURL order.
+	urlColumns = "id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at"
 )
 
 //  newShortURLRepository inits new url repository.
-func newShortURLRepository(ctx context.Context, db *sql.DB, asyncEnded chan struct{}) *shortURLRepository {
-	repo := shortURLRepository{
+//  Delete jobs are processed by async queue, on context done queue is processed and signal sent to asyncEnded.
+func newShortURLRepository(ctx context.Context, db *sql.DB, asyncEnded chan struct{}, opts ...deletequeue.Option) *shortURLRepository {
+	repo := &shortURLRepository{
 		db: db,
-		wg: &sync.WaitGroup{},
-		insertBuffer: URLBuffer{
-			buf: make([]model.ShortURL, 0, 100),
-		},
-		deleteChan:      make(chan schema.ShortURL),
-		flushDeleteChan: make(chan struct{}),
-		asyncEnded:      asyncEnded,
-	}
-	repo.waitAsync(ctx)
-	repo.initDeleteBatchWorker()
-
-	return &repo
-}
-
-func (r *shortURLRepository) waitAsync(ctx context.Context) {
-	go func() {
-		<-ctx.Done()
-		r.wg.Wait()
-		close(r.deleteChan)
-	}()
-}
-
-//  DeleteURLBatch runs goroutine that adds list of urls to delete buffer.
-//  Flushes delete buffer after adding urls.
-func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) error {
-	if len(shortIDList) == 0 {
-		return nil
 	}
-	r.wg.Add(1)
-	go func() {
-		defer r.wg.Done()
+	repo.deleteQueue = deletequeue.New(ctx, repo, asyncEnded, opts...)
 
-		for _, v := range shortIDList {
-			r.deleteChan <- schema.ShortURL{ShortID: v, UserID: userID}
-		}
-
-		//  run flush on end of list
-		r.flushDeleteChan <- struct{}{}
-	}()
-
-	return nil
+	return repo
 }
 
-//  initDeleteBatchWorker runs single delete worker, that takes URLs from deleteChan
-//  and delete when filling the cache, or when take signal from flushDeleteChan.
-func (r *shortURLRepository) initDeleteBatchWorker() {
-	go func() {
-		cache := make([]schema.ShortURL, 0, delBuffBatch)
-		for {
-			select {
-			// read URL to delete from deleteChan
-			case v, ok := <-r.deleteChan:
-				if !ok { // if chanel closed, write buff and send to asyncEnded
-					if len(cache) == 0 {
-						r.asyncEnded <- struct{}{}
-						return
-					}
-				}
-				cache = append(cache, v)
-				if len(cache) < cap(cache) {
-					continue
-				}
-			// read flush signal
-			case <-r.flushDeleteChan:
-				if len(cache) == 0 {
-					continue
-				}
-			}
-			if err := r.deleteTxURLBatch(cache); err != nil {
-				log.Fatalf("ошибка транзакции удаления очереди URL:%v", err.Error())
-			}
-			cache = make([]schema.ShortURL, 0, delBuffBatch)
-
+//  DeleteURLBatch saves delete job and adds it to async delete queue without blocking.
+//  Returns shterrors.ErrDeleteQueueFull if queue has no space for list,
+//  shterrors.ErrDeleteListTooLarge if list is larger than queue.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job := model.NewDeleteJob(userID, shortIDList)
+	if len(shortIDList) == 0 {
+		job.Finish(model.DeleteJobDone, "", job.CreatedAt)
+		if err := insertDeleteJob(r.db, job); err != nil {
+			return model.DeleteJob{}, err
 		}
-	}()
-}
-
-//  deleteTxURLBatch marks array of urls as deleted with transaction.
-func (r *shortURLRepository) deleteTxURLBatch(urls []schema.ShortURL) (err error) {
-	tx, err := r.db.Begin()
-	if err != nil {
-		return
+		return job, nil
 	}
 
-	// defer make rollback
-	defer func() {
-		if err != nil {
-			if rollErr := tx.Rollback(); rollErr != nil {
-				err = fmt.Errorf("ошибка транзакции удаления:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
-			}
-		}
-	}()
-
-	stmt, err := tx.Prepare("UPDATE urls SET isdeleted = TRUE WHERE shorturl = $1 AND user_id = $2")
+	err := r.deleteQueue.Add(job, func() error {
+		return insertDeleteJob(r.db, job)
+	})
 	if err != nil {
-		return
-	}
-
-	for _, sht := range urls {
-		_, err = stmt.Exec(sht.ShortID, sht.UserID)
-		if err != nil {
-			return
-		}
-	}
-
-	if err = tx.Commit(); err != nil {
-		return
+		return model.DeleteJob{}, err
 	}
 
-	return nil
+	return job, nil
 }
 
-//  SaveURLBuff saves array of urls using buffer.
-func (r *shortURLRepository) SaveURLBuff(sht *model.ShortURL) error {
-	if sht == nil {
-		return errors.New("URL is nil")
-	}
-
-	r.insertBuffer.Lock()
-	defer r.insertBuffer.Unlock()
-
-	r.insertBuffer.buf = append(r.insertBuffer.buf, *sht)
-
-	if cap(r.insertBuffer.buf) == len(r.insertBuffer.buf) {
-		err := r.saveURLBuffFlushNoLock()
-		if err != nil {
-			return fmt.Errorf("ошибка хранилица:%w", err)
-		}
-	}
-	return nil
-}
-
-//  SaveURLBuffFlush locks mutex and runs save buffer flush.
-func (r *shortURLRepository) SaveURLBuffFlush() error {
-	r.insertBuffer.Lock()
-	defer r.insertBuffer.Unlock()
-
-	return r.saveURLBuffFlushNoLock()
+//  DeleteQueueStats returns state of async delete queue.
+func (r *shortURLRepository) DeleteQueueStats() model.DeleteQueueStats {
+	return r.deleteQueue.Stats()
 }
 
 //  SaveURL saves url to database.
+//  If source url is held by expired url, expired url is marked and url is saved.
 func (r *shortURLRepository) SaveURL(ctx context.Context, sht model.ShortURL) (model.ShortURL, error) {
 	dbObj, err := schema.NewURLFromCanonical(sht)
 	if err != nil {
@@ -193,19 +82,30 @@ func (r *shortURLRepository) SaveURL(ctx context.Context, sht model.ShortURL) (m
 
 	row := r.db.QueryRowContext(
 		ctx,
-		"INSERT INTO urls (id, user_id, srcurl, shorturl, isdeleted) VALUES ($1, $2, $3, $4, $5) RETURNING id ",
+		"INSERT INTO urls (id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id ",
 		dbObj.ID,
 		dbObj.UserID,
 		dbObj.URL,
 		dbObj.ShortID,
 		dbObj.IsDeleted,
+		nullTime(dbObj.ExpiresAt),
+		dbObj.IsExpired,
+		dbObj.CreatedAt,
 	)
 
 	if row.Err() != nil {
-		// check duplicate srcurl
 		pqErr, ok := row.Err().(*pq.Error)
+		// check duplicate srcurl
 		if ok && pqErr.Code == pgerrcode.UniqueViolation && pqErr.Constraint == "urls_srcurl_key" {
-			existURL, err := r.GetShortURLBySrcURL(ctx, sht.URL)
+			released, err := releaseExpiredSrcURL(ctx, r.db, time.Now(), sht.URL)
+			if err != nil {
+				return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+			}
+			if released > 0 {
+				return r.SaveURL(ctx, sht)
+			}
+
+			existURL, err := r.GetURLBySrcURL(ctx, sht.URL)
 			if err != nil {
 				return model.ShortURL{}, fmt.Errorf("ошибка добавления записи в БД, ссылка %v уже существует: ошибка получения существующей короткой ссыки: %w",
 					sht.URL, err)
@@ -213,6 +113,15 @@ func (r *shortURLRepository) SaveURL(ctx context.Context, sht model.ShortURL) (m
 			return model.ShortURL{}, &shterrors.ErrorConflictSaveURL{
 				Err:           row.Err(),
 				ExistShortURL: existURL.ShortID,
+				Code:          shterrors.ConflictCodeURL,
+			}
+		}
+		// check duplicate shorturl
+		if ok && pqErr.Code == pgerrcode.UniqueViolation && pqErr.Constraint == "urls_shorturl_key" {
+			return model.ShortURL{}, &shterrors.ErrorConflictSaveURL{
+				Err:           row.Err(),
+				ExistShortURL: sht.ShortID,
+				Code:          shterrors.ConflictCodeAlias,
 			}
 		}
 	}
@@ -226,12 +135,16 @@ func (r *shortURLRepository) SaveURL(ctx context.Context, sht model.ShortURL) (m
 }
 
 //  GetURL selects url from database by shortID, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist.
 func (r *shortURLRepository) GetURL(ctx context.Context, shortID string) (model.ShortURL, error) {
-	dbObj := schema.ShortURL{}
-	err := r.db.QueryRow(
-		"select id, user_id, srcurl, shorturl, isdeleted from urls where shorturl = $1", shortID,
-	).Scan(&dbObj.ID, &dbObj.UserID, &dbObj.URL, &dbObj.ShortID, &dbObj.IsDeleted)
+	dbObj, err := scanURL(r.db.QueryRowContext(
+		ctx,
+		"select "+urlColumns+" from urls where shorturl = $1", shortID,
+	))
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ShortURL{}, shterrors.ErrNotFound
+	}
 	if err != nil {
 		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
 	}
@@ -239,13 +152,17 @@ func (r *shortURLRepository) GetURL(ctx context.Context, shortID string) (model.
 	return dbObj.ToCanonical()
 }
 
-//  GetShortURLBySrcURL selects url from database by url, returns as canonical ShortURL.
-func (r *shortURLRepository) GetShortURLBySrcURL(ctx context.Context, url string) (model.ShortURL, error) {
-	dbObj := schema.ShortURL{}
-	err := r.db.QueryRow(
-		"select id, user_id, srcurl, shorturl, isdeleted from urls where srcurl = $1", url,
-	).Scan(&dbObj.ID, &dbObj.UserID, &dbObj.URL, &dbObj.ShortID, &dbObj.IsDeleted)
+//  GetURLBySrcURL selects not expired url from database by source url, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist or expired.
+func (r *shortURLRepository) GetURLBySrcURL(ctx context.Context, url string) (model.ShortURL, error) {
+	dbObj, err := scanURL(r.db.QueryRowContext(
+		ctx,
+		"select "+urlColumns+" from urls where srcurl = $1 and isexpired = false and (expires_at is null or expires_at > now())", url,
+	))
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ShortURL{}, shterrors.ErrNotFound
+	}
 	if err != nil {
 		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
 	}
@@ -253,23 +170,21 @@ func (r *shortURLRepository) GetShortURLBySrcURL(ctx context.Context, url string
 	return dbObj.ToCanonical()
 }
 
-//  GetUserURLList selects list of url from database by userID, returns as list of canonical ShortURL.
-func (r *shortURLRepository) GetUserURLList(ctx context.Context, userID uuid.UUID, limit int) ([]model.ShortURL, error) {
-	var userURLs schema.URLList
-	userURLs = make([]schema.ShortURL, 0, limit)
+//  GetUserURLList selects page of user urls from database by filter, ordered by creation time and id.
+//  Returns list of canonical ShortURL.
+func (r *shortURLRepository) GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) ([]model.ShortURL, error) {
+	query, args := userURLListQuery(userID, filter)
 
-	rows, err := r.db.QueryContext(
-		ctx,
-		"SELECT id, user_id, srcurl, shorturl, isdeleted from urls WHERE user_id = $1 LIMIT $2", userID, limit)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	defer rows.Close()
 
+	userURLs := make(schema.URLList, 0, filter.Limit)
 	for rows.Next() {
-		var s schema.ShortURL
-		err = rows.Scan(&s.ID, &s.UserID, &s.URL, &s.ShortID, &s.IsDeleted)
+		s, err := scanURL(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -284,17 +199,93 @@ func (r *shortURLRepository) GetUserURLList(ctx context.Context, userID uuid.UUI
 	return userURLs.ToCanonical()
 }
 
-//  GetCount returns count of stored, not deleted urls.
+//  userURLListQuery builds select query of user urls page with filter conditions.
+func userURLListQuery(userID uuid.UUID, filter model.URLListFilter) (string, []interface{}) {
+	args := []interface{}{userID}
+	where := []string{"user_id = $1"}
+
+	addCond := func(cond string, vals ...interface{}) {
+		for _, v := range vals {
+			args = append(args, v)
+			cond = strings.Replace(cond, "?", "$"+strconv.Itoa(len(args)), 1)
+		}
+		where = append(where, cond)
+	}
+
+	if !filter.IncludeDeleted {
+		addCond("isdeleted = FALSE")
+	}
+	if filter.URLContains != "" {
+		addCond("strpos(srcurl, ?) > 0", filter.URLContains)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		addCond("created_at > ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		addCond("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.After != nil {
+		addCond("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	args = append(args, filter.Limit)
+	query := "SELECT " + urlColumns + " FROM urls WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY created_at, id LIMIT $" + strconv.Itoa(len(args))
+
+	return query, args
+}
+
+//  GetCount returns count of stored, not deleted and not expired urls.
 func (r *shortURLRepository) GetCount() (int, error) {
 	count := 0
 	err := r.db.QueryRow(
-		"SELECT  COUNT(*) as count FROM urls WHERE isdeleted = $1", false).Scan(&count)
+		"SELECT  COUNT(*) as count FROM urls WHERE isdeleted = $1 AND isexpired = $1 AND (expires_at IS NULL OR expires_at > now())",
+		false).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
 
+//  MarkExpired marks urls with expiration time before now as expired, returns list of marked shortIDs.
+func (r *shortURLRepository) MarkExpired(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"UPDATE urls SET isexpired = TRUE WHERE isexpired = FALSE AND expires_at <= $1 RETURNING shorturl", now)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	defer rows.Close()
+
+	expired := make([]string, 0)
+	for rows.Next() {
+		var shortID string
+		if err := rows.Scan(&shortID); err != nil {
+			return nil, fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		expired = append(expired, shortID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	return expired, nil
+}
+
+//  releaseExpiredSrcURL marks urls with source urls and expiration time before now as expired,
+//  so source urls are removed from unique index and can be saved again. Returns count of marked urls.
+func releaseExpiredSrcURL(ctx context.Context, db execer, now time.Time, srcURLs ...string) (int64, error) {
+	res, err := db.ExecContext(ctx,
+		"UPDATE urls SET isexpired = TRUE WHERE isexpired = FALSE AND expires_at <= $1 AND srcurl = ANY($2)",
+		now, pq.Array(srcURLs))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
 //  Exist checks that shortID exist in database.
 func (r *shortURLRepository) Exist(shortID string) (bool, error) {
 	count := 0
@@ -306,16 +297,20 @@ func (r *shortURLRepository) Exist(shortID string) (bool, error) {
 	return count > 0, nil
 }
 
-// saveURLBuffFlushNoLock saves array of urls to database, using transaction.
-func (r *shortURLRepository) saveURLBuffFlushNoLock() (err error) {
-	tx, err := r.db.Begin()
+//  SaveURLBatch saves list of urls to database in one transaction.
+//  If any url is not saved, no urls are saved.
+func (r *shortURLRepository) SaveURLBatch(ctx context.Context, urls []model.ShortURL) (err error) {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return
 	}
 
-	// defer make rollback and clean buffer
+	// defer make rollback
 	defer func() {
-		r.insertBuffer.buf = r.insertBuffer.buf[:0]
 		if err != nil {
 			if rollErr := tx.Rollback(); rollErr != nil {
 				err = fmt.Errorf("ошибка транзакции сохранения:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
@@ -323,29 +318,39 @@ func (r *shortURLRepository) saveURLBuffFlushNoLock() (err error) {
 		}
 	}()
 
-	stmt, err := tx.Prepare("INSERT INTO urls(id, user_id, srcurl, shorturl, isdeleted) VALUES($1, $2, $3, $4, $5)RETURNING id")
+	srcURLs := make([]string, 0, len(urls))
+	for _, sht := range urls {
+		srcURLs = append(srcURLs, sht.URL)
+	}
+	if _, err = releaseExpiredSrcURL(ctx, tx, time.Now(), srcURLs...); err != nil {
+		return
+	}
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO urls(id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8)RETURNING id")
 	if err != nil {
 		return
 	}
 
-	for _, sht := range r.insertBuffer.buf {
+	for _, sht := range urls {
 		var dbObj schema.ShortURL
 		dbObj, err = schema.NewURLFromCanonical(sht)
 		if err != nil {
-			continue
+			return
 		}
 
-		if err = stmt.QueryRow(
+		if err = stmt.QueryRowContext(ctx,
 			dbObj.ID,
 			dbObj.UserID,
 			dbObj.URL,
 			dbObj.ShortID,
-			dbObj.IsDeleted).Scan(&dbObj.ID); err != nil {
+			dbObj.IsDeleted,
+			nullTime(dbObj.ExpiresAt),
+			dbObj.IsExpired,
+			dbObj.CreatedAt).Scan(&dbObj.ID); err != nil {
 			err = fmt.Errorf("ошибка транзакции сохранения dbObj- %v :%w ", dbObj.UserID.String(), err)
 
 			return
 		}
-		sht.ID = dbObj.ID
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -354,3 +359,34 @@ func (r *shortURLRepository) saveURLBuffFlushNoLock() (err error) {
 
 	return nil
 }
+
+//  rowScanner is common interface of sql.Row and sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+//  execer is common interface of sql.DB and sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+//  scanURL scans url record with urlColumns.
+func scanURL(row rowScanner) (schema.ShortURL, error) {
+	dbObj := schema.ShortURL{}
+	expiresAt := sql.NullTime{}
+
+	err := row.Scan(&dbObj.ID, &dbObj.UserID, &dbObj.URL, &dbObj.ShortID, &dbObj.IsDeleted, &expiresAt, &dbObj.IsExpired, &dbObj.CreatedAt)
+	if err != nil {
+		return schema.ShortURL{}, err
+	}
+
+	if expiresAt.Valid {
+		dbObj.ExpiresAt = expiresAt.Time
+	}
+	return dbObj, nil
+}
+
+//  nullTime converts time to sql.NullTime, zero time converts to NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
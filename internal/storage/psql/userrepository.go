@@ -38,9 +38,10 @@ func (r *userRepository) AddUser(ctx context.Context, user model.User) (model.Us
 }
 
 //  Exist checks that user is exist in database.
-func (r *userRepository) Exist(userID uuid.UUID) (bool, error) {
+func (r *userRepository) Exist(ctx context.Context, userID uuid.UUID) (bool, error) {
 	count := 0
-	err := r.db.QueryRow(
+	err := r.db.QueryRowContext(
+		ctx,
 		"SELECT  COUNT(*) as count FROM users WHERE id = $1", userID).Scan(&count)
 
 	if err != nil {
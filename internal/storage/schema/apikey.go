@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+type (
+	//  APIKey storage api key entity, contains only hash of key.
+	APIKey struct {
+		ID        uuid.UUID
+		UserID    uuid.UUID
+		Name      string
+		Prefix    string
+		Hash      string
+		CreatedAt time.Time
+		RevokedAt time.Time
+	}
+	//  APIKeyList list of storage api key entities.
+	APIKeyList []APIKey
+)
+
+//  NewAPIKeyFromCanonical creates a new api key storage object from canonical model.
+func NewAPIKeyFromCanonical(obj model.APIKey) (APIKey, error) {
+	if err := obj.Validate(); err != nil {
+		return APIKey{}, fmt.Errorf("status: %w", err)
+	}
+
+	return APIKey{
+		ID:        obj.ID,
+		UserID:    obj.UserID,
+		Name:      obj.Name,
+		Prefix:    obj.Prefix,
+		Hash:      obj.Hash,
+		CreatedAt: obj.CreatedAt,
+		RevokedAt: obj.RevokedAt,
+	}, nil
+}
+
+//  ToCanonical converts a storage api key object to canonical model.
+func (k APIKey) ToCanonical() (model.APIKey, error) {
+	obj := model.APIKey{
+		ID:        k.ID,
+		UserID:    k.UserID,
+		Name:      k.Name,
+		Prefix:    k.Prefix,
+		Hash:      k.Hash,
+		CreatedAt: k.CreatedAt,
+		RevokedAt: k.RevokedAt,
+	}
+
+	if err := obj.Validate(); err != nil {
+		return model.APIKey{}, fmt.Errorf("status: %w", err)
+	}
+	return obj, nil
+}
+
+//  ToCanonical converts a list of storage api key objects to list of canonical models.
+func (l APIKeyList) ToCanonical() ([]model.APIKey, error) {
+	objs := make([]model.APIKey, 0, len(l))
+	for _, v := range l {
+		obj, err := v.ToCanonical()
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/atrush/pract_01.git/internal/model"
+)
+
+//  Max length of stored click text fields.
+const (
+	maxReferrerLen  = 2048
+	maxUserAgentLen = 512
+	maxIPLen        = 45
+)
+
+type (
+	//  Click storage redirect entity.
+	Click struct {
+		ShortID   string
+		ClickedAt time.Time
+		Referrer  string
+		UserAgent string
+		IP        string
+	}
+)
+
+//  NewClickFromCanonical creates a new Click storage object from canonical model.
+func NewClickFromCanonical(obj model.Click) (Click, error) {
+	if err := obj.Validate(); err != nil {
+		return Click{}, fmt.Errorf("status: %w", err)
+	}
+
+	return Click{
+		ShortID:   obj.ShortID,
+		ClickedAt: obj.ClickedAt,
+		Referrer:  truncate(obj.Referrer, maxReferrerLen),
+		UserAgent: truncate(obj.UserAgent, maxUserAgentLen),
+		IP:        truncate(obj.IP, maxIPLen),
+	}, nil
+}
+
+//  truncate removes invalid UTF-8 sequences and cuts string to max length in bytes on rune boundary.
+func truncate(s string, max int) string {
+	s = strings.ToValidUTF8(s, "")
+	if len(s) <= max {
+		return s
+	}
+
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
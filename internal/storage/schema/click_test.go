@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		max  int
+		want string
+	}{
+		{name: "short", s: "test", max: 10, want: "test"},
+		{name: "ascii cut", s: "test", max: 2, want: "te"},
+		{name: "multibyte cut at limit", s: "aпривет", max: 4, want: "aп"},
+		{name: "multibyte on limit", s: "aпривет", max: 5, want: "aпр"},
+		{name: "invalid utf8", s: "te\xffst", max: 10, want: "test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.max)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+
+	//  user agent of multibyte runes cut at max length
+	got := truncate("a"+strings.Repeat("я", maxUserAgentLen), maxUserAgentLen)
+	assert.True(t, utf8.ValidString(got))
+	assert.Equal(t, "a"+strings.Repeat("я", maxUserAgentLen/2-1), got)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -18,6 +19,9 @@ type (
 		URL       string    `validate:"required,max=2048"`
 		UserID    uuid.UUID `validate:"required"`
 		IsDeleted bool
+		ExpiresAt time.Time
+		IsExpired bool
+		CreatedAt time.Time
 	}
 	//  URLList list of storage url entityes.
 	URLList []ShortURL
@@ -31,6 +35,9 @@ func NewURLFromCanonical(obj model.ShortURL) (ShortURL, error) {
 		URL:       obj.URL,
 		UserID:    obj.UserID,
 		IsDeleted: obj.IsDeleted,
+		ExpiresAt: obj.ExpiresAt,
+		IsExpired: obj.IsExpired,
+		CreatedAt: obj.CreatedAt,
 	}
 	if err := dbObj.Validate(); err != nil {
 		return ShortURL{}, err
@@ -46,6 +53,9 @@ func (o ShortURL) ToCanonical() (model.ShortURL, error) {
 		URL:       o.URL,
 		UserID:    o.UserID,
 		IsDeleted: o.IsDeleted,
+		ExpiresAt: o.ExpiresAt,
+		IsExpired: o.IsExpired,
+		CreatedAt: o.CreatedAt,
 	}
 
 	if err := obj.Validate(); err != nil {
@@ -68,6 +78,16 @@ func (o URLList) ToCanonical() ([]model.ShortURL, error) {
 	return objs, nil
 }
 
+//  Expired checks that url is marked as expired or expiration time has come.
+//  Expired url does not hold its source url, same url can be saved again.
+func (o ShortURL) Expired(now time.Time) bool {
+	if o.IsExpired {
+		return true
+	}
+
+	return !o.ExpiresAt.IsZero() && !o.ExpiresAt.After(now)
+}
+
 //  Validate validates storage url object.
 func (o ShortURL) Validate() error {
 	if o.ID == uuid.Nil {
@@ -0,0 +1,214 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/logging"
+	"github.com/atrush/pract_01.git/internal/model"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ st.ClickRepository = (*clickRepository)(nil)
+
+//  clickRepository implements ClickRepository interface, provides actions with click records in sqlite storage.
+type clickRepository struct {
+	db         *sql.DB
+	clickChan  chan schema.Click
+	asyncEnded chan struct{}
+	closed     bool
+	logger     *slog.Logger
+	sync.RWMutex
+}
+
+const (
+	clickBuffBatch     = 100         //  size of buffer for batch insert clicks.
+	clickChanSize      = 1000        //  size of incoming clicks queue.
+	clickFlushInterval = time.Second //  interval of flushing not full clicks buffer.
+
+	//  insertClickQuery inserts click.
+	insertClickQuery = "INSERT INTO clicks (shorturl, clicked_at, referrer, user_agent, ip) VALUES (?, ?, ?, ?, ?)"
+
+	//  maxReferrers is max count of referrers in stats.
+	maxReferrers = 10
+)
+
+//  newClickRepository inits new click repository.
+func newClickRepository(ctx context.Context, db *sql.DB, asyncEnded chan struct{}) *clickRepository {
+	repo := clickRepository{
+		db:         db,
+		clickChan:  make(chan schema.Click, clickChanSize),
+		asyncEnded: asyncEnded,
+		logger:     logging.FromContext(ctx),
+	}
+	repo.waitAsync(ctx)
+	repo.initClickBatchWorker()
+
+	return &repo
+}
+
+//  waitAsync closes clicks queue on context done.
+func (r *clickRepository) waitAsync(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+
+		r.Lock()
+		defer r.Unlock()
+		r.closed = true
+		close(r.clickChan)
+	}()
+}
+
+//  AddClick adds click to queue without blocking.
+//  If queue is full, click is dropped.
+func (r *clickRepository) AddClick(click model.Click) error {
+	dbObj, err := schema.NewClickFromCanonical(click)
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.closed {
+		return errors.New("очередь записи переходов закрыта")
+	}
+
+	select {
+	case r.clickChan <- dbObj:
+		return nil
+	default:
+		return errors.New("очередь записи переходов переполнена")
+	}
+}
+
+//  initClickBatchWorker runs single click worker, that takes clicks from clickChan
+//  and inserts when filling the cache, or by flush interval.
+func (r *clickRepository) initClickBatchWorker() {
+	go func() {
+		ticker := time.NewTicker(clickFlushInterval)
+		defer ticker.Stop()
+
+		cache := make([]schema.Click, 0, clickBuffBatch)
+		for {
+			select {
+			// read click from clickChan
+			case v, ok := <-r.clickChan:
+				if !ok { // if chanel closed, write buff and send to asyncEnded
+					if len(cache) > 0 {
+						r.saveClickBatch(cache)
+					}
+					r.asyncEnded <- struct{}{}
+					return
+				}
+				cache = append(cache, v)
+				if len(cache) < cap(cache) {
+					continue
+				}
+			// flush by interval
+			case <-ticker.C:
+				if len(cache) == 0 {
+					continue
+				}
+			}
+			r.saveClickBatch(cache)
+			cache = make([]schema.Click, 0, clickBuffBatch)
+		}
+	}()
+}
+
+//  saveClickBatch inserts clicks with transaction. If transaction fails, clicks are inserted one by one,
+//  so invalid click does not drop other clicks of batch. Errors are logged.
+func (r *clickRepository) saveClickBatch(clicks []schema.Click) {
+	err := r.insertTxClickBatch(clicks)
+	if err == nil {
+		return
+	}
+	r.logger.Warn("ошибка транзакции сохранения переходов, переходы сохраняются по одному", "error", err.Error())
+
+	for _, c := range clicks {
+		if _, err := r.db.Exec(insertClickQuery, c.ShortID, unixTime(c.ClickedAt), c.Referrer, c.UserAgent, c.IP); err != nil {
+			r.logger.Error("ошибка сохранения перехода", "short_id", c.ShortID, "error", err.Error())
+		}
+	}
+}
+
+//  insertTxClickBatch inserts array of clicks with transaction.
+func (r *clickRepository) insertTxClickBatch(clicks []schema.Click) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+
+	// defer make rollback
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(); rollErr != nil {
+				err = fmt.Errorf("ошибка транзакции сохранения:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
+			}
+		}
+	}()
+
+	stmt, err := tx.Prepare(insertClickQuery)
+	if err != nil {
+		return
+	}
+	defer stmt.Close()
+
+	for _, c := range clicks {
+		_, err = stmt.Exec(c.ShortID, unixTime(c.ClickedAt), c.Referrer, c.UserAgent, c.IP)
+		if err != nil {
+			return
+		}
+	}
+
+	return tx.Commit()
+}
+
+//  GetStats returns aggregated redirect stats for shortID.
+func (r *clickRepository) GetStats(ctx context.Context, shortID string) (model.ClickStats, error) {
+	stats := model.ClickStats{
+		ShortID:   shortID,
+		Referrers: make(map[string]int),
+	}
+
+	lastClickAt := sql.NullInt64{}
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*), COUNT(DISTINCT ip), MAX(clicked_at) FROM clicks WHERE shorturl = ?", shortID,
+	).Scan(&stats.Clicks, &stats.UniqueIPs, &lastClickAt)
+	if err != nil {
+		return model.ClickStats{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	stats.LastClickAt = fromUnixTime(lastClickAt)
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT referrer, COUNT(*) as count FROM clicks WHERE shorturl = ? AND referrer <> '' GROUP BY referrer ORDER BY count DESC LIMIT ?",
+		shortID, maxReferrers)
+	if err != nil {
+		return model.ClickStats{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var referrer string
+		var count int
+		if err := rows.Scan(&referrer, &count); err != nil {
+			return model.ClickStats{}, fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		stats.Referrers[referrer] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return model.ClickStats{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	return stats, nil
+}
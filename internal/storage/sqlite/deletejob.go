@@ -0,0 +1,174 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
+)
+
+var _ deletequeue.Processor = (*shortURLRepository)(nil)
+
+//  deleteJobColumns is list of delete_jobs table columns in scanDeleteJob order.
+const deleteJobColumns = "id, user_id, shorturls, status, error, created_at, finished_at"
+
+//  GetDeleteJob selects delete job from database by id.
+//  Returns shterrors.ErrDeleteJobNotFound if job not exist.
+func (r *shortURLRepository) GetDeleteJob(ctx context.Context, jobID uuid.UUID) (model.DeleteJob, error) {
+	job, err := scanDeleteJob(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+deleteJobColumns+" FROM delete_jobs WHERE id = ?", jobID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DeleteJob{}, shterrors.ErrDeleteJobNotFound
+	}
+	if err != nil {
+		return model.DeleteJob{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return job, nil
+}
+
+//  DeleteJobs marks urls of jobs as deleted and jobs as done with transaction.
+func (r *shortURLRepository) DeleteJobs(jobs []model.DeleteJob) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return
+	}
+
+	// defer make rollback
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(); rollErr != nil {
+				err = fmt.Errorf("ошибка транзакции удаления:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
+			}
+		}
+	}()
+
+	finishedAt := unixTime(time.Now())
+	for _, job := range jobs {
+		args := make([]interface{}, 0, len(job.ShortIDs)+1)
+		args = append(args, job.UserID)
+		for _, v := range job.ShortIDs {
+			args = append(args, v)
+		}
+
+		query := "UPDATE urls SET isdeleted = TRUE WHERE user_id = ? AND shorturl IN (" + placeholders(len(job.ShortIDs)) + ")"
+		if _, err = tx.Exec(query, args...); err != nil {
+			return
+		}
+
+		_, err = tx.Exec("UPDATE delete_jobs SET status = ?, finished_at = ? WHERE id = ?", model.DeleteJobDone, finishedAt, job.ID)
+		if err != nil {
+			return
+		}
+	}
+
+	return tx.Commit()
+}
+
+//  FailJobs marks jobs as failed with delete error.
+func (r *shortURLRepository) FailJobs(jobs []model.DeleteJob, deleteErr error) error {
+	args := make([]interface{}, 0, len(jobs)+3)
+	args = append(args, model.DeleteJobFailed, deleteErr.Error(), unixTime(time.Now()))
+	for _, job := range jobs {
+		args = append(args, job.ID)
+	}
+
+	_, err := r.db.Exec(
+		"UPDATE delete_jobs SET status = ?, error = ?, finished_at = ? WHERE id IN ("+placeholders(len(jobs))+")", args...)
+	if err != nil {
+		return fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return nil
+}
+
+//  insertDeleteJob saves delete job to database, list of shortIDs is stored as json array.
+func insertDeleteJob(db *sql.DB, job model.DeleteJob) error {
+	shortIDs, err := json.Marshal(job.ShortIDs)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения задачи удаления:%w", err)
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO delete_jobs ("+deleteJobColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		job.ID,
+		job.UserID,
+		string(shortIDs),
+		job.Status,
+		job.Error,
+		unixTime(job.CreatedAt),
+		nullUnixTime(job.FinishedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения задачи удаления:%w", err)
+	}
+
+	return nil
+}
+
+//  loadPendingDeleteJobs selects not processed delete jobs, ordered by creation time.
+func loadPendingDeleteJobs(db *sql.DB) ([]model.DeleteJob, error) {
+	rows, err := db.Query(
+		"SELECT "+deleteJobColumns+" FROM delete_jobs WHERE status = ? ORDER BY created_at", model.DeleteJobPending)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+	}
+
+	defer rows.Close()
+
+	jobs := make([]model.DeleteJob, 0)
+	for rows.Next() {
+		job, err := scanDeleteJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения задач удаления:%w", err)
+	}
+	return jobs, nil
+}
+
+//  scanDeleteJob scans delete job record with deleteJobColumns.
+func scanDeleteJob(row rowScanner) (model.DeleteJob, error) {
+	job := model.DeleteJob{}
+	shortIDs := ""
+	status := ""
+	createdAt := sql.NullInt64{}
+	finishedAt := sql.NullInt64{}
+
+	err := row.Scan(&job.ID, &job.UserID, &shortIDs, &status, &job.Error, &createdAt, &finishedAt)
+	if err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	if err := json.Unmarshal([]byte(shortIDs), &job.ShortIDs); err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	job.Status = model.DeleteJobStatus(status)
+	job.CreatedAt = fromUnixTime(createdAt).UTC()
+	job.FinishedAt = fromUnixTime(finishedAt)
+	if !job.FinishedAt.IsZero() {
+		job.FinishedAt = job.FinishedAt.UTC()
+	}
+	return job, nil
+}
+
+//  placeholders returns list of count comma separated placeholders.
+func placeholders(count int) string {
+	return "?" + strings.Repeat(", ?", count-1)
+}
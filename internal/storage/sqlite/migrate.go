@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	sqlitemigrate "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+//  MigrationStatus represents state of database schema.
+type MigrationStatus struct {
+	Version uint //  applied schema version, 0 if no migrations applied
+	Dirty   bool //  true if last migration failed
+	Latest  uint //  latest schema version known by binary
+}
+
+//  ErrSchemaAhead returns if database schema version is newer than binary supports.
+var ErrSchemaAhead = errors.New("версия схемы БД новее версии приложения")
+
+//  MigrateUp applies all not applied migrations.
+//  Returns ErrSchemaAhead if database schema version is ahead of binary.
+func MigrateUp(conStringDSN string) error {
+	return withMigrate(conStringDSN, func(m *migrate.Migrate, latest uint) error {
+		if err := checkSchemaVersion(m, latest); err != nil {
+			return err
+		}
+
+		if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("ошибка применения миграций: %w", err)
+		}
+		return nil
+	})
+}
+
+//  MigrateDown rolls back last applied migration.
+func MigrateDown(conStringDSN string) error {
+	return withMigrate(conStringDSN, func(m *migrate.Migrate, latest uint) error {
+		if err := checkSchemaVersion(m, latest); err != nil {
+			return err
+		}
+
+		if err := m.Steps(-1); err != nil {
+			return fmt.Errorf("ошибка отката миграции: %w", err)
+		}
+		return nil
+	})
+}
+
+//  GetMigrationStatus returns applied and latest schema versions.
+func GetMigrationStatus(conStringDSN string) (MigrationStatus, error) {
+	status := MigrationStatus{}
+	err := withMigrate(conStringDSN, func(m *migrate.Migrate, latest uint) error {
+		version, dirty, err := schemaVersion(m)
+		if err != nil {
+			return err
+		}
+
+		status = MigrationStatus{
+			Version: version,
+			Dirty:   dirty,
+			Latest:  latest,
+		}
+		return nil
+	})
+
+	return status, err
+}
+
+//  withMigrate opens separate connection for migrations and runs fn with migrate instance.
+func withMigrate(conStringDSN string, fn func(m *migrate.Migrate, latest uint) error) error {
+	path, err := dbPath(conStringDSN)
+	if err != nil {
+		return fmt.Errorf("ошибка миграции бд: %w", err)
+	}
+
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return fmt.Errorf("ошибка миграции бд: %w", err)
+	}
+
+	src, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		db.Close()
+		return fmt.Errorf("ошибка чтения миграций: %w", err)
+	}
+
+	latest, err := latestVersion(src)
+	if err != nil {
+		src.Close()
+		db.Close()
+		return err
+	}
+
+	driver, err := sqlitemigrate.WithInstance(db, &sqlitemigrate.Config{})
+	if err != nil {
+		src.Close()
+		db.Close()
+		return fmt.Errorf("ошибка миграции бд: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, driverName, driver)
+	if err != nil {
+		src.Close()
+		driver.Close()
+		return fmt.Errorf("ошибка миграции бд: %w", err)
+	}
+	//  closes source, driver and db
+	defer m.Close()
+
+	return fn(m, latest)
+}
+
+//  checkSchemaVersion returns error if database schema version is unknown for binary.
+func checkSchemaVersion(m *migrate.Migrate, latest uint) error {
+	version, _, err := schemaVersion(m)
+	if err != nil {
+		return err
+	}
+
+	if version > latest {
+		return fmt.Errorf("%w: версия БД %v, версия приложения %v", ErrSchemaAhead, version, latest)
+	}
+	return nil
+}
+
+//  schemaVersion returns applied schema version, 0 if migrations not applied.
+func schemaVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("ошибка получения версии схемы БД: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+//  latestVersion returns latest migration version from source.
+func latestVersion(src source.Driver) (uint, error) {
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("ошибка чтения миграций: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return version, nil
+			}
+			return 0, fmt.Errorf("ошибка чтения миграций: %w", err)
+		}
+		version = next
+	}
+}
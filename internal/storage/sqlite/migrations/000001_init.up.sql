@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS users (
+    id text not null,
+    primary key (id)
+);
+
+CREATE TABLE IF NOT EXISTS urls (
+    id text not null,
+    user_id text not null,
+    srcurl varchar(2050) not null,
+    shorturl varchar(16) not null,
+    isdeleted boolean not null default false,
+    expires_at integer,
+    isexpired boolean not null default false,
+    created_at integer not null,
+    constraint urls_shorturl_key unique (shorturl),
+    constraint urls_srcurl_key unique (srcurl),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls (expires_at) WHERE isexpired = false;
+CREATE INDEX IF NOT EXISTS urls_user_created_idx ON urls (user_id, created_at, id);
+
+CREATE TABLE IF NOT EXISTS clicks (
+    id integer primary key autoincrement,
+    shorturl varchar(16) not null,
+    clicked_at integer not null,
+    referrer varchar(2048) not null default '',
+    user_agent varchar(512) not null default '',
+    ip varchar(45) not null default ''
+);
+
+CREATE INDEX IF NOT EXISTS clicks_shorturl_idx ON clicks (shorturl);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id text not null,
+    user_id text not null,
+    name varchar(64) not null default '',
+    prefix varchar(16) not null,
+    key_hash char(64) not null,
+    created_at integer not null,
+    revoked_at integer,
+    constraint api_keys_key_hash_key unique (key_hash),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS delete_jobs (
+    id text not null,
+    user_id text not null,
+    shorturls text not null,
+    status varchar(16) not null,
+    error text not null default '',
+    created_at integer not null,
+    finished_at integer,
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+CREATE INDEX IF NOT EXISTS delete_jobs_pending_idx ON delete_jobs (created_at) WHERE status = 'pending';
@@ -0,0 +1,27 @@
+DELETE FROM urls WHERE EXISTS (
+    SELECT 1 FROM urls u WHERE u.srcurl = urls.srcurl AND (u.created_at, u.id) > (urls.created_at, urls.id)
+);
+
+CREATE TABLE urls_old (
+    id text not null,
+    user_id text not null,
+    srcurl varchar(2050) not null,
+    shorturl varchar(16) not null,
+    isdeleted boolean not null default false,
+    expires_at integer,
+    isexpired boolean not null default false,
+    created_at integer not null,
+    constraint urls_shorturl_key unique (shorturl),
+    constraint urls_srcurl_key unique (srcurl),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+INSERT INTO urls_old (id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at)
+    SELECT id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at FROM urls;
+
+DROP TABLE urls;
+ALTER TABLE urls_old RENAME TO urls;
+
+CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls (expires_at) WHERE isexpired = false;
+CREATE INDEX IF NOT EXISTS urls_user_created_idx ON urls (user_id, created_at, id);
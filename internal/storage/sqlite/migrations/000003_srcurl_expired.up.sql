@@ -0,0 +1,23 @@
+CREATE TABLE urls_new (
+    id text not null,
+    user_id text not null,
+    srcurl varchar(2050) not null,
+    shorturl varchar(16) not null,
+    isdeleted boolean not null default false,
+    expires_at integer,
+    isexpired boolean not null default false,
+    created_at integer not null,
+    constraint urls_shorturl_key unique (shorturl),
+    primary key (id),
+    foreign key (user_id) references users (id)
+);
+
+INSERT INTO urls_new (id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at)
+    SELECT id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at FROM urls;
+
+DROP TABLE urls;
+ALTER TABLE urls_new RENAME TO urls;
+
+CREATE UNIQUE INDEX IF NOT EXISTS urls_srcurl_key ON urls (srcurl) WHERE isexpired = false;
+CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls (expires_at) WHERE isexpired = false;
+CREATE INDEX IF NOT EXISTS urls_user_created_idx ON urls (user_id, created_at, id);
@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
+	_ "modernc.org/sqlite"
+)
+
+var _ storage.Storage = (*Storage)(nil)
+
+const (
+	//  DSNPrefix is prefix of sqlite database dsn, followed by database file path.
+	DSNPrefix = "sqlite://"
+
+	//  driverName is name of pure go sqlite driver.
+	driverName = "sqlite"
+	//  busyTimeout is waiting time of locked database.
+	busyTimeout = 5 * time.Second
+)
+
+//  Storage implements Storage interface, provides storing data in sqlite database file.
+//  Uses single connection, sqlite allows only one writer.
+type Storage struct {
+	shortURLRepo *shortURLRepository
+	userRepo     *userRepository
+	clickRepo    *clickRepository
+	apiKeyRepo   *apiKeyRepository
+	db           *sql.DB
+	conStringDSN string
+
+	storageAsyncEnded chan struct{}
+	urlAsyncEnded     chan struct{}
+	clickAsyncEnded   chan struct{}
+	waitAsyncEnd      bool
+}
+
+//  IsDSN checks that dsn is sqlite database dsn.
+func IsDSN(conStringDSN string) bool {
+	return strings.HasPrefix(conStringDSN, DSNPrefix)
+}
+
+//  NewStorage opens sqlite database file from dsn "sqlite://<path>", creates file if not exist.
+//  On init applies not applied migrations, fails if database schema is ahead of binary.
+//  Options set params of async url delete queue. Async errors are logged by logger from context.
+func NewStorage(ctx context.Context, asyncEndedChan chan struct{}, conStringDSN string, opts ...deletequeue.Option) (*Storage, error) {
+	path, err := dbPath(conStringDSN)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации бд:%w", err)
+	}
+
+	if err := MigrateUp(conStringDSN); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(driverName, path)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	pragmas := []string{
+		"PRAGMA foreign_keys = ON",
+		"PRAGMA journal_mode = WAL",
+		fmt.Sprintf("PRAGMA busy_timeout = %d", busyTimeout.Milliseconds()),
+	}
+	for _, p := range pragmas {
+		if _, err := db.Exec(p); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("ошибка инициализации бд:%w", err)
+		}
+	}
+
+	st := &Storage{
+		db:           db,
+		conStringDSN: conStringDSN,
+	}
+
+	// chan for signal that tasks ended
+	st.storageAsyncEnded = asyncEndedChan
+	st.urlAsyncEnded = make(chan struct{})
+	st.clickAsyncEnded = make(chan struct{})
+
+	// init waiter
+	st.waitAsyncEnd = true
+
+	//  jobs accepted before restart are processed first
+	pending, err := loadPendingDeleteJobs(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	opts = append(opts, deletequeue.WithPending(pending))
+
+	st.shortURLRepo = newShortURLRepository(ctx, db, st.urlAsyncEnded, opts...)
+	st.userRepo = newUserRepository(db)
+	st.clickRepo = newClickRepository(ctx, db, st.clickAsyncEnded)
+	st.apiKeyRepo = newAPIKeyRepository(db)
+
+	st.runWaiterAsyncEnded()
+	return st, nil
+}
+
+//  WaitAsyncTasksEnded returns true if on shutting down we must wait async tasks ended
+func (s *Storage) WaitAsyncTasksEnded() bool {
+	return s.waitAsyncEnd
+}
+
+func (s *Storage) runWaiterAsyncEnded() {
+	go func() {
+		//  wait url and click repositories async tasks ended
+		<-s.urlAsyncEnded
+		<-s.clickAsyncEnded
+
+		s.storageAsyncEnded <- struct{}{}
+	}()
+}
+
+//  URL returns urls repository.
+func (s *Storage) URL() storage.URLRepository {
+	return s.shortURLRepo
+}
+
+//  DeleteQueueStats returns state of async url delete queue.
+func (s *Storage) DeleteQueueStats() model.DeleteQueueStats {
+	return s.shortURLRepo.DeleteQueueStats()
+}
+
+//  User returns users repository.
+func (s *Storage) User() storage.UserRepository {
+	return s.userRepo
+}
+
+//  Click returns clicks repository.
+func (s *Storage) Click() storage.ClickRepository {
+	return s.clickRepo
+}
+
+//  APIKey returns api keys repository.
+func (s *Storage) APIKey() storage.APIKeyRepository {
+	return s.apiKeyRepo
+}
+
+//  Ping checks database connection.
+func (s *Storage) Ping() error {
+	if s == nil || s.db == nil {
+		return errors.New("db not initialized")
+	}
+
+	if err := s.db.Ping(); err != nil {
+		return fmt.Errorf("ping for DSN (%s) failed: %w", s.conStringDSN, err)
+	}
+
+	return nil
+}
+
+//  Close closes database connection.
+func (s *Storage) Close() {
+	if s.db == nil {
+		return
+	}
+
+	s.db.Close()
+	s.db = nil
+}
+
+//  dbPath returns database file path from dsn.
+func dbPath(conStringDSN string) (string, error) {
+	if !IsDSN(conStringDSN) {
+		return "", fmt.Errorf("строка соединения с бд должна начинаться с %v", DSNPrefix)
+	}
+
+	path := strings.TrimPrefix(conStringDSN, DSNPrefix)
+	if path == "" {
+		return "", errors.New("путь до файла бд пуст")
+	}
+
+	return path, nil
+}
+
+//  unixTime converts time to unix microseconds, storage precision of time.
+func unixTime(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+//  nullUnixTime converts time to unix microseconds, zero time converts to NULL.
+func nullUnixTime(t time.Time) sql.NullInt64 {
+	return sql.NullInt64{Int64: t.UnixMicro(), Valid: !t.IsZero()}
+}
+
+//  fromUnixTime converts unix microseconds to time, NULL converts to zero time.
+func fromUnixTime(v sql.NullInt64) time.Time {
+	if !v.Valid {
+		return time.Time{}
+	}
+
+	return time.UnixMicro(v.Int64)
+}
+
+//  isUniqueViolation checks that error is violation of unique constraint on table column.
+func isUniqueViolation(err error, tableColumn string) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: "+tableColumn)
+}
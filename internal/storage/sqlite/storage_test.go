@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/storagetest"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		ctx, cancel := context.WithCancel(context.Background())
+		finished := make(chan struct{})
+
+		st, err := NewStorage(ctx, finished, DSNPrefix+filepath.Join(t.TempDir(), "shortener.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			cancel()
+			<-finished
+			st.Close()
+		})
+
+		return st
+	})
+}
+
+func TestStorage_ReplayDeleteJobs(t *testing.T) {
+	dsn := DSNPrefix + filepath.Join(t.TempDir(), "shortener.db")
+	ctx := context.Background()
+
+	userID := uuid.New()
+	sht := model.NewShortURL("https://replay.test", userID, model.WithAlias("replay"))
+
+	ctxFirst, cancel := context.WithCancel(ctx)
+	finished := make(chan struct{})
+	st, err := NewStorage(ctxFirst, finished, dsn)
+	require.NoError(t, err)
+
+	_, err = st.User().AddUser(ctx, model.User{ID: userID})
+	require.NoError(t, err)
+	_, err = st.URL().SaveURL(ctx, sht)
+	require.NoError(t, err)
+
+	//  job accepted, but not processed before crash
+	job := model.NewDeleteJob(userID, []string{sht.ShortID})
+	require.NoError(t, insertDeleteJob(st.db, job))
+
+	cancel()
+	<-finished
+	st.Close()
+
+	ctxSecond, cancel := context.WithCancel(ctx)
+	finished = make(chan struct{})
+	reopened, err := NewStorage(ctxSecond, finished, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		<-finished
+		reopened.Close()
+	})
+
+	require.Eventually(t, func() bool {
+		got, err := reopened.URL().GetDeleteJob(ctx, job.ID)
+		return err == nil && got.Status == model.DeleteJobDone
+	}, time.Second, 10*time.Millisecond)
+
+	got, err := reopened.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	require.True(t, got.IsDeleted)
+}
+
+func TestMigrate(t *testing.T) {
+	dsn := DSNPrefix + filepath.Join(t.TempDir(), "shortener.db")
+
+	require.NoError(t, MigrateUp(dsn))
+	status, err := GetMigrationStatus(dsn)
+	require.NoError(t, err)
+	require.Equal(t, status.Latest, status.Version)
+	require.False(t, status.Dirty)
+
+	//  down rolls back one migration
+	latest := status.Latest
+	require.NoError(t, MigrateDown(dsn))
+	status, err = GetMigrationStatus(dsn)
+	require.NoError(t, err)
+	require.Equal(t, latest-1, status.Version)
+
+	_, err = NewStorage(context.Background(), make(chan struct{}), "postgres://localhost")
+	require.Error(t, err)
+}
@@ -0,0 +1,378 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	st "github.com/atrush/pract_01.git/internal/storage"
+	"github.com/atrush/pract_01.git/internal/storage/deletequeue"
+	"github.com/atrush/pract_01.git/internal/storage/schema"
+)
+
+var _ st.URLRepository = (*shortURLRepository)(nil)
+
+//  shortURLRepository implements URLRepository interface, provides actions with url records in sqlite storage.
+type shortURLRepository struct {
+	db          *sql.DB
+	deleteQueue *deletequeue.Queue
+}
+
+const (
+	//  urlColumns is list of urls table columns in scanURL order.
+	urlColumns = "id, user_id, srcurl, shorturl, isdeleted, expires_at, isexpired, created_at"
+	//  urlPlaceholders is placeholders of urlColumns values.
+	urlPlaceholders = "(?, ?, ?, ?, ?, ?, ?, ?)"
+)
+
+//  newShortURLRepository inits new url repository.
+//  Delete jobs are processed by async queue, on context done queue is processed and signal sent to asyncEnded.
+func newShortURLRepository(ctx context.Context, db *sql.DB, asyncEnded chan struct{}, opts ...deletequeue.Option) *shortURLRepository {
+	repo := &shortURLRepository{
+		db: db,
+	}
+	repo.deleteQueue = deletequeue.New(ctx, repo, asyncEnded, opts...)
+
+	return repo
+}
+
+//  DeleteURLBatch saves delete job and adds it to async delete queue without blocking.
+//  Returns shterrors.ErrDeleteQueueFull if queue has no space for list,
+//  shterrors.ErrDeleteListTooLarge if list is larger than queue.
+func (r *shortURLRepository) DeleteURLBatch(userID uuid.UUID, shortIDList ...string) (model.DeleteJob, error) {
+	job := model.NewDeleteJob(userID, shortIDList)
+	if len(shortIDList) == 0 {
+		job.Finish(model.DeleteJobDone, "", job.CreatedAt)
+		if err := insertDeleteJob(r.db, job); err != nil {
+			return model.DeleteJob{}, err
+		}
+		return job, nil
+	}
+
+	err := r.deleteQueue.Add(job, func() error {
+		return insertDeleteJob(r.db, job)
+	})
+	if err != nil {
+		return model.DeleteJob{}, err
+	}
+
+	return job, nil
+}
+
+//  DeleteQueueStats returns state of async delete queue.
+func (r *shortURLRepository) DeleteQueueStats() model.DeleteQueueStats {
+	return r.deleteQueue.Stats()
+}
+
+//  SaveURLBatch saves list of urls to database with one insert statement, using transaction.
+//  If any url is not saved, no urls are saved.
+func (r *shortURLRepository) SaveURLBatch(ctx context.Context, urls []model.ShortURL) (err error) {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(urls))
+	args := make([]interface{}, 0, len(urls)*8)
+	srcURLs := make([]string, 0, len(urls))
+	for _, sht := range urls {
+		dbObj, err := schema.NewURLFromCanonical(sht)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения пачки:%w", err)
+		}
+
+		values = append(values, urlPlaceholders)
+		args = append(args, urlArgs(dbObj)...)
+		srcURLs = append(srcURLs, dbObj.URL)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return
+	}
+
+	// defer make rollback
+	defer func() {
+		if err != nil {
+			if rollErr := tx.Rollback(); rollErr != nil {
+				err = fmt.Errorf("ошибка транзакции сохранения:%v; транзакцию не удалось отменить:%w", err.Error(), rollErr)
+			}
+		}
+	}()
+
+	if _, err = releaseExpiredSrcURL(ctx, tx, time.Now(), srcURLs...); err != nil {
+		err = fmt.Errorf("ошибка транзакции сохранения:%w", err)
+		return
+	}
+
+	if _, err = tx.ExecContext(ctx, "INSERT INTO urls ("+urlColumns+") VALUES "+strings.Join(values, ", "), args...); err != nil {
+		err = fmt.Errorf("ошибка транзакции сохранения:%w", err)
+		return
+	}
+
+	return tx.Commit()
+}
+
+//  SaveURL saves url to database.
+//  If source url is held by expired url, expired url is marked and url is saved.
+func (r *shortURLRepository) SaveURL(ctx context.Context, sht model.ShortURL) (model.ShortURL, error) {
+	dbObj, err := schema.NewURLFromCanonical(sht)
+	if err != nil {
+		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx, "INSERT INTO urls ("+urlColumns+") VALUES "+urlPlaceholders, urlArgs(dbObj)...)
+
+	// check duplicate shorturl
+	if isUniqueViolation(err, "urls.shorturl") {
+		return model.ShortURL{}, &shterrors.ErrorConflictSaveURL{
+			Err:           err,
+			ExistShortURL: sht.ShortID,
+			Code:          shterrors.ConflictCodeAlias,
+		}
+	}
+
+	// check duplicate srcurl
+	if isUniqueViolation(err, "urls.srcurl") {
+		released, relErr := releaseExpiredSrcURL(ctx, r.db, time.Now(), sht.URL)
+		if relErr != nil {
+			return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", relErr)
+		}
+		if released > 0 {
+			return r.SaveURL(ctx, sht)
+		}
+
+		existURL, getErr := r.GetURLBySrcURL(ctx, sht.URL)
+		if getErr != nil {
+			return model.ShortURL{}, fmt.Errorf("ошибка добавления записи в БД, ссылка %v уже существует: ошибка получения существующей короткой ссыки: %w",
+				sht.URL, getErr)
+		}
+		return model.ShortURL{}, &shterrors.ErrorConflictSaveURL{
+			Err:           err,
+			ExistShortURL: existURL.ShortID,
+			Code:          shterrors.ConflictCodeURL,
+		}
+	}
+
+	if err != nil {
+		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return sht, nil
+}
+
+//  GetURL selects url from database by shortID, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist.
+func (r *shortURLRepository) GetURL(ctx context.Context, shortID string) (model.ShortURL, error) {
+	dbObj, err := scanURL(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+urlColumns+" FROM urls WHERE shorturl = ?", shortID,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ShortURL{}, shterrors.ErrNotFound
+	}
+	if err != nil {
+		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetURLBySrcURL selects not expired url from database by source url, returns as canonical ShortURL.
+//  Returns shterrors.ErrNotFound if url not exist or expired.
+func (r *shortURLRepository) GetURLBySrcURL(ctx context.Context, url string) (model.ShortURL, error) {
+	dbObj, err := scanURL(r.db.QueryRowContext(
+		ctx,
+		"SELECT "+urlColumns+" FROM urls WHERE srcurl = ? AND isexpired = FALSE AND (expires_at IS NULL OR expires_at > ?)",
+		url, unixTime(time.Now()),
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ShortURL{}, shterrors.ErrNotFound
+	}
+	if err != nil {
+		return model.ShortURL{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return dbObj.ToCanonical()
+}
+
+//  GetUserURLList selects page of user urls from database by filter, ordered by creation time and id.
+//  Returns list of canonical ShortURL.
+func (r *shortURLRepository) GetUserURLList(ctx context.Context, userID uuid.UUID, filter model.URLListFilter) ([]model.ShortURL, error) {
+	query, args := userURLListQuery(userID, filter)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	userURLs := make(schema.URLList, 0, filter.Limit)
+	for rows.Next() {
+		s, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		userURLs = append(userURLs, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return userURLs.ToCanonical()
+}
+
+//  userURLListQuery builds select query of user urls page with filter conditions.
+func userURLListQuery(userID uuid.UUID, filter model.URLListFilter) (string, []interface{}) {
+	args := []interface{}{userID}
+	where := []string{"user_id = ?"}
+
+	addCond := func(cond string, vals ...interface{}) {
+		args = append(args, vals...)
+		where = append(where, cond)
+	}
+
+	if !filter.IncludeDeleted {
+		addCond("isdeleted = FALSE")
+	}
+	if filter.URLContains != "" {
+		addCond("instr(srcurl, ?) > 0", filter.URLContains)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		addCond("created_at > ?", unixTime(filter.CreatedAfter))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		addCond("created_at < ?", unixTime(filter.CreatedBefore))
+	}
+	if filter.After != nil {
+		addCond("(created_at, id) > (?, ?)", unixTime(filter.After.CreatedAt), filter.After.ID)
+	}
+
+	args = append(args, filter.Limit)
+	query := "SELECT " + urlColumns + " FROM urls WHERE " + strings.Join(where, " AND ") +
+		" ORDER BY created_at, id LIMIT ?"
+
+	return query, args
+}
+
+//  GetCount returns count of stored, not deleted and not expired urls.
+func (r *shortURLRepository) GetCount() (int, error) {
+	count := 0
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM urls WHERE isdeleted = FALSE AND isexpired = FALSE AND (expires_at IS NULL OR expires_at > ?)",
+		unixTime(time.Now())).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+//  MarkExpired marks urls with expiration time before now as expired, returns list of marked shortIDs.
+func (r *shortURLRepository) MarkExpired(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"UPDATE urls SET isexpired = TRUE WHERE isexpired = FALSE AND expires_at <= ? RETURNING shorturl", unixTime(now))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	defer rows.Close()
+
+	expired := make([]string, 0)
+	for rows.Next() {
+		var shortID string
+		if err := rows.Scan(&shortID); err != nil {
+			return nil, fmt.Errorf("ошибка хранилица:%w", err)
+		}
+		expired = append(expired, shortID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+	return expired, nil
+}
+
+//  releaseExpiredSrcURL marks urls with source urls and expiration time before now as expired,
+//  so source urls are removed from unique index and can be saved again. Returns count of marked urls.
+func releaseExpiredSrcURL(ctx context.Context, db execer, now time.Time, srcURLs ...string) (int64, error) {
+	if len(srcURLs) == 0 {
+		return 0, nil
+	}
+
+	args := make([]interface{}, 0, len(srcURLs)+1)
+	args = append(args, unixTime(now))
+	for _, v := range srcURLs {
+		args = append(args, v)
+	}
+
+	res, err := db.ExecContext(ctx,
+		"UPDATE urls SET isexpired = TRUE WHERE isexpired = FALSE AND expires_at <= ? AND srcurl IN (?"+strings.Repeat(", ?", len(srcURLs)-1)+")",
+		args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+//  Exist checks that shortID exist in database.
+func (r *shortURLRepository) Exist(shortID string) (bool, error) {
+	count := 0
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM urls WHERE shorturl = ?", shortID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//  rowScanner is common interface of sql.Row and sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+//  execer is common interface of sql.DB and sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+//  scanURL scans url record with urlColumns.
+func scanURL(row rowScanner) (schema.ShortURL, error) {
+	dbObj := schema.ShortURL{}
+	expiresAt := sql.NullInt64{}
+	createdAt := sql.NullInt64{}
+
+	err := row.Scan(&dbObj.ID, &dbObj.UserID, &dbObj.URL, &dbObj.ShortID, &dbObj.IsDeleted, &expiresAt, &dbObj.IsExpired, &createdAt)
+	if err != nil {
+		return schema.ShortURL{}, err
+	}
+
+	dbObj.ExpiresAt = fromUnixTime(expiresAt)
+	dbObj.CreatedAt = fromUnixTime(createdAt)
+	return dbObj, nil
+}
+
+//  urlArgs returns url values in urlColumns order.
+func urlArgs(dbObj schema.ShortURL) []interface{} {
+	return []interface{}{
+		dbObj.ID,
+		dbObj.UserID,
+		dbObj.URL,
+		dbObj.ShortID,
+		dbObj.IsDeleted,
+		nullUnixTime(dbObj.ExpiresAt),
+		dbObj.IsExpired,
+		unixTime(dbObj.CreatedAt),
+	}
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+)
+
+var _ storage.UserRepository = (*userRepository)(nil)
+
+//  userRepository implements UserRepository interface, provides actions with user records in sqlite storage.
+type userRepository struct {
+	db *sql.DB
+}
+
+//  newUserRepository inits new user repository.
+func newUserRepository(db *sql.DB) *userRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+//  AddUser saves user to database.
+func (r *userRepository) AddUser(ctx context.Context, user model.User) (model.User, error) {
+	if _, err := r.db.ExecContext(ctx, "INSERT INTO users (id) VALUES (?)", user.ID); err != nil {
+		return model.User{}, fmt.Errorf("ошибка хранилица:%w", err)
+	}
+
+	return user, nil
+}
+
+//  Exist checks that user is exist in database.
+func (r *userRepository) Exist(ctx context.Context, userID uuid.UUID) (bool, error) {
+	count := 0
+	err := r.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM users WHERE id = ?", userID).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+//  GetCount returns count of stored users.
+func (r *userRepository) GetCount() (int, error) {
+	count := 0
+	err := r.db.QueryRow(
+		"SELECT COUNT(*) FROM users").Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
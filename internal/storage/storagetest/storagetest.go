@@ -0,0 +1,105 @@
+//  Package storagetest provides conformance tests of storage.Storage implementations.
+//  Every backend runs the same suite, so backends behave equally for service layer.
+//  Tests do not expect empty storage, every test uses unique users, urls and shortIDs,
+//  counts are checked as difference before and after changes.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	//  asyncWait is max waiting time of async storage tasks, such as deleting.
+	asyncWait = 5 * time.Second
+	//  asyncTick is checking interval of async storage tasks.
+	asyncTick = 10 * time.Millisecond
+)
+
+//  NewStorageFunc returns initialized storage for test, storage must be closed by cleanup.
+type NewStorageFunc func(t *testing.T) storage.Storage
+
+//  Run runs conformance tests against storage returned by newStorage.
+//  Every test gets new storage.
+func Run(t *testing.T, newStorage NewStorageFunc) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, st storage.Storage)
+	}{
+		{name: "Storage", test: testStorage},
+		{name: "User/AddUser", test: testAddUser},
+		{name: "User/GetCount", test: testUserGetCount},
+		{name: "URL/GetURL", test: testGetURL},
+		{name: "URL/SaveURL", test: testSaveURL},
+		{name: "URL/SaveURLConflict", test: testSaveURLConflict},
+		{name: "URL/SaveURLUnknownUser", test: testSaveURLUnknownUser},
+		{name: "URL/Exist", test: testExist},
+		{name: "URL/GetUserURLList", test: testGetUserURLList},
+		{name: "URL/GetUserURLListFilter", test: testGetUserURLListFilter},
+		{name: "URL/DeleteURLBatch", test: testDeleteURLBatch},
+		{name: "URL/GetDeleteJobNotFound", test: testGetDeleteJobNotFound},
+		{name: "URL/MarkExpired", test: testMarkExpired},
+		{name: "URL/GetCount", test: testURLGetCount},
+		{name: "URL/SaveURLBatch", test: testSaveURLBatch},
+		{name: "URL/GetURLBySrcURL", test: testGetURLBySrcURL},
+		{name: "URL/SaveExpiredSrcURL", test: testSaveExpiredSrcURL},
+		{name: "URL/ConcurrentSaveURL", test: testConcurrentSaveURL},
+		{name: "URL/ConcurrentSaveAlias", test: testConcurrentSaveAlias},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+//  testStorage checks storage repositories.
+//  Ping result is not checked, storage without database connection returns error.
+func testStorage(t *testing.T, st storage.Storage) {
+	st.Ping()
+
+	assert.NotNil(t, st.URL())
+	assert.NotNil(t, st.User())
+	assert.NotNil(t, st.Click())
+	assert.NotNil(t, st.APIKey())
+
+	//  must not block or panic
+	st.WaitAsyncTasksEnded()
+}
+
+//  addUser saves new user to storage.
+func addUser(t *testing.T, st storage.Storage) uuid.UUID {
+	userID := uuid.New()
+	_, err := st.User().AddUser(context.Background(), model.User{ID: userID})
+	require.NoError(t, err)
+
+	return userID
+}
+
+//  newURL returns not saved url of user with unique shortID and source url.
+func newURL(userID uuid.UUID, opts ...model.ShortURLOption) model.ShortURL {
+	opts = append([]model.ShortURLOption{model.WithAlias(shortID())}, opts...)
+
+	return model.NewShortURL("https://practicum.yandex.ru/"+uuid.NewString(), userID, opts...)
+}
+
+//  saveURL saves new url of user to storage.
+func saveURL(t *testing.T, st storage.Storage, userID uuid.UUID, opts ...model.ShortURLOption) model.ShortURL {
+	sht, err := st.URL().SaveURL(context.Background(), newURL(userID, opts...))
+	require.NoError(t, err)
+
+	return sht
+}
+
+//  shortID returns unique shortID, so tests can run on not empty database.
+func shortID() string {
+	return uuid.NewString()[:8]
+}
@@ -0,0 +1,511 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/shterrors"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//  testGetURL checks that stored url is returned and not existing url returns shterrors.ErrNotFound.
+func testGetURL(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	sht := saveURL(t, st, userID)
+
+	got, err := st.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.Equal(t, sht.ID, got.ID)
+	assert.Equal(t, sht.URL, got.URL)
+	assert.Equal(t, userID, got.UserID)
+
+	_, err = st.URL().GetURL(ctx, shortID())
+	assert.ErrorIs(t, err, shterrors.ErrNotFound)
+}
+
+//  testSaveURL checks that all fields of saved url are stored.
+func testSaveURL(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	sht, err := st.URL().SaveURL(ctx, newURL(userID, model.WithExpiresAt(expiresAt)))
+	require.NoError(t, err)
+
+	got, err := st.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.Equal(t, sht.ID, got.ID)
+	assert.Equal(t, sht.ShortID, got.ShortID)
+	assert.Equal(t, sht.URL, got.URL)
+	assert.Equal(t, userID, got.UserID)
+	assert.False(t, got.IsDeleted)
+	assert.False(t, got.IsExpired)
+	assert.True(t, expiresAt.Equal(got.ExpiresAt), "время истечения %v, ожидалось %v", got.ExpiresAt, expiresAt)
+	assert.True(t, sht.CreatedAt.Equal(got.CreatedAt), "время создания %v, ожидалось %v", got.CreatedAt, sht.CreatedAt)
+}
+
+//  testSaveURLConflict checks that saving of existing shortID or source url returns shterrors.ErrorConflictSaveURL
+//  with conflict code and stored shortID.
+func testSaveURLConflict(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	sht := saveURL(t, st, userID)
+
+	//  same shortID, another url
+	_, err := st.URL().SaveURL(ctx, newURL(userID, model.WithAlias(sht.ShortID)))
+	conflictErr := &shterrors.ErrorConflictSaveURL{}
+	require.True(t, errors.As(err, &conflictErr), "ожидалась ошибка конфликта, получено: %v", err)
+	assert.Equal(t, shterrors.ConflictCodeAlias, conflictErr.Code)
+	assert.Equal(t, sht.ShortID, conflictErr.ExistShortURL)
+
+	//  same url, another shortID, also for another user
+	_, err = st.URL().SaveURL(ctx, model.NewShortURL(sht.URL, addUser(t, st), model.WithAlias(shortID())))
+	conflictErr = &shterrors.ErrorConflictSaveURL{}
+	require.True(t, errors.As(err, &conflictErr), "ожидалась ошибка конфликта, получено: %v", err)
+	assert.Equal(t, shterrors.ConflictCodeURL, conflictErr.Code)
+	assert.Equal(t, sht.ShortID, conflictErr.ExistShortURL)
+
+	//  stored url is not changed
+	got, err := st.URL().GetURL(ctx, sht.ShortID)
+	require.NoError(t, err)
+	assert.Equal(t, sht.ID, got.ID)
+	assert.Equal(t, sht.URL, got.URL)
+}
+
+//  testSaveURLUnknownUser checks that url of not existing user is not saved.
+func testSaveURLUnknownUser(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	sht := newURL(uuid.New())
+
+	_, err := st.URL().SaveURL(ctx, sht)
+	require.Error(t, err)
+
+	_, err = st.URL().GetURL(ctx, sht.ShortID)
+	assert.ErrorIs(t, err, shterrors.ErrNotFound)
+}
+
+//  testExist checks shortID existence.
+func testExist(t *testing.T, st storage.Storage) {
+	sht := saveURL(t, st, addUser(t, st))
+
+	exist, err := st.URL().Exist(sht.ShortID)
+	require.NoError(t, err)
+	assert.True(t, exist)
+
+	exist, err = st.URL().Exist(shortID())
+	require.NoError(t, err)
+	assert.False(t, exist)
+}
+
+//  testGetUserURLList checks that user urls are returned ordered by creation time and id, paged by limit and cursor.
+func testGetUserURLList(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	saved := make([]model.ShortURL, 0, 5)
+	for i := 0; i < 5; i++ {
+		saved = append(saved, saveURL(t, st, userID))
+	}
+	//  url of another user must not be returned
+	saveURL(t, st, addUser(t, st))
+
+	sort.Slice(saved, func(i, j int) bool {
+		return model.NewURLCursor(saved[i]).Less(saved[j])
+	})
+
+	//  all urls
+	list, err := st.URL().GetUserURLList(ctx, userID, model.URLListFilter{Limit: model.MaxURLListLimit})
+	require.NoError(t, err)
+	assert.Equal(t, shortIDs(saved), shortIDs(list))
+
+	//  pages
+	got := make([]model.ShortURL, 0, len(saved))
+	filter := model.URLListFilter{Limit: 2}
+	for {
+		page, err := st.URL().GetUserURLList(ctx, userID, filter)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), filter.Limit)
+		if len(page) == 0 {
+			break
+		}
+
+		got = append(got, page...)
+		cursor := model.NewURLCursor(page[len(page)-1])
+		filter.After = &cursor
+	}
+	assert.Equal(t, shortIDs(saved), shortIDs(got))
+
+	//  user without urls
+	list, err = st.URL().GetUserURLList(ctx, uuid.New(), model.URLListFilter{Limit: model.MaxURLListLimit})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+//  testGetUserURLListFilter checks filters of user urls list.
+func testGetUserURLListFilter(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	first := saveURL(t, st, userID)
+	time.Sleep(10 * time.Millisecond)
+	second := saveURL(t, st, userID)
+	time.Sleep(10 * time.Millisecond)
+	deleted := saveURL(t, st, userID)
+
+	_, err := st.URL().DeleteURLBatch(userID, deleted.ShortID)
+	require.NoError(t, err)
+	waitDeleted(t, st, deleted.ShortID)
+
+	tests := []struct {
+		name   string
+		filter model.URLListFilter
+		want   []model.ShortURL
+	}{
+		{
+			name:   "deleted are excluded",
+			filter: model.URLListFilter{},
+			want:   []model.ShortURL{first, second},
+		},
+		{
+			name:   "include deleted",
+			filter: model.URLListFilter{IncludeDeleted: true},
+			want:   []model.ShortURL{first, second, deleted},
+		},
+		{
+			name:   "url contains",
+			filter: model.URLListFilter{URLContains: second.URL[len(second.URL)-12:]},
+			want:   []model.ShortURL{second},
+		},
+		{
+			name:   "created after",
+			filter: model.URLListFilter{CreatedAfter: first.CreatedAt, IncludeDeleted: true},
+			want:   []model.ShortURL{second, deleted},
+		},
+		{
+			name:   "created before",
+			filter: model.URLListFilter{CreatedBefore: deleted.CreatedAt, IncludeDeleted: true},
+			want:   []model.ShortURL{first, second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Limit = model.MaxURLListLimit
+			list, err := st.URL().GetUserURLList(ctx, userID, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, shortIDs(tt.want), shortIDs(list))
+		})
+	}
+}
+
+//  testDeleteURLBatch checks that urls are marked as deleted only for owner and delete job is done.
+//  Deleting can be async, result is waited.
+func testDeleteURLBatch(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	toDelete := saveURL(t, st, userID)
+	alien := saveURL(t, st, addUser(t, st))
+	kept := saveURL(t, st, userID)
+
+	emptyJob, err := st.URL().DeleteURLBatch(userID)
+	require.NoError(t, err)
+	waitDeleteJobDone(t, st, emptyJob.ID)
+
+	job, err := st.URL().DeleteURLBatch(userID, toDelete.ShortID, alien.ShortID, shortID())
+	require.NoError(t, err)
+	assert.Equal(t, userID, job.UserID)
+	assert.Len(t, job.ShortIDs, 3)
+	waitDeleted(t, st, toDelete.ShortID)
+	waitDeleteJobDone(t, st, job.ID)
+
+	stored, err := st.URL().GetDeleteJob(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, userID, stored.UserID)
+	assert.Equal(t, job.ShortIDs, stored.ShortIDs)
+	assert.Empty(t, stored.Error)
+	assert.True(t, job.CreatedAt.Equal(stored.CreatedAt), "время создания %v, ожидалось %v", stored.CreatedAt, job.CreatedAt)
+	assert.False(t, stored.FinishedAt.IsZero())
+
+	//  deleted url is still returned by shortID
+	got, err := st.URL().GetURL(ctx, toDelete.ShortID)
+	require.NoError(t, err)
+	assert.True(t, got.IsDeleted)
+
+	got, err = st.URL().GetURL(ctx, alien.ShortID)
+	require.NoError(t, err)
+	assert.False(t, got.IsDeleted, "удалена ссылка другого пользователя")
+
+	got, err = st.URL().GetURL(ctx, kept.ShortID)
+	require.NoError(t, err)
+	assert.False(t, got.IsDeleted)
+}
+
+//  testGetDeleteJobNotFound checks that not existing delete job returns shterrors.ErrDeleteJobNotFound.
+func testGetDeleteJobNotFound(t *testing.T, st storage.Storage) {
+	_, err := st.URL().GetDeleteJob(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, shterrors.ErrDeleteJobNotFound)
+}
+
+//  testMarkExpired checks that only urls with expiration time before now are marked once.
+func testMarkExpired(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	now := time.Now()
+
+	expired := saveURL(t, st, userID, model.WithExpiresAt(now.Add(time.Hour)))
+	notExpired := saveURL(t, st, userID, model.WithExpiresAt(now.Add(3*time.Hour)))
+	never := saveURL(t, st, userID)
+
+	marked, err := st.URL().MarkExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Contains(t, marked, expired.ShortID)
+	assert.NotContains(t, marked, notExpired.ShortID)
+	assert.NotContains(t, marked, never.ShortID)
+
+	got, err := st.URL().GetURL(ctx, expired.ShortID)
+	require.NoError(t, err)
+	assert.True(t, got.IsExpired)
+
+	got, err = st.URL().GetURL(ctx, notExpired.ShortID)
+	require.NoError(t, err)
+	assert.False(t, got.IsExpired)
+
+	//  marked urls are not returned again
+	marked, err = st.URL().MarkExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.NotContains(t, marked, expired.ShortID)
+}
+
+//  testURLGetCount checks that count includes only not deleted and not expired urls.
+func testURLGetCount(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	before, err := st.URL().GetCount()
+	require.NoError(t, err)
+
+	saveURL(t, st, userID)
+	deleted := saveURL(t, st, userID)
+	expired := saveURL(t, st, userID, model.WithExpiresAt(time.Now().Add(time.Hour)))
+
+	count, err := st.URL().GetCount()
+	require.NoError(t, err)
+	assert.Equal(t, before+3, count)
+
+	_, err = st.URL().DeleteURLBatch(userID, deleted.ShortID)
+	require.NoError(t, err)
+	waitDeleted(t, st, deleted.ShortID)
+
+	marked, err := st.URL().MarkExpired(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Contains(t, marked, expired.ShortID)
+
+	count, err = st.URL().GetCount()
+	require.NoError(t, err)
+	assert.Equal(t, before+1, count)
+}
+
+//  testSaveURLBatch checks that list of urls is stored in one batch and batch with conflict is not stored.
+func testSaveURLBatch(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	list := make([]model.ShortURL, 0, 150)
+	for i := 0; i < cap(list); i++ {
+		list = append(list, newURL(userID))
+	}
+	require.NoError(t, st.URL().SaveURLBatch(ctx, list))
+
+	for _, sht := range list {
+		got, err := st.URL().GetURL(ctx, sht.ShortID)
+		require.NoError(t, err)
+		assert.Equal(t, sht.URL, got.URL)
+		assert.Equal(t, userID, got.UserID)
+	}
+
+	//  empty batch
+	require.NoError(t, st.URL().SaveURLBatch(ctx, nil))
+
+	//  batch with stored source url is not saved
+	notSaved := newURL(userID)
+	conflict := newURL(userID)
+	conflict.URL = list[0].URL
+
+	err := st.URL().SaveURLBatch(ctx, []model.ShortURL{notSaved, conflict})
+	require.Error(t, err)
+
+	_, err = st.URL().GetURL(ctx, notSaved.ShortID)
+	assert.ErrorIs(t, err, shterrors.ErrNotFound)
+}
+
+//  testGetURLBySrcURL checks that stored url is returned by source url and not existing returns shterrors.ErrNotFound.
+func testGetURLBySrcURL(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	sht := saveURL(t, st, userID)
+
+	got, err := st.URL().GetURLBySrcURL(ctx, sht.URL)
+	require.NoError(t, err)
+	assert.Equal(t, sht.ID, got.ID)
+	assert.Equal(t, sht.ShortID, got.ShortID)
+
+	_, err = st.URL().GetURLBySrcURL(ctx, newURL(userID).URL)
+	assert.ErrorIs(t, err, shterrors.ErrNotFound)
+}
+
+//  testSaveExpiredSrcURL checks that expired url does not hold its source url:
+//  same url is saved again with new shortID, expired shortID stays in storage.
+func testSaveExpiredSrcURL(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	now := time.Now()
+
+	//  expiration time has come, url is not marked
+	expired := saveURL(t, st, userID, model.WithExpiresAt(now.Add(-time.Minute)))
+	_, err := st.URL().GetURLBySrcURL(ctx, expired.URL)
+	assert.ErrorIs(t, err, shterrors.ErrNotFound)
+
+	sht, err := st.URL().SaveURL(ctx, model.NewShortURL(expired.URL, userID, model.WithAlias(shortID())))
+	require.NoError(t, err)
+
+	got, err := st.URL().GetURLBySrcURL(ctx, expired.URL)
+	require.NoError(t, err)
+	assert.Equal(t, sht.ShortID, got.ShortID)
+
+	got, err = st.URL().GetURL(ctx, expired.ShortID)
+	require.NoError(t, err)
+	assert.True(t, got.Expired(time.Now()))
+
+	//  not expired url holds source url
+	_, err = st.URL().SaveURL(ctx, model.NewShortURL(expired.URL, userID, model.WithAlias(shortID())))
+	var conflictErr *shterrors.ErrorConflictSaveURL
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, shterrors.ConflictCodeURL, conflictErr.Code)
+	assert.Equal(t, sht.ShortID, conflictErr.ExistShortURL)
+
+	//  marked url, saved in batch
+	marked := saveURL(t, st, userID, model.WithExpiresAt(now.Add(time.Hour)))
+	_, err = st.URL().MarkExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+
+	batch := []model.ShortURL{model.NewShortURL(marked.URL, userID, model.WithAlias(shortID()))}
+	require.NoError(t, st.URL().SaveURLBatch(ctx, batch))
+
+	got, err = st.URL().GetURLBySrcURL(ctx, marked.URL)
+	require.NoError(t, err)
+	assert.Equal(t, batch[0].ShortID, got.ShortID)
+}
+
+//  testConcurrentSaveURL checks concurrent saving and reading of different urls.
+func testConcurrentSaveURL(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+
+	const workers = 10
+	const perWorker = 10
+
+	saved := make(chan model.ShortURL, workers*perWorker)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				sht, err := st.URL().SaveURL(ctx, newURL(userID))
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, err = st.URL().GetURL(ctx, sht.ShortID)
+				assert.NoError(t, err)
+				saved <- sht
+			}
+		}()
+	}
+	wg.Wait()
+	close(saved)
+
+	count := 0
+	for sht := range saved {
+		got, err := st.URL().GetURL(ctx, sht.ShortID)
+		require.NoError(t, err)
+		assert.Equal(t, sht.URL, got.URL)
+		count++
+	}
+	assert.Equal(t, workers*perWorker, count)
+
+	list, err := st.URL().GetUserURLList(ctx, userID, model.URLListFilter{Limit: model.MaxURLListLimit})
+	require.NoError(t, err)
+	assert.Len(t, list, workers*perWorker)
+}
+
+//  testConcurrentSaveAlias checks that only one of concurrent saves of same shortID succeeds,
+//  others return shterrors.ErrorConflictSaveURL.
+func testConcurrentSaveAlias(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := addUser(t, st)
+	alias := shortID()
+
+	const workers = 10
+
+	mu := sync.Mutex{}
+	succeeded, conflicts := 0, 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := st.URL().SaveURL(ctx, newURL(userID, model.WithAlias(alias)))
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, &shterrors.ErrorConflictSaveURL{}):
+				conflicts++
+			default:
+				t.Errorf("неожиданная ошибка: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, workers-1, conflicts)
+}
+
+//  waitDeleted waits that url is marked as deleted.
+func waitDeleted(t *testing.T, st storage.Storage, shortID string) {
+	require.Eventually(t, func() bool {
+		sht, err := st.URL().GetURL(context.Background(), shortID)
+		return err == nil && sht.IsDeleted
+	}, asyncWait, asyncTick, "ссылка %v не удалена", shortID)
+}
+
+//  waitDeleteJobDone waits until delete job is done.
+func waitDeleteJobDone(t *testing.T, st storage.Storage, jobID uuid.UUID) {
+	require.Eventually(t, func() bool {
+		job, err := st.URL().GetDeleteJob(context.Background(), jobID)
+		return err == nil && job.Status == model.DeleteJobDone
+	}, asyncWait, asyncTick, "задача удаления %v не выполнена", jobID)
+}
+
+//  shortIDs returns shortIDs of urls list.
+func shortIDs(list []model.ShortURL) []string {
+	ids := make([]string, 0, len(list))
+	for _, v := range list {
+		ids = append(ids, v.ShortID)
+	}
+
+	return ids
+}
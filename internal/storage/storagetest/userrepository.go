@@ -0,0 +1,53 @@
+package storagetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/atrush/pract_01.git/internal/model"
+	"github.com/atrush/pract_01.git/internal/storage"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//  testAddUser checks that saved user exists.
+func testAddUser(t *testing.T, st storage.Storage) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	exist, err := st.User().Exist(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	user, err := st.User().AddUser(ctx, model.User{ID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+
+	exist, err = st.User().Exist(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, exist)
+}
+
+//  testUserGetCount checks users count after concurrent adding.
+func testUserGetCount(t *testing.T, st storage.Storage) {
+	before, err := st.User().GetCount()
+	require.NoError(t, err)
+
+	const users = 20
+	wg := sync.WaitGroup{}
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := st.User().AddUser(context.Background(), model.User{ID: uuid.New()})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	count, err := st.User().GetCount()
+	require.NoError(t, err)
+	assert.Equal(t, before+users, count)
+}
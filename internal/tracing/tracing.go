@@ -0,0 +1,114 @@
+//  Package tracing provides OpenTelemetry tracing of shortener: tracer provider with exporter from config,
+//  W3C trace context propagation, chi router middleware and gRPC interceptors.
+//  Spans are created by global tracer provider, so if tracing is disabled spans are not recorded.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//  ServiceName is name of service in exported spans.
+const ServiceName = "shortener"
+
+//  Span exporters.
+const (
+	ExporterNone   = "none"   //  spans are not recorded
+	ExporterStdout = "stdout" //  spans are written to stdout in json
+	ExporterOTLP   = "otlp"   //  spans are sent to OTLP gRPC endpoint
+)
+
+type (
+	//  Config is tracing params.
+	Config struct {
+		Exporter     string    //  span exporter, tracing is disabled if empty or ExporterNone
+		OTLPEndpoint string    //  OTLP gRPC endpoint <host:port>
+		OTLPInsecure bool      //  disables TLS of OTLP connection
+		Version      string    //  service version in exported spans
+		Writer       io.Writer //  writer of stdout exporter, os.Stdout if nil
+	}
+
+	//  ShutdownFunc flushes not exported spans and stops exporter.
+	ShutdownFunc func(ctx context.Context) error
+)
+
+//  Init sets W3C trace context propagator and global tracer provider with exporter from config.
+//  If tracing is disabled, global tracer provider is not set and spans are not recorded,
+//  incoming trace context is still propagated.
+func Init(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка инициализации трассировки:%w", err)
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	provider := NewProvider(exporter, cfg.Version)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+//  NewProvider returns tracer provider, that batches spans to exporter.
+func NewProvider(exporter sdktrace.SpanExporter, version string) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(ServiceName),
+			semconv.ServiceVersionKey.String(version),
+		)),
+	)
+}
+
+//  Tracer returns tracer of instrumented package from global tracer provider.
+func Tracer(name string) trace.Tracer {
+	return otel.Tracer(name)
+}
+
+//  newExporter returns span exporter from config, nil if tracing is disabled.
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		w := cfg.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("неизвестный экспортер трассировки: %v", cfg.Exporter)
+	}
+}
+
+//  End records error of operation to span, if error is not nil, and ends span.
+//  Used with defer and named error result.
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
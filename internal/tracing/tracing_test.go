@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//  initRecorder sets global tracer provider, that records ended spans, until end of test.
+func initRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	_, err := Init(context.Background(), Config{})
+	require.NoError(t, err)
+	otel.SetTracerProvider(provider)
+
+	return recorder
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := initRecorder(t)
+
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.Get("/{shortID}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Tracer("test").Start(r.Context(), "child")
+		span.End()
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	request := httptest.NewRequest(http.MethodGet, "/1xQ6p+JI", nil)
+	request.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+	r.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	child, server := spans[0], spans[1]
+
+	//  server span named by route, parent from traceparent header
+	assert.Equal(t, "GET /{shortID}", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, traceID, server.SpanContext().TraceID().String())
+	assert.Equal(t, spanID, server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), semconv.HTTPRouteKey.String("/{shortID}"))
+	assert.Contains(t, server.Attributes(), semconv.HTTPStatusCodeKey.Int(http.StatusNotFound))
+	assert.Equal(t, codes.Unset, server.Status().Code)
+
+	//  span of handler is child of server span
+	assert.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+}
+
+func TestEnd(t *testing.T) {
+	recorder := initRecorder(t)
+
+	_, span := Tracer("test").Start(context.Background(), "ok")
+	var err error
+	End(span, &err)
+
+	_, span = Tracer("test").Start(context.Background(), "failed")
+	err = errors.New("ошибка")
+	End(span, &err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "ошибка", spans[1].Status().Description)
+	require.Len(t, spans[1].Events(), 1)
+}
+
+func TestInit(t *testing.T) {
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		shutdown, err := Init(context.Background(), Config{Exporter: ExporterStdout, Version: "test", Writer: buf})
+		require.NoError(t, err)
+
+		_, span := Tracer("test").Start(context.Background(), "exported")
+		span.End()
+
+		//  batched spans are exported on shutdown
+		require.NoError(t, shutdown(context.Background()))
+		assert.Contains(t, buf.String(), `"Name":"exported"`)
+		assert.Contains(t, buf.String(), ServiceName)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+		shutdown, err := Init(context.Background(), Config{Exporter: ExporterNone})
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+
+		_, span := Tracer("test").Start(context.Background(), "not recorded")
+		assert.False(t, span.IsRecording())
+		span.End()
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		_, err := Init(context.Background(), Config{Exporter: "jaeger"})
+		require.Error(t, err)
+	})
+}
@@ -17,26 +17,77 @@ type Config struct {
 	FileStoragePath string `env:"FILE_STORAGE_PATH" json:"file_storage_path"  validate:"-"`
 	DatabaseDSN     string `env:"DATABASE_DSN" json:"database_dsn"  validate:"-"`
 	EnableHTTPS     bool   `env:"ENABLE_HTTPS" json:"enable_https" envDefault:"false" validate:"-"`
+	GRPCAddress     string `env:"GRPC_ADDRESS" json:"grpc_address" validate:"required,hostname_port"`
 
-	Debug         bool   `env:"SHORTENER_DEBUG" json:"-" envDefault:"false" validate:"-"`
-	ConfigPath    string `env:"CONFIG" json:"-" validate:"-"`
-	TrustedSubnet string `env:"TRUSTED_SUBNET" json:"trusted_subnet" validate:"-"`
+	Debug          bool   `env:"SHORTENER_DEBUG" json:"-" envDefault:"false" validate:"-"`
+	ConfigPath     string `env:"CONFIG" json:"-" validate:"-"`
+	TrustedSubnet  string `env:"TRUSTED_SUBNET" json:"trusted_subnet" validate:"-"`
+	TrustedProxies string `env:"TRUSTED_PROXIES" json:"trusted_proxies" validate:"-"`
+
+	AliasCharset   string `env:"ALIAS_CHARSET" json:"alias_charset" validate:"-"`
+	AliasMinLength int    `env:"ALIAS_MIN_LENGTH" json:"alias_min_length" validate:"gte=0,ltefield=AliasMaxLength"`
+	AliasMaxLength int    `env:"ALIAS_MAX_LENGTH" json:"alias_max_length" validate:"gte=0,lte=16"`
+	AliasReserved  string `env:"ALIAS_RESERVED" json:"alias_reserved" validate:"-"`
+
+	CacheAddress string `env:"CACHE_ADDRESS" json:"cache_address" validate:"omitempty,hostname_port"`
+	CacheSize    int    `env:"CACHE_SIZE" json:"cache_size" validate:"gte=0"`
+	CacheTTL     int    `env:"CACHE_TTL" json:"cache_ttl" validate:"gte=0"`
+
+	AuthKeys          string `env:"AUTH_KEYS" json:"auth_keys" validate:"-"`
+	AuthKeysFile      string `env:"AUTH_KEYS_FILE" json:"auth_keys_file" validate:"-"`
+	AuthTokenTTL      int    `env:"AUTH_TOKEN_TTL" json:"auth_token_ttl" validate:"gte=0"`
+	AuthDisableLegacy bool   `env:"AUTH_DISABLE_LEGACY" json:"auth_disable_legacy" validate:"-"`
+	AuthRequired      string `env:"AUTH_REQUIRED" json:"auth_required" validate:"-"`
+
+	DeleteQueueSize      int    `env:"DELETE_QUEUE_SIZE" json:"delete_queue_size" validate:"gte=0"`
+	DeleteDeadLetterPath string `env:"DELETE_DEAD_LETTER_PATH" json:"delete_dead_letter_path" validate:"-"`
+
+	TraceExporter     string `env:"TRACE_EXPORTER" json:"trace_exporter" validate:"omitempty,oneof=none stdout otlp"`
+	TraceOTLPEndpoint string `env:"TRACE_OTLP_ENDPOINT" json:"trace_otlp_endpoint" validate:"required_if=TraceExporter otlp,omitempty,hostname_port"`
+	TraceOTLPInsecure bool   `env:"TRACE_OTLP_INSECURE" json:"trace_otlp_insecure" validate:"-"`
+
+	LogLevel  string `env:"LOG_LEVEL" json:"log_level" validate:"oneof=debug info warn error"`
+	LogFormat string `env:"LOG_FORMAT" json:"log_format" validate:"oneof=text json"`
 }
 
 //  Default config params.
 const (
 	defServerPort  = ":8080"
+	defGRPCAddress = ":3201"
 	defBaseURL     = "http://localhost:8080"
 	defFileStorage = ""
 	defDatabaseDSN = ""
 	defDebug       = false
 	defEnableHTTPS = false
+
+	defAliasCharset   = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	defAliasMinLength = 3
+	defAliasMaxLength = 16
+	defAliasReserved  = "api,ping,debug,metrics"
+
+	defCacheTTL = 300
+
+	defAuthTokenTTL = 60 * 60 * 24 * 30
+	defAuthRequired = "keys"
+
+	defLogLevel  = "info"
+	defLogFormat = "text"
 )
 
 //  NewConfig inits new config.
 //  Reads flag params over default params, then redefines  with environment params.
 func NewConfig() (*Config, error) {
-	cfg := Config{}
+	cfg := Config{
+		AliasCharset:   defAliasCharset,
+		AliasMinLength: defAliasMinLength,
+		AliasMaxLength: defAliasMaxLength,
+		AliasReserved:  defAliasReserved,
+		CacheTTL:       defCacheTTL,
+		AuthTokenTTL:   defAuthTokenTTL,
+		AuthRequired:   defAuthRequired,
+		LogLevel:       defLogLevel,
+		LogFormat:      defLogFormat,
+	}
 
 	configPath := getConfigPath()
 	if len(configPath) != 0 {
@@ -68,6 +119,7 @@ func (c *Config) Validate() error {
 func (c *Config) readFlagConfig() {
 	flagConfig := &Config{}
 	flag.StringVar(&flagConfig.ServerPort, "a", defServerPort, "порт HTTP-сервера <:port>")
+	flag.StringVar(&flagConfig.GRPCAddress, "g", defGRPCAddress, "адрес gRPC-сервера <host:port>")
 	flag.StringVar(&flagConfig.BaseURL, "b", defBaseURL, "базовый URL для сокращенных ссылок <http://localhost:port>")
 	flag.StringVar(&flagConfig.FileStoragePath, "f", defFileStorage, "путь до файла с сокращёнными URL")
 	flag.StringVar(&flagConfig.DatabaseDSN, "d", defDatabaseDSN, "строка с адресом подключения к БД")
@@ -75,6 +127,7 @@ func (c *Config) readFlagConfig() {
 	flag.BoolVar(&flagConfig.EnableHTTPS, "s", defEnableHTTPS, "включения HTTPS в веб-сервере")
 	flag.StringVar(&flagConfig.ConfigPath, "c", "", "файл конфигурации")
 	flag.StringVar(&flagConfig.TrustedSubnet, "t", "", "CIDR доверенной подсети")
+	flag.StringVar(&flagConfig.CacheAddress, "r", "", "адрес Redis-совместимого сервера кэша <host:port>")
 	flag.Parse()
 
 	c.redefineConfig(flagConfig)
@@ -82,6 +135,7 @@ func (c *Config) readFlagConfig() {
 
 //  redefineConfig redefines config with new config.
 //  if string not empty override
+//  if int not zero override
 //  if bool true override
 func (c *Config) redefineConfig(nc *Config) {
 	if nc.BaseURL != "" {
@@ -90,6 +144,9 @@ func (c *Config) redefineConfig(nc *Config) {
 	if nc.ServerPort != "" {
 		c.ServerPort = nc.ServerPort
 	}
+	if nc.GRPCAddress != "" {
+		c.GRPCAddress = nc.GRPCAddress
+	}
 	if nc.FileStoragePath != "" {
 		c.FileStoragePath = nc.FileStoragePath
 	}
@@ -99,12 +156,72 @@ func (c *Config) redefineConfig(nc *Config) {
 	if nc.TrustedSubnet != "" {
 		c.TrustedSubnet = nc.TrustedSubnet
 	}
+	if nc.TrustedProxies != "" {
+		c.TrustedProxies = nc.TrustedProxies
+	}
 	if nc.Debug {
 		c.Debug = nc.Debug
 	}
 	if nc.EnableHTTPS {
 		c.EnableHTTPS = nc.EnableHTTPS
 	}
+	if nc.AliasCharset != "" {
+		c.AliasCharset = nc.AliasCharset
+	}
+	if nc.AliasMinLength != 0 {
+		c.AliasMinLength = nc.AliasMinLength
+	}
+	if nc.AliasMaxLength != 0 {
+		c.AliasMaxLength = nc.AliasMaxLength
+	}
+	if nc.AliasReserved != "" {
+		c.AliasReserved = nc.AliasReserved
+	}
+	if nc.CacheAddress != "" {
+		c.CacheAddress = nc.CacheAddress
+	}
+	if nc.CacheSize != 0 {
+		c.CacheSize = nc.CacheSize
+	}
+	if nc.CacheTTL != 0 {
+		c.CacheTTL = nc.CacheTTL
+	}
+	if nc.AuthKeys != "" {
+		c.AuthKeys = nc.AuthKeys
+	}
+	if nc.AuthKeysFile != "" {
+		c.AuthKeysFile = nc.AuthKeysFile
+	}
+	if nc.AuthTokenTTL != 0 {
+		c.AuthTokenTTL = nc.AuthTokenTTL
+	}
+	if nc.AuthDisableLegacy {
+		c.AuthDisableLegacy = nc.AuthDisableLegacy
+	}
+	if nc.AuthRequired != "" {
+		c.AuthRequired = nc.AuthRequired
+	}
+	if nc.DeleteQueueSize != 0 {
+		c.DeleteQueueSize = nc.DeleteQueueSize
+	}
+	if nc.DeleteDeadLetterPath != "" {
+		c.DeleteDeadLetterPath = nc.DeleteDeadLetterPath
+	}
+	if nc.TraceExporter != "" {
+		c.TraceExporter = nc.TraceExporter
+	}
+	if nc.TraceOTLPEndpoint != "" {
+		c.TraceOTLPEndpoint = nc.TraceOTLPEndpoint
+	}
+	if nc.TraceOTLPInsecure {
+		c.TraceOTLPInsecure = nc.TraceOTLPInsecure
+	}
+	if nc.LogLevel != "" {
+		c.LogLevel = nc.LogLevel
+	}
+	if nc.LogFormat != "" {
+		c.LogFormat = nc.LogFormat
+	}
 }
 
 //  readEnvConfig redefines config params with environment params.